@@ -0,0 +1,218 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Ameight/systray-queue-app/internal/queue"
+)
+
+// Env vars that enable and protect the remote API.
+const (
+	EnvAddr  = "QUEUE_HTTP_ADDR"  // e.g. "127.0.0.1:8765"; the API is off when empty
+	EnvToken = "QUEUE_HTTP_TOKEN" // optional; when set, requests need "Authorization: Bearer <token>"
+	// EnvDedupWindow overrides DefaultDedupWindow, as a Go duration such as
+	// "30m" or "48h"; "0" turns deduplication off.
+	EnvDedupWindow = "QUEUE_HTTP_DEDUP_WINDOW"
+)
+
+// DefaultDedupWindow is how long a client ID keeps a retried POST /tasks
+// from creating a second task.
+const DefaultDedupWindow = 24 * time.Hour
+
+// IdempotencyHeader is the header alternative to the client_id field.
+const IdempotencyHeader = "Idempotency-Key"
+
+// maxClientIDLen bounds client IDs, which are stored with the task.
+const maxClientIDLen = 200
+
+// Server is a small JSON API for enqueueing and listing tasks from scripts.
+type Server struct {
+	q        *queue.TaskQueue
+	token    string
+	onChange func()
+	onAdd    func(queue.Task)
+	dedup    time.Duration
+
+	mu  sync.Mutex
+	srv *http.Server
+}
+
+func New(q *queue.TaskQueue, token string) *Server {
+	return &Server{q: q, token: token, dedup: DefaultDedupWindow}
+}
+
+// SetDedupWindow sets how long a client ID is remembered; 0 turns
+// deduplication off. Call it before ListenAndServe.
+func (s *Server) SetDedupWindow(d time.Duration) {
+	s.dedup = max(d, 0)
+}
+
+// SetOnChange sets a callback invoked after the queue is modified via the API.
+func (s *Server) SetOnChange(fn func()) {
+	s.onChange = fn
+}
+
+// SetOnAdd sets a callback invoked with each task enqueued via the API.
+// It runs on the request goroutine after the task is saved, so it should
+// return quickly.
+func (s *Server) SetOnAdd(fn func(queue.Task)) {
+	s.onAdd = fn
+}
+
+// ListenAndServe blocks serving the API on addr. After Shutdown it returns
+// http.ErrServerClosed.
+func (s *Server) ListenAndServe(addr string) error {
+	srv := s.server(addr)
+	return srv.ListenAndServe()
+}
+
+// Serve is ListenAndServe on a listener the caller opened.
+func (s *Server) Serve(ln net.Listener) error {
+	srv := s.server(ln.Addr().String())
+	return srv.Serve(ln)
+}
+
+// server returns the http.Server, creating it on first use. Once Shutdown
+// has run it is a closed server with no handler.
+func (s *Server) server(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tasks", s.handleTasks)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.srv == nil {
+		s.srv = &http.Server{
+			Addr:              addr,
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+	}
+	return s.srv
+}
+
+// Shutdown stops the server, waiting until ctx is done for open requests to
+// finish. A server shut down before it started never listens.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if s.srv == nil {
+		s.srv = &http.Server{}
+	}
+	srv := s.srv
+	s.mu.Unlock()
+	return srv.Shutdown(ctx)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	if s.token == "" {
+		return true
+	}
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) == 1
+}
+
+func (s *Server) handleTasks(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.q.GetAll())
+	case http.MethodPost:
+		s.handleCreate(w, r)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	var req struct {
+		Text      string   `json:"text"`
+		Priority  int      `json:"priority"`
+		BlockedBy []string `json:"blocked_by"`
+		Color     string   `json:"color"`
+		ClientID  string   `json:"client_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad json", http.StatusBadRequest)
+		return
+	}
+	clientID := strings.TrimSpace(req.ClientID)
+	if key := strings.TrimSpace(r.Header.Get(IdempotencyHeader)); key != "" {
+		if clientID != "" && clientID != key {
+			http.Error(w, "client_id and "+IdempotencyHeader+" differ", http.StatusBadRequest)
+			return
+		}
+		clientID = key
+	}
+	if len(clientID) > maxClientIDLen {
+		http.Error(w, "client_id is too long", http.StatusBadRequest)
+		return
+	}
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		http.Error(w, "text required", http.StatusBadRequest)
+		return
+	}
+	if !queue.ValidColor(req.Color) {
+		http.Error(w, "bad color: "+req.Color, http.StatusBadRequest)
+		return
+	}
+	t := queue.Task{
+		ID:        strconv.FormatInt(time.Now().UnixNano(), 10),
+		Text:      text,
+		CreatedAt: time.Now(),
+		Priority:  req.Priority,
+		BlockedBy: req.BlockedBy,
+		Color:     req.Color,
+		ClientID:  clientID,
+	}
+	existing, added, err := s.q.EnqueueOnce(t, s.dedup)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, queue.ErrQueueFull):
+			status = http.StatusTooManyRequests
+		case errors.Is(err, queue.ErrDependencyCycle):
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if !added {
+		// A retry of a request that already created its task.
+		writeJSON(w, http.StatusOK, existing)
+		return
+	}
+	if stored, ok := s.q.GetByID(t.ID); ok {
+		t = stored
+	}
+	if s.onChange != nil {
+		s.onChange()
+	}
+	if s.onAdd != nil {
+		s.onAdd(t)
+	}
+	writeJSON(w, http.StatusCreated, t)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(data)
+}
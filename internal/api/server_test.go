@@ -0,0 +1,132 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Ameight/systray-queue-app/internal/queue"
+)
+
+func newTestServer(t *testing.T) (*Server, string, <-chan error) {
+	t.Helper()
+	t.Setenv(queue.EnvPassphrase, "")
+	t.Setenv(queue.EnvBackend, "")
+	q, err := queue.NewTaskQueue(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = q.Close() })
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New(q, "")
+	served := make(chan error, 1)
+	go func() { served <- s.Serve(ln) }()
+	return s, ln.Addr().String(), served
+}
+
+// startPost sends the headers and the first half of a POST /tasks body, so
+// the handler is left waiting for the rest.
+func startPost(t *testing.T, addr, body string) (net.Conn, string) {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	half := len(body) / 2
+	fmt.Fprintf(conn, "POST /tasks HTTP/1.1\r\nHost: %s\r\nContent-Type: application/json\r\nContent-Length: %d\r\n\r\n%s", addr, len(body), body[:half])
+	time.Sleep(100 * time.Millisecond) // let the server start reading
+	return conn, body[half:]
+}
+
+func TestShutdownWaitsForInFlightRequest(t *testing.T) {
+	s, addr, served := newTestServer(t)
+	conn, rest := startPost(t, addr, `{"text":"sent during shutdown"}`)
+
+	shut := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		shut <- s.Shutdown(ctx)
+	}()
+	select {
+	case err := <-shut:
+		t.Fatalf("Shutdown returned %v with a request still open", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	if _, err := conn.Write([]byte(rest)); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		t.Fatalf("in-flight request got %s", resp.Status)
+	}
+	select {
+	case err := <-shut:
+		if err != nil {
+			t.Fatalf("Shutdown = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Shutdown did not return after the last request finished")
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		t.Fatalf("Serve = %v, want http.ErrServerClosed", err)
+	}
+	if n := s.q.Count(); n != 1 {
+		t.Fatalf("queue has %d tasks, want the one sent during shutdown", n)
+	}
+}
+
+func TestShutdownStopsWithinTimeout(t *testing.T) {
+	s, addr, _ := newTestServer(t)
+	startPost(t, addr, `{"text":"never finished"}`)
+
+	const timeout = 300 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	start := time.Now()
+	err := s.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown = %v, want context.DeadlineExceeded", err)
+	}
+	if took := time.Since(start); took > timeout+time.Second {
+		t.Fatalf("Shutdown took %v with a %v timeout", took, timeout)
+	}
+	if _, err := net.DialTimeout("tcp", addr, 500*time.Millisecond); err == nil {
+		t.Fatal("server still accepts connections after Shutdown")
+	}
+}
+
+func TestShutdownBeforeServe(t *testing.T) {
+	t.Setenv(queue.EnvPassphrase, "")
+	t.Setenv(queue.EnvBackend, "")
+	q, err := queue.NewTaskQueue(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+	s := New(q, "")
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		t.Fatalf("Serve after Shutdown = %v, want http.ErrServerClosed", err)
+	}
+}
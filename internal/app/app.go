@@ -1,36 +1,90 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
 	"os/exec"
+	"path"
+	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/getlantern/systray"
 
+	"github.com/Ameight/systray-queue-app/internal/api"
 	"github.com/Ameight/systray-queue-app/internal/hotkeys"
+	"github.com/Ameight/systray-queue-app/internal/i18n"
 	"github.com/Ameight/systray-queue-app/internal/manage"
 	"github.com/Ameight/systray-queue-app/internal/queue"
 	"github.com/Ameight/systray-queue-app/internal/ui"
 	"github.com/Ameight/systray-queue-app/internal/updater"
 	"github.com/Ameight/systray-queue-app/internal/util"
+	"github.com/Ameight/systray-queue-app/internal/webhook"
 )
 
 var favicon []byte
 
-func Run(faviconData []byte) {
+// Run shows the tray icon and blocks until the app quits, from the menu or
+// because ctx is cancelled (e.g. on SIGINT).
+func Run(ctx context.Context, faviconData []byte) {
 	favicon = faviconData
+	i18n.Init()
+	appCtx, appCancel = context.WithCancel(ctx)
+	defer appCancel()
 	runtime.LockOSThread()
 	systray.Run(onReady, onExit)
 }
 
+// shutdownTimeout bounds how long onExit waits for servers and background
+// goroutines before giving up on them.
+const shutdownTimeout = 3 * time.Second
+
 var (
 	q      *queue.TaskQueue
 	mgr    *manage.Server
+	apiSrv *api.Server
 	hkRegs []hotkeys.Registered
+	// instance keeps a second tray app from running on the same data
+	// directory; nil if the lock could not be taken for another reason.
+	instance *util.InstanceLock
+
+	// appCtx is cancelled when the app starts quitting; background
+	// goroutines started with goBackground return when it is done.
+	appCtx    context.Context
+	appCancel context.CancelFunc
+	bg        sync.WaitGroup
+
+	// maxAttachmentSize mirrors KeyConfig.MaxAttachmentSize; updated on settings reload.
+	maxAttachmentSize atomic.Int64
+	// confirmRemoval mirrors KeyConfig.IsConfirmRemovalEnabled.
+	confirmRemoval atomic.Bool
+	// doneIcon mirrors KeyConfig.DoneIcon.
+	doneIcon atomic.Bool
+	// nativeTaskView mirrors KeyConfig.NativeTaskView.
+	nativeTaskView atomic.Bool
+	// autoArchiveDays mirrors KeyConfig.AutoArchiveDays.
+	autoArchiveDays atomic.Int64
+	// doneSound and doneSoundFile mirror KeyConfig.DoneSound and
+	// KeyConfig.DoneSoundFile; soundPlaying is set while the sound plays.
+	doneSound     atomic.Bool
+	doneSoundFile atomic.Pointer[string]
+	soundPlaying  atomic.Bool
+	// queueCleared is set when the last task is completed or deleted and
+	// cleared once a task is added; refreshAll shows IconDone while it is set.
+	queueCleared atomic.Bool
+	// completionWebhook is built from KeyConfig.WebhookURL; nil when none is set.
+	completionWebhook atomic.Pointer[webhook.Sender]
 )
 
 // ── Timer state ───────────────────────────────────────────────────────────────
@@ -118,6 +172,41 @@ func fmtElapsed(d time.Duration) string {
 	return fmt.Sprintf("%dh %dm", h, m)
 }
 
+// fmtSince describes how long ago something happened in the short form the
+// tooltip uses: "just now", "5 min ago", "2 h ago", "3 d ago".
+func fmtSince(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return i18n.T("just now")
+	case d < time.Hour:
+		return i18n.Tf("%d min ago", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return i18n.Tf("%d h ago", int(d/time.Hour))
+	}
+	return i18n.Tf("%d d ago", int(d/(24*time.Hour)))
+}
+
+// titleWithCount appends the queue length badge to the menubar title.
+func titleWithCount(title string, count int) string {
+	if count == 0 {
+		return title
+	}
+	return fmt.Sprintf("%s (%d)", title, count)
+}
+
+// confirmRemove asks before a task is completed or deleted, unless the
+// confirmation is turned off in settings.
+func confirmRemove(title, question, okLabel string, t queue.Task) bool {
+	if !confirmRemoval.Load() {
+		return true
+	}
+	text := strings.TrimSpace(t.Text)
+	if runes := []rune(text); len(runes) > 100 {
+		text = string(runes[:100]) + "…"
+	}
+	return ui.Confirm(title, question+"\n\n"+text, okLabel)
+}
+
 func taskPreview(text string) string {
 	line := text
 	if idx := strings.IndexByte(line, '\n'); idx >= 0 {
@@ -131,14 +220,223 @@ func taskPreview(text string) string {
 	return line
 }
 
+// ── Log file ──────────────────────────────────────────────────────────────────
+
+// Log file rotation: app.log plus app.log.1 and app.log.2, 1 MB each.
+const (
+	logFileSize  = 1 << 20
+	logFileCount = 3
+)
+
+var logFile *util.RotatingLog
+
+// setupLogFile sends the standard logger to dataDir/app.log, and to stderr as
+// well when the app was started from a terminal. Launched from the desktop,
+// stderr goes nowhere, so the file is the only record of what failed.
+func setupLogFile(dataDir string) {
+	lf, err := util.OpenRotatingLog(filepath.Join(dataDir, "app.log"), logFileSize, logFileCount)
+	if err != nil {
+		log.Printf("log file: %v", err)
+		return
+	}
+	logFile = lf
+	var w io.Writer = lf
+	if util.IsTerminal(os.Stderr) {
+		w = io.MultiWriter(os.Stderr, lf)
+	}
+	log.SetOutput(w)
+	log.Printf("[app] started, data in %s", dataDir)
+}
+
+// ── Background work ───────────────────────────────────────────────────────────
+
+// goBackground runs fn on its own goroutine; onExit waits for it to return
+// after cancelling appCtx.
+func goBackground(fn func(ctx context.Context)) {
+	bg.Add(1)
+	go func() {
+		defer bg.Done()
+		fn(appCtx)
+	}()
+}
+
+// postCompletion sends a completed task to the webhook in the background.
+// Delivery failures are only logged: the task stays completed.
+func postCompletion(t queue.Task) {
+	s := completionWebhook.Load()
+	if s == nil {
+		return
+	}
+	goBackground(func(ctx context.Context) {
+		if err := s.Send(ctx, t); err != nil {
+			log.Printf("[webhook] task %s: %v", t.ID, err)
+		}
+	})
+}
+
+// playDoneSound plays the completion sound in the background when it is
+// turned on. Failures are only logged: the task stays completed. One sound
+// plays at a time, so completing several tasks at once chimes once.
+func playDoneSound() {
+	if !doneSound.Load() || !soundPlaying.CompareAndSwap(false, true) {
+		return
+	}
+	var path string
+	if p := doneSoundFile.Load(); p != nil {
+		path = *p
+	}
+	go func() {
+		defer soundPlaying.Store(false)
+		if err := util.PlaySound(path); err != nil {
+			log.Printf("[sound] %v", err)
+		}
+	}()
+}
+
+// every calls fn each interval d until ctx is done.
+func every(ctx context.Context, d time.Duration, fn func()) {
+	t := time.NewTicker(d)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			fn()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 // ── OS notification ───────────────────────────────────────────────────────────
 
-func sendNotification(title, body string) {
-	switch runtime.GOOS {
-	case "darwin":
-		script := fmt.Sprintf(`display notification %q with title %q sound name "Glass"`, body, title)
-		_ = exec.Command("osascript", "-e", script).Run()
+// notify shows a desktop notification in the background. It never blocks the
+// caller, and failures are only logged.
+func notify(title, body string) {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("[notify] panic: %v", r)
+			}
+		}()
+		var err error
+		switch runtime.GOOS {
+		case "darwin":
+			// osascript lets us add a sound, which zenity cannot.
+			script := fmt.Sprintf(`display notification %q with title %q sound name "Glass"`, body, title)
+			err = exec.Command("osascript", "-e", script).Run()
+		default:
+			err = ui.Notify(title, body)
+		}
+		if err != nil {
+			log.Printf("[notify] %v", err)
+		}
+	}()
+}
+
+// ── Upcoming submenu ──────────────────────────────────────────────────────────
+
+// upcomingCount is how many tasks after the current one the Upcoming
+// submenu lists.
+const upcomingCount = 5
+
+var (
+	upcomingItems []*systray.MenuItem
+	upcomingMu    sync.Mutex
+	upcomingIDs   []string // task shown by each visible item, in order
+)
+
+// refreshUpcoming lists the tasks that come after the current one, as
+// q.Upcoming orders them.
+func refreshUpcoming() {
+	next := q.Upcoming(upcomingCount)
+	upcomingMu.Lock()
+	defer upcomingMu.Unlock()
+	upcomingIDs = upcomingIDs[:0]
+	for i, item := range upcomingItems {
+		if i >= len(next) {
+			item.Hide()
+			continue
+		}
+		item.SetTitle(fmt.Sprintf("%d. %s", i+1, taskPreview(next[i].Text)))
+		item.Show()
+		upcomingIDs = append(upcomingIDs, next[i].ID)
+	}
+}
+
+// watchUpcoming opens the preview of an Upcoming task when its item is
+// clicked. The items are created in a loop, so they cannot be cases of the
+// menu's select.
+func watchUpcoming(ctx context.Context) {
+	for i, item := range upcomingItems {
+		go func() {
+			for {
+				select {
+				case <-item.ClickedCh:
+					openUpcoming(i)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+}
+
+// openUpcoming previews the task behind item i at its current position; it
+// may have moved since the menu was built.
+func openUpcoming(i int) {
+	upcomingMu.Lock()
+	var id string
+	if i < len(upcomingIDs) {
+		id = upcomingIDs[i]
+	}
+	upcomingMu.Unlock()
+	for pos, t := range q.GetAll() {
+		if t.ID == id {
+			_ = openURL(fmt.Sprintf("/view?index=%d", pos))
+			return
+		}
+	}
+}
+
+// ── Browse cursor ─────────────────────────────────────────────────────────────
+
+// browse is a read cursor over the queue for Next task / Previous task: it
+// steps through the tasks without changing their order. It starts over from
+// the current task whenever the order of the queue changes.
+var browse struct {
+	mu  sync.Mutex
+	ids []string // queue order the cursor was set against
+	pos int
+}
+
+// browseStep moves the cursor by delta, wrapping around at either end, and
+// shows the task it lands on in a notification.
+func browseStep(delta int) {
+	tasks := q.GetAll()
+	if len(tasks) == 0 {
+		return
+	}
+	ids := make([]string, len(tasks))
+	for i, t := range tasks {
+		ids[i] = t.ID
 	}
+	head, _ := q.Peek()
+
+	browse.mu.Lock()
+	if !slices.Equal(ids, browse.ids) {
+		browse.ids = ids
+		browse.pos = max(slices.Index(ids, head.ID), 0)
+	}
+	browse.pos = ((browse.pos+delta)%len(tasks) + len(tasks)) % len(tasks)
+	pos := browse.pos
+	browse.mu.Unlock()
+
+	t := tasks[pos]
+	title := i18n.Tf("Task %d of %d", pos+1, len(tasks))
+	if t.ID == head.ID {
+		title = i18n.Tf("Task %d of %d (current)", pos+1, len(tasks))
+	}
+	notify(title, taskPreview(t.Text))
 }
 
 // ── App ───────────────────────────────────────────────────────────────────────
@@ -150,6 +448,23 @@ func onReady() {
 		systray.Quit()
 		return
 	}
+	if err := util.CheckWritable(dataDir); err != nil {
+		log.Fatalf("data directory %s is not writable (set %s to use another one): %v", dataDir, util.EnvDataDir, err)
+	}
+	// Checked before opening app.log so that the second copy does not
+	// rotate the first one's log.
+	instance, err = util.LockInstance(filepath.Join(dataDir, "app.lock"))
+	if errors.Is(err, util.ErrAlreadyRunning) {
+		log.Printf("[app] %v on %s; exiting", err, dataDir)
+		ui.Info(i18n.T("Queue"), i18n.T("Queue is already running: use its icon in the menu bar or system tray.\n\nThe command-line interface can be used alongside it."))
+		systray.Quit()
+		return
+	}
+	if err != nil {
+		// queue.json is still protected by the per-write lock.
+		log.Printf("[app] instance lock: %v", err)
+	}
+	setupLogFile(dataDir)
 
 	q, err = queue.NewTaskQueue(dataDir)
 	if err != nil {
@@ -158,15 +473,67 @@ func onReady() {
 		return
 	}
 
+	if err := q.RemoveOpenedCopies(); err != nil {
+		log.Printf("[app] remove decrypted copies: %v", err)
+	}
+
+	if r := q.AttachmentReport(); r.Missing > 0 {
+		notify(i18n.T("Queue"), i18n.Tf("%d of %d attachments are missing from %s. See app.log for the tasks.", r.Missing, r.Checked, q.AttachmentsDir()))
+	}
+
 	mgr = manage.New(q, dataDir, favicon)
 
-	systray.SetIcon(ui.MakeTemplateIcon())
-	systray.SetTitle("Queue")
-	systray.SetTooltip("Queue")
+	// refreshAll runs on the tray, timer, reload and API goroutines; iconMu
+	// keeps lastIcon in step with the icon actually shown.
+	var iconMu sync.Mutex
+	lastIcon := ui.TrayIcon(-1)
+	updateIcon := func(state ui.TrayIcon) {
+		iconMu.Lock()
+		defer iconMu.Unlock()
+		if state == lastIcon {
+			return
+		}
+		lastIcon = state
+		icon, template := ui.TrayIconData(state)
+		if template != nil {
+			// Only macOS uses the template; elsewhere this sets icon.
+			systray.SetTemplateIcon(template, icon)
+		} else {
+			systray.SetIcon(icon)
+		}
+	}
+	updateIcon(ui.IconIdle)
+	systray.SetTitle(i18n.T("Queue"))
+	systray.SetTooltip(i18n.T("Queue"))
 
 	// ── Load config early (needed for menu order + timer duration) ────────
 	cfg, cfgPath, cfgErr := hotkeys.LoadOrCreate(dataDir)
 	timerDuration = cfg.TimerDuration()
+	maxAttachmentSize.Store(cfg.MaxAttachmentSize())
+	applyAttachmentTypes(cfg)
+	confirmRemoval.Store(cfg.IsConfirmRemovalEnabled())
+	doneIcon.Store(cfg.DoneIcon)
+	nativeTaskView.Store(cfg.NativeTaskView)
+	autoArchiveDays.Store(int64(cfg.AutoArchiveDays()))
+	doneSound.Store(cfg.DoneSound)
+	doneSoundFile.Store(&cfg.DoneSoundFile)
+	ui.SetDialogTimeout(cfg.DialogTimeout())
+	q.SetMaxLen(cfg.QueueLimit())
+	q.SetBackupCount(cfg.BackupCount())
+	q.SetDueFirst(cfg.SortByDue)
+	completionWebhook.Store(webhook.New(cfg.WebhookURL, cfg.WebhookSecret))
+	q.SetOnComplete(func(t queue.Task) {
+		postCompletion(t)
+		playDoneSound()
+	})
+	q.SetOnEmpty(func() {
+		queueCleared.Store(true)
+		notify(i18n.T("Queue"), i18n.T("The queue is empty — great job!"))
+	})
+	q.SetOnSaveError(func(err error) {
+		// Called with the queue locked; the dialog must not hold it up.
+		go ui.Error(i18n.T("Queue"), i18n.Tf("Could not save the queue: %v\n\nThe change was undone. Check that the data folder is writable and try again.", err))
+	})
 
 	// ── Build menu in configured group order ──────────────────────────────
 	//
@@ -179,10 +546,43 @@ func onReady() {
 		mTaskTitle   *systray.MenuItem
 		mTimer       *systray.MenuItem
 		mSkip        *systray.MenuItem
+		mCopy        *systray.MenuItem
+		mOpenAttach  *systray.MenuItem
 		mDone        *systray.MenuItem
+		mDoneNote    *systray.MenuItem
+		mUndo        *systray.MenuItem
+		mEdit        *systray.MenuItem
+		mDelete      *systray.MenuItem
+		mBulk        *systray.MenuItem
+		mPromote     *systray.MenuItem
+		mPin         *systray.MenuItem
+		mDuplicate   *systray.MenuItem
+		mSaveTmpl    *systray.MenuItem
+		mFromTmpl    *systray.MenuItem
+		mSnooze      *systray.MenuItem
+		mUpcoming    *systray.MenuItem
+		mBrowseNext  *systray.MenuItem
+		mBrowsePrev  *systray.MenuItem
 		mAddQuick    *systray.MenuItem
+		mAddText     *systray.MenuItem
 		mAddAdvanced *systray.MenuItem
+		mFocus       *systray.MenuItem
 		mQueue       *systray.MenuItem
+		mList        *systray.MenuItem
+		mHistory     *systray.MenuItem
+		mStats       *systray.MenuItem
+		mFilter      *systray.MenuItem
+		mSearch      *systray.MenuItem
+		mImport      *systray.MenuItem
+		mExport      *systray.MenuItem
+		mExportTask  *systray.MenuItem
+		mCopyMD      *systray.MenuItem
+		mCleanup     *systray.MenuItem
+		mDedup       *systray.MenuItem
+		mDataDir     *systray.MenuItem
+		mClear       *systray.MenuItem
+		mRestore     *systray.MenuItem
+		mArchive     *systray.MenuItem
 		mSettings    *systray.MenuItem
 		mQuit        *systray.MenuItem
 	)
@@ -198,24 +598,64 @@ func onReady() {
 		var items []*systray.MenuItem
 		switch g.ID {
 		case "task":
-			mTaskTitle = systray.AddMenuItem("No tasks", "Click to view current task")
-			items = []*systray.MenuItem{mTaskTitle}
+			mTaskTitle = systray.AddMenuItem(i18n.T("No tasks"), i18n.T("Click to view current task"))
+			mUpcoming = systray.AddMenuItem(i18n.T("Upcoming"), i18n.T("The next tasks in line"))
+			// Menu items cannot be removed, so a fixed set of sub-items is
+			// retitled and shown or hidden by refreshAll.
+			for range upcomingCount {
+				sub := mUpcoming.AddSubMenuItem("", i18n.T("Preview this task"))
+				sub.Hide()
+				upcomingItems = append(upcomingItems, sub)
+			}
+			mBrowseNext = systray.AddMenuItem(i18n.T("Next task"), i18n.T("Show the next task in line without changing the order"))
+			mBrowsePrev = systray.AddMenuItem(i18n.T("Previous task"), i18n.T("Show the previous task in line without changing the order"))
+			items = []*systray.MenuItem{mTaskTitle, mUpcoming, mBrowseNext, mBrowsePrev}
 		case "timer":
-			mTimer = systray.AddMenuItem("Start timer", "Start a focus timer")
+			mTimer = systray.AddMenuItem(i18n.T("Start timer"), i18n.T("Start a focus timer"))
 			items = []*systray.MenuItem{mTimer}
 		case "actions":
-			mSkip = systray.AddMenuItem("Skip", "Move current task to the end")
-			mDone = systray.AddMenuItem("Done", "Complete current task")
-			items = []*systray.MenuItem{mSkip, mDone}
+			mSkip = systray.AddMenuItem(i18n.T("Skip"), i18n.T("Move current task to the end"))
+			mDone = systray.AddMenuItem(i18n.T("Done"), i18n.T("Complete current task"))
+			mDoneNote = systray.AddMenuItem(i18n.T("Done with note…"), i18n.T("Complete current task and record the outcome in history"))
+			mUndo = systray.AddMenuItem(i18n.T("Undo"), i18n.T("Revert the last complete, skip or delete"))
+			mEdit = systray.AddMenuItem(i18n.T("Edit task…"), i18n.T("Edit current task text"))
+			mCopy = systray.AddMenuItem(i18n.T("Copy text"), i18n.T("Copy the current task's text to the clipboard"))
+			mOpenAttach = systray.AddMenuItem(i18n.T("Open attachment…"), i18n.T("Open an attachment of the current task in its default app"))
+			mPromote = systray.AddMenuItem(i18n.T("Move to front…"), i18n.T("Pick a task to make current"))
+			mPin = systray.AddMenuItem(i18n.T("Pin task…"), i18n.T("Keep a task current, even after skips"))
+			mSnooze = systray.AddMenuItem(i18n.T("Snooze…"), i18n.T("Hide the current task for a while"))
+			mDuplicate = systray.AddMenuItem(i18n.T("Duplicate task…"), i18n.T("Pick a task to copy to the end of the queue"))
+			mSaveTmpl = systray.AddMenuItem(i18n.T("Save as template…"), i18n.T("Keep the current task's text, tags, priority and recurrence to add again later"))
+			mDelete = systray.AddMenuItem(i18n.T("Delete task…"), i18n.T("Pick a task to delete"))
+			mBulk = systray.AddMenuItem(i18n.T("Bulk actions…"), i18n.T("Complete, skip or delete several tasks at once"))
+			items = []*systray.MenuItem{mSkip, mDone, mDoneNote, mUndo, mSnooze, mEdit, mCopy, mOpenAttach, mPromote, mPin, mDuplicate, mSaveTmpl, mDelete, mBulk}
 		case "navigation":
-			mAddQuick = systray.AddMenuItem("Add task", "Quick add")
-			mAddAdvanced = systray.AddMenuItem("Add task (advanced)", "Open advanced editor in browser")
-			mQueue = systray.AddMenuItem("All tasks", "View and manage all tasks")
-			items = []*systray.MenuItem{mAddQuick, mAddAdvanced, mQueue}
+			mAddQuick = systray.AddMenuItem(i18n.T("Add task"), i18n.T("Quick add"))
+			mAddText = systray.AddMenuItem(i18n.T("Add text only…"), i18n.T("Add a task from a single line of text, no further questions"))
+			mFromTmpl = systray.AddMenuItem(i18n.T("Add from template…"), i18n.T("Add a task from a saved template"))
+			mAddAdvanced = systray.AddMenuItem(i18n.T("Add task (advanced)"), i18n.T("Open advanced editor in browser"))
+			mFocus = systray.AddMenuItem(i18n.T("Focus"), i18n.T("Show only the current task, with Done and Skip"))
+			mQueue = systray.AddMenuItem(i18n.T("All tasks"), i18n.T("View and manage all tasks"))
+			mList = systray.AddMenuItem(i18n.T("Show queue"), i18n.T("Overview of the whole queue; drag rows to reorder"))
+			mFilter = systray.AddMenuItem(i18n.T("Filter by tag…"), i18n.T("Show tasks with a tag"))
+			mSearch = systray.AddMenuItem(i18n.T("Search…"), i18n.T("Find tasks by text or tag"))
+			mHistory = systray.AddMenuItem(i18n.T("History"), i18n.T("View completed tasks"))
+			mStats = systray.AddMenuItem(i18n.T("Statistics"), i18n.T("Completed tasks per day"))
+			items = []*systray.MenuItem{mAddQuick, mAddText, mFromTmpl, mAddAdvanced, mFocus, mQueue, mList, mFilter, mSearch, mHistory, mStats}
 		case "system":
-			mSettings = systray.AddMenuItem("Settings", "Configure hotkeys")
-			mQuit = systray.AddMenuItem("Quit", "Quit")
-			items = []*systray.MenuItem{mSettings, mQuit}
+			mImport = systray.AddMenuItem(i18n.T("Import…"), i18n.T("Add tasks from a .txt/.csv file or an export bundle"))
+			mExport = systray.AddMenuItem(i18n.T("Export…"), i18n.T("Save the queue with attachments as a zip"))
+			mExportTask = systray.AddMenuItem(i18n.T("Export task…"), i18n.T("Save the current task as a self-contained HTML file to share"))
+			mCopyMD = systray.AddMenuItem(i18n.T("Copy as Markdown…"), i18n.T("Copy or save the queue as a Markdown checklist"))
+			mDataDir = systray.AddMenuItem(i18n.T("Open data folder"), i18n.T("Show queue.json, history and attachments in the file manager"))
+			mCleanup = systray.AddMenuItem(i18n.T("Clean up attachments…"), i18n.T("Delete attachment files no task uses"))
+			mDedup = systray.AddMenuItem(i18n.T("Find duplicates…"), i18n.T("Find tasks with the same text and delete the copies"))
+			mClear = systray.AddMenuItem(i18n.T("Empty queue…"), i18n.T("Remove every task after saving a backup"))
+			mRestore = systray.AddMenuItem(i18n.T("Restore from backup…"), i18n.T("Put the queue back as it was at an earlier save"))
+			mArchive = systray.AddMenuItem(i18n.T("Archive…"), i18n.T("View the tasks moved out of the queue for their age and restore them"))
+			mSettings = systray.AddMenuItem(i18n.T("Settings"), i18n.T("Configure hotkeys"))
+			mQuit = systray.AddMenuItem(i18n.T("Quit"), i18n.T("Quit"))
+			items = []*systray.MenuItem{mImport, mExport, mExportTask, mCopyMD, mDataDir, mCleanup, mDedup, mClear, mRestore, mArchive, mSettings, mQuit}
 		}
 		groupItems[g.ID] = items
 		if !g.Visible {
@@ -226,6 +666,7 @@ func onReady() {
 			}
 		}
 	}
+	watchUpcoming(appCtx)
 
 	// applyVisibility applies show/hide for all groups from a fresh config.
 	applyVisibility := func(newGroups []hotkeys.TrayGroupConfig) {
@@ -247,27 +688,53 @@ func onReady() {
 	// ── refreshAll updates all dynamic tray content ───────────────────────
 
 	refreshAll := func() {
-		count := len(q.GetAll())
+		count := q.Count()
 		task, hasTask := q.Peek()
 		active, paused, remain := timerSnapshot()
 
 		// Task title item
 		if mTaskTitle != nil {
-			if hasTask {
+			if hasTask && task.Pinned {
+				mTaskTitle.SetTitle("📌 " + taskPreview(task.Text))
+				mTaskTitle.Enable()
+			} else if hasTask {
 				mTaskTitle.SetTitle(taskPreview(task.Text))
 				mTaskTitle.Enable()
+			} else if count > 0 {
+				mTaskTitle.SetTitle(i18n.T("All tasks snoozed"))
+				mTaskTitle.Disable()
 			} else {
-				mTaskTitle.SetTitle("No tasks")
+				mTaskTitle.SetTitle(i18n.T("No tasks"))
 				mTaskTitle.Disable()
 			}
 		}
+		if mUpcoming != nil {
+			refreshUpcoming()
+		}
+		for _, m := range []*systray.MenuItem{mBrowseNext, mBrowsePrev} {
+			if m == nil {
+				continue
+			}
+			if count > 1 {
+				m.Enable()
+			} else {
+				m.Disable()
+			}
+		}
 		if mSkip != nil {
-			if hasTask {
+			if hasTask && !task.Pinned {
 				mSkip.Enable()
 			} else {
 				mSkip.Disable()
 			}
 		}
+		if mFocus != nil {
+			if hasTask {
+				mFocus.Enable()
+			} else {
+				mFocus.Disable()
+			}
+		}
 		if mDone != nil {
 			if hasTask {
 				mDone.Enable()
@@ -275,6 +742,133 @@ func onReady() {
 				mDone.Disable()
 			}
 		}
+		if mDoneNote != nil {
+			if hasTask {
+				mDoneNote.Enable()
+			} else {
+				mDoneNote.Disable()
+			}
+		}
+		if mUndo != nil {
+			if action := q.UndoAction(); action != "" {
+				mUndo.SetTitle(i18n.T("Undo " + action))
+				mUndo.Enable()
+			} else {
+				mUndo.SetTitle(i18n.T("Undo"))
+				mUndo.Disable()
+			}
+		}
+		if mEdit != nil {
+			if hasTask {
+				mEdit.Enable()
+			} else {
+				mEdit.Disable()
+			}
+		}
+		if mCopy != nil {
+			if hasTask {
+				mCopy.Enable()
+			} else {
+				mCopy.Disable()
+			}
+		}
+		if mOpenAttach != nil {
+			if head, ok := q.Peek(); ok && len(head.Attachments) > 0 {
+				mOpenAttach.Enable()
+			} else {
+				mOpenAttach.Disable()
+			}
+		}
+		if mPromote != nil {
+			if count > 1 {
+				mPromote.Enable()
+			} else {
+				mPromote.Disable()
+			}
+		}
+		if mPin != nil {
+			if _, ok := q.Pinned(); ok {
+				mPin.SetTitle(i18n.T("Unpin task"))
+				mPin.Enable()
+			} else {
+				mPin.SetTitle(i18n.T("Pin task…"))
+				if count > 0 {
+					mPin.Enable()
+				} else {
+					mPin.Disable()
+				}
+			}
+		}
+		if mExportTask != nil {
+			if hasTask {
+				mExportTask.Enable()
+			} else {
+				mExportTask.Disable()
+			}
+		}
+		if mArchive != nil {
+			if q.Archive().Count() > 0 {
+				mArchive.Enable()
+			} else {
+				mArchive.Disable()
+			}
+		}
+		if mCopyMD != nil {
+			if count > 0 {
+				mCopyMD.Enable()
+			} else {
+				mCopyMD.Disable()
+			}
+		}
+		if mSnooze != nil {
+			if hasTask {
+				mSnooze.Enable()
+			} else {
+				mSnooze.Disable()
+			}
+		}
+		if mDuplicate != nil {
+			if count > 0 {
+				mDuplicate.Enable()
+			} else {
+				mDuplicate.Disable()
+			}
+		}
+		if mSaveTmpl != nil {
+			if hasTask {
+				mSaveTmpl.Enable()
+			} else {
+				mSaveTmpl.Disable()
+			}
+		}
+		if mDelete != nil {
+			if count > 0 {
+				mDelete.Enable()
+			} else {
+				mDelete.Disable()
+			}
+		}
+		if mBulk != nil {
+			if count > 0 {
+				mBulk.Enable()
+			} else {
+				mBulk.Disable()
+			}
+		}
+		if mDedup != nil {
+			if count > 1 {
+				mDedup.Enable()
+			} else {
+				mDedup.Disable()
+			}
+		}
+		if mClear != nil {
+			if count > 0 {
+				mClear.Enable()
+			} else {
+				mClear.Disable()
+			}
+		}
 
 		// Timer item label
 		if mTimer != nil {
@@ -285,16 +879,17 @@ func onReady() {
 			}
 			switch {
 			case active && paused:
-				mTimer.SetTitle("▶ Resume  " + fmtCountdown(remain))
+				mTimer.SetTitle(i18n.T("▶ Resume") + "  " + fmtCountdown(remain))
 			case active:
-				mTimer.SetTitle("⏸ Pause  " + fmtCountdown(remain))
+				mTimer.SetTitle(i18n.T("⏸ Pause") + "  " + fmtCountdown(remain))
 			default:
-				mTimer.SetTitle("Start timer")
+				mTimer.SetTitle(i18n.T("Start timer"))
 			}
 		}
 
 		// Menubar title & tooltip
 		var titleStr string
+		tooltip := i18n.Tf("Tasks: %d", count)
 		if active {
 			titleStr = fmtCountdown(remain)
 		}
@@ -307,160 +902,1197 @@ func onReady() {
 				} else {
 					titleStr = elapsed
 				}
-				systray.SetTooltip(fmt.Sprintf("Tasks: %d · %s on current task", count, elapsed))
-			} else {
-				if !active {
-					titleStr = "Queue"
-				}
-				systray.SetTooltip(fmt.Sprintf("Tasks: %d", count))
+				tooltip = i18n.Tf("Tasks: %d · %s on current task", count, elapsed)
+			} else if !active {
+				titleStr = i18n.T("Queue")
 			}
-		} else {
-			if !active {
-				titleStr = "Queue"
+		} else if !active {
+			titleStr = i18n.T("Queue")
+		}
+		last := "—"
+		if at, ok := q.History().LastCompletedAt(); ok {
+			last = fmtSince(time.Since(at))
+		}
+		systray.SetTooltip(tooltip + "\n" + i18n.Tf("Last completed: %s", last))
+		systray.SetTitle(titleWithCount(titleStr, count))
+
+		iconState := ui.IconIdle
+		if hasTask {
+			iconState = ui.IconPending
+		}
+		if count > 0 {
+			queueCleared.Store(false)
+		} else if queueCleared.Load() && doneIcon.Load() {
+			iconState = ui.IconDone
+		}
+		if count > 0 {
+			now := timeNow()
+			for _, t := range q.GetAll() {
+				if t.IsOverdue(now) {
+					iconState = ui.IconOverdue
+					break
+				}
 			}
-			systray.SetTooltip(fmt.Sprintf("Tasks: %d", count))
 		}
-		systray.SetTitle(titleStr)
+		updateIcon(iconState)
 	}
 	refreshAll()
 
-	// ── Quick add ─────────────────────────────────────────────────────────
+	// ── Remote API (opt-in) ───────────────────────────────────────────────
 
-	quickAdd := func() {
-		text, ok, err := ui.QuickAddText()
-		if err != nil {
-			ui.Error("Add task", err.Error())
-			return
+	if addr := os.Getenv(api.EnvAddr); addr != "" {
+		apiSrv = api.New(q, os.Getenv(api.EnvToken))
+		if raw := os.Getenv(api.EnvDedupWindow); raw != "" {
+			if d, err := time.ParseDuration(raw); err != nil {
+				log.Printf("[api] %s: %v; using %v", api.EnvDedupWindow, err, api.DefaultDedupWindow)
+			} else {
+				apiSrv.SetDedupWindow(d)
+			}
+		}
+		apiSrv.SetOnChange(refreshAll)
+		apiSrv.SetOnAdd(func(t queue.Task) {
+			notify(i18n.T("Queue — Task added"), taskPreview(t.Text))
+		})
+		go func() {
+			log.Printf("[api] listening on %s", addr)
+			if err := apiSrv.ListenAndServe(addr); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("[api] %v", err)
+			}
+		}()
+	}
+
+	// ── Dialog guard ──────────────────────────────────────────────────────
+
+	// Actions that show dialogs run on their own goroutine so the menu loop
+	// never blocks on them, and only one runs at a time: a click while a
+	// dialog is open is ignored.
+	var dialogMu sync.Mutex
+	inDialog := func(fn func()) func() {
+		return func() {
+			go func() {
+				if !dialogMu.TryLock() {
+					log.Printf("[app] a dialog is already open; ignoring the action")
+					return
+				}
+				defer dialogMu.Unlock()
+				fn()
+			}()
 		}
+	}
+
+	// ── Show the current task ─────────────────────────────────────────────
+
+	showTaskDialog := inDialog(func() {
+		t, ok := q.Peek()
 		if !ok {
+			ui.Info(i18n.T("Current task"), i18n.T("No tasks"))
 			return
 		}
-		t := queue.Task{
-			ID:        fmt.Sprintf("%d", timeNowNano()),
-			Text:      text,
-			CreatedAt: timeNow(),
+		ui.ShowTask(t)
+	})
+
+	// showCurrent opens the current task on the manage page, or in a native
+	// dialog when Settings asks for one or the page cannot be opened: no
+	// manage server, or no browser on a minimal setup.
+	showCurrent := func() {
+		if t, ok := q.Peek(); ok {
+			rememberLastTask(t.ID)
 		}
-		if err := q.Enqueue(t); err != nil {
-			ui.Error("Add task", err.Error())
+		if !nativeTaskView.Load() && openBrowserQuiet("/") == nil {
 			return
 		}
-		refreshAll()
+		showTaskDialog()
 	}
 
-	// ── Hotkeys ───────────────────────────────────────────────────────────
-
-	actions := map[string]func(){
-		hotkeys.ActionShowFirst:        func() { _ = openURL("/") },
-		hotkeys.ActionAddQuick:         quickAdd,
-		hotkeys.ActionManageQueue:      func() { _ = openURL("/") },
-		hotkeys.ActionAddFromClipboard: func() { _ = openURL("/add") },
-		hotkeys.ActionSkip:             func() { _ = q.Skip(); refreshAll() },
-		hotkeys.ActionComplete:         func() { _, _ = q.Complete(); timerStop(); refreshAll() },
+	// restoreLastTask reopens the preview of the task showCurrent opened
+	// last, as it was before a restart. A task that has since been
+	// completed or deleted is skipped without a word.
+	restoreLastTask := func() {
+		s, err := util.LoadSettings()
+		if err != nil {
+			log.Printf("[app] restore last task: %v", err)
+			return
+		}
+		if s.LastTaskID == "" {
+			return
+		}
+		for i, t := range q.GetAll() {
+			if t.ID != s.LastTaskID {
+				continue
+			}
+			if !nativeTaskView.Load() && openBrowserQuiet(fmt.Sprintf("/view?index=%d", i)) == nil {
+				return
+			}
+			inDialog(func() { ui.ShowTask(t) })()
+			return
+		}
 	}
 
-	type menuItem struct {
-		item   *systray.MenuItem
-		base   string
-		action string
-	}
-	hotkeyMenuItems := []menuItem{}
-	if mAddQuick != nil {
-		hotkeyMenuItems = append(hotkeyMenuItems, menuItem{mAddQuick, "Quick add", hotkeys.ActionAddQuick})
-	}
-	if mAddAdvanced != nil {
-		hotkeyMenuItems = append(hotkeyMenuItems, menuItem{mAddAdvanced, "Open advanced editor in browser", hotkeys.ActionAddFromClipboard})
-	}
-	if mSkip != nil {
-		hotkeyMenuItems = append(hotkeyMenuItems, menuItem{mSkip, "Move current task to the end", hotkeys.ActionSkip})
-	}
-	if mDone != nil {
-		hotkeyMenuItems = append(hotkeyMenuItems, menuItem{mDone, "Complete current task", hotkeys.ActionComplete})
-	}
-	if mQueue != nil {
-		hotkeyMenuItems = append(hotkeyMenuItems, menuItem{mQueue, "View and manage all tasks", hotkeys.ActionManageQueue})
-	}
+	// ── Quick add ─────────────────────────────────────────────────────────
 
-	applyTooltips := func(c hotkeys.KeyConfig) {
-		for _, m := range hotkeyMenuItems {
-			tooltip := m.base
-			if hc, ok := c.Hotkeys[m.action]; ok && hc.Enabled && hc.Combo != "" {
-				tooltip += "  " + hotkeys.FormatCombo(hc.Combo)
+	// enqueueNew adds a task built by one of the add dialogs and reports
+	// failures the same way for all of them.
+	enqueueNew := func(t queue.Task) {
+		if err := q.EnqueueWithPriority(t); err != nil {
+			if errors.Is(err, queue.ErrQueueFull) {
+				ui.Error(i18n.T("Add task"), i18n.Tf("The queue is full (%d tasks).\nComplete or delete a task first, or raise the limit in Settings.", q.Count()))
+				return
 			}
-			m.item.SetTooltip(tooltip)
+			ui.Error(i18n.T("Add task"), err.Error())
+			return
 		}
+		refreshAll()
 	}
 
-	if cfgErr != nil {
-		ui.Error("Hotkeys", cfgErr.Error())
-	} else {
-		applyTooltips(cfg)
-		hkRegs, err = hotkeys.Register(cfg, actions)
+	// addTextOnly is the short path of quickAdd: just the text dialog, which
+	// trims and rejects empty input the same way.
+	addTextOnly := inDialog(func() {
+		text, ok, err := ui.QuickAddText()
 		if err != nil {
-			ui.Error("Hotkeys", err.Error()+"\nConfig: "+cfgPath)
+			ui.Error(i18n.T("Add task"), err.Error())
+			return
 		}
-	}
-
+		if !ok {
+			return
+		}
+		enqueueNew(queue.Task{
+			ID:        fmt.Sprintf("%d", timeNowNano()),
+			Text:      text,
+			CreatedAt: timeNow(),
+		})
+	})
+
+	quickAdd := inDialog(func() {
+		text, ok, err := ui.QuickAddText()
+		if err != nil {
+			ui.Error(i18n.T("Add task"), err.Error())
+			return
+		}
+		if !ok {
+			return
+		}
+		notes, err := ui.QuickAddNotes()
+		if err != nil {
+			ui.Error(i18n.T("Add task"), err.Error())
+			return
+		}
+		due, err := ui.QuickAddDueDate()
+		if err != nil {
+			ui.Error(i18n.T("Add task"), err.Error())
+			return
+		}
+		reminders, err := ui.QuickAddReminders(timeNow(), due)
+		if err != nil {
+			ui.Error(i18n.T("Add task"), err.Error())
+			return
+		}
+		estimate, err := ui.QuickAddEstimate()
+		if err != nil {
+			ui.Error(i18n.T("Add task"), err.Error())
+			return
+		}
+		prio, err := ui.QuickAddPriority()
+		if err != nil {
+			ui.Error(i18n.T("Add task"), err.Error())
+			return
+		}
+		recur, err := ui.QuickAddRecurrence()
+		if err != nil {
+			ui.Error(i18n.T("Add task"), err.Error())
+			return
+		}
+		tags, err := ui.QuickAddTags()
+		if err != nil {
+			ui.Error(i18n.T("Add task"), err.Error())
+			return
+		}
+		color, err := ui.QuickAddColor()
+		if err != nil {
+			ui.Error(i18n.T("Add task"), err.Error())
+			return
+		}
+		blockers, err := ui.QuickAddBlockers(q.GetAll())
+		if err != nil {
+			ui.Error(i18n.T("Add task"), err.Error())
+			return
+		}
+		var attachments []queue.Attachment
+		choice := ui.QuickAddAttachChoice(util.CanPasteImage(), util.CanRecordAudio(), util.CanCaptureScreen())
+		if choice == ui.AttachURL {
+			raw, ok, err := ui.QuickAddURL()
+			if err != nil {
+				ui.Error(i18n.T("Add task"), err.Error())
+				return
+			}
+			if ok {
+				a, err := downloadAttachment(raw)
+				if err != nil {
+					ui.Error(i18n.T("Attach from URL"), err.Error())
+					return
+				}
+				attachments = append(attachments, a)
+			}
+		}
+		if choice == ui.AttachRecording {
+			a, err := recordAudio()
+			if err != nil {
+				ui.Error(i18n.T("Add task"), err.Error())
+				return
+			}
+			attachments = append(attachments, a)
+		}
+		if choice == ui.AttachScreenshot {
+			a, err := captureScreenshot()
+			switch {
+			case errors.Is(err, util.ErrScreenshotCanceled):
+				// Add the task without the screenshot.
+			case errors.Is(err, util.ErrNoScreenshotTool):
+				ui.Info(i18n.T("Add task"), i18n.T("No screenshot tool was found — pick a file instead."))
+				choice = ui.AttachFiles
+			case err != nil:
+				ui.Error(i18n.T("Add task"), err.Error())
+				return
+			default:
+				attachments = append(attachments, a)
+			}
+		}
+		if choice == ui.AttachClipboard {
+			a, err := pasteClipboardImage()
+			switch {
+			case errors.Is(err, util.ErrNoClipboardImage):
+				ui.Info(i18n.T("Add task"), i18n.T("The clipboard has no image — pick a file instead."))
+				choice = ui.AttachFiles
+			case err != nil:
+				ui.Error(i18n.T("Add task"), err.Error())
+				return
+			default:
+				attachments = append(attachments, a)
+			}
+		}
+		if choice == ui.AttachFiles {
+			srcs, err := ui.QuickAddAttachments()
+			if err != nil {
+				ui.Error(i18n.T("Add task"), err.Error())
+				return
+			}
+			attachments, err = importAttachments(srcs)
+			if err != nil {
+				ui.Error(i18n.T("Add task"), err.Error())
+				return
+			}
+		}
+		t := queue.Task{
+			ID:              fmt.Sprintf("%d", timeNowNano()),
+			Text:            text,
+			Notes:           notes,
+			CreatedAt:       timeNow(),
+			DueDate:         due,
+			Priority:        prio,
+			Attachments:     attachments,
+			Tags:            queue.ParseTags(tags),
+			Recurrence:      recur,
+			EstimateMinutes: estimate,
+			BlockedBy:       blockers,
+			Color:           color,
+			Reminders:       reminders,
+		}
+		enqueueNew(t)
+	})
+
+	// ── Undo ──────────────────────────────────────────────────────────────
+
+	undo := inDialog(func() {
+		if err := q.Undo(); err != nil && !errors.Is(err, queue.ErrNothingToUndo) {
+			ui.Error(i18n.T("Undo"), err.Error())
+		}
+		refreshAll()
+	})
+
+	// ── Filter by tag ─────────────────────────────────────────────────────
+
+	filterByTag := inDialog(func() {
+		tags := q.Tags()
+		if len(tags) == 0 {
+			ui.Info(i18n.T("Filter by tag"), i18n.T("No tasks have tags yet."))
+			return
+		}
+		tag, ok, err := ui.PickTag(tags)
+		if err != nil {
+			ui.Error(i18n.T("Filter by tag"), err.Error())
+			return
+		}
+		if ok {
+			_ = openURL("/tag?name=" + url.QueryEscape(tag))
+		}
+	})
+
+	// ── Import ────────────────────────────────────────────────────────────
+
+	importTasks := inDialog(func() {
+		path, ok, err := ui.PickImportFile()
+		if err != nil {
+			ui.Error(i18n.T("Import"), err.Error())
+			return
+		}
+		if !ok {
+			return
+		}
+		if strings.EqualFold(filepath.Ext(path), ".zip") {
+			n, err := q.ImportBundle(path)
+			if err != nil {
+				ui.Error(i18n.T("Import"), err.Error())
+				return
+			}
+			refreshAll()
+			ui.Info(i18n.T("Import"), i18n.Tf("Imported %d tasks from the bundle.", n))
+			return
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			ui.Error(i18n.T("Import"), err.Error())
+			return
+		}
+		defer f.Close()
+		tasks, skipped, err := queue.ParseImport(f, strings.EqualFold(filepath.Ext(path), ".csv"))
+		if err != nil {
+			ui.Error(i18n.T("Import"), err.Error())
+			return
+		}
+		if err := q.EnqueueAll(tasks); err != nil {
+			ui.Error(i18n.T("Import"), err.Error())
+			return
+		}
+		refreshAll()
+		msg := i18n.Tf("Imported %d tasks.", len(tasks))
+		if skipped > 0 {
+			msg += " " + i18n.Tf("Skipped %d malformed rows.", skipped)
+		}
+		ui.Info(i18n.T("Import"), msg)
+	})
+
+	// ── Export ────────────────────────────────────────────────────────────
+
+	exportQueue := inDialog(func() {
+		path, ok, err := ui.PickExportPath("queue-" + timeNow().Format("2006-01-02") + ".zip")
+		if err != nil {
+			ui.Error(i18n.T("Export"), err.Error())
+			return
+		}
+		if !ok {
+			return
+		}
+		if err := exportBundle(path); err != nil {
+			ui.Error(i18n.T("Export"), err.Error())
+			return
+		}
+		ui.Info(i18n.T("Export"), i18n.Tf("Exported %d tasks to %s.", q.Count(), path))
+	})
+
+	// ── Copy the queue as Markdown ────────────────────────────────────────
+
+	copyMarkdown := inDialog(func() {
+		title := i18n.T("Copy as Markdown")
+		tasks := q.GetAll()
+		if len(tasks) == 0 {
+			return
+		}
+		toFile, ok := ui.AskMarkdownTarget(len(tasks))
+		if !ok {
+			return
+		}
+		md := queue.MarkdownChecklist(tasks)
+		if !toFile {
+			if err := util.CopyText(md); err != nil {
+				ui.Error(title, err.Error())
+				return
+			}
+			notify(i18n.T("Copied to clipboard"), i18n.Tf("%d tasks as Markdown", len(tasks)))
+			return
+		}
+		path, ok, err := ui.PickMarkdownPath("queue-" + timeNow().Format("2006-01-02") + ".md")
+		if err != nil {
+			ui.Error(title, err.Error())
+			return
+		}
+		if !ok {
+			return
+		}
+		if err := util.AtomicWriteFile(path, []byte(md), 0o644); err != nil {
+			ui.Error(title, err.Error())
+			return
+		}
+		ui.Info(title, i18n.Tf("Saved %d tasks to %s.", len(tasks), path))
+	})
+
+	// ── Export the current task ───────────────────────────────────────────
+
+	exportTask := inDialog(func() {
+		t, ok := q.Peek()
+		if !ok {
+			return
+		}
+		if size := attachmentsSize(t); size > shareWarnSize {
+			// Base64 makes the page a third larger than the files.
+			if !ui.Confirm(i18n.T("Export task"),
+				i18n.Tf("The attachments of this task take %d MB; the HTML file will be about %d MB.\nExport anyway?", size>>20, (size*4/3)>>20), i18n.T("Export")) {
+				return
+			}
+		}
+		path, ok, err := ui.PickTaskExportPath("task-" + timeNow().Format("2006-01-02") + ".html")
+		if err != nil {
+			ui.Error(i18n.T("Export task"), err.Error())
+			return
+		}
+		if !ok {
+			return
+		}
+		page, err := ui.RenderSharedTask(t, q.ReadAttachment)
+		if err != nil {
+			ui.Error(i18n.T("Export task"), err.Error())
+			return
+		}
+		if err := util.AtomicWriteFile(path, []byte(page), 0o644); err != nil {
+			ui.Error(i18n.T("Export task"), err.Error())
+			return
+		}
+		ui.Info(i18n.T("Export task"), i18n.Tf("Saved the task to %s.", path))
+	})
+
+	// ── Reveal the data folder ────────────────────────────────────────────
+
+	// The folder resolved at startup is the one in use, even if Settings
+	// has since chosen another one for the next launch.
+	openDataDir := inDialog(func() {
+		if err := util.OpenFolder(dataDir); err != nil {
+			ui.Error(i18n.T("Open data folder"), i18n.Tf("Could not open %s: %v", dataDir, err))
+		}
+	})
+
+	// ── Attachment cleanup ────────────────────────────────────────────────
+
+	cleanupAttachments := inDialog(func() {
+		if !ui.Confirm(i18n.T("Clean up attachments"),
+			i18n.T("Delete attachment files that no queued task or history entry refers to?"), i18n.T("Delete")) {
+			return
+		}
+		n, err := q.GCAttachments()
+		if err != nil {
+			ui.Error(i18n.T("Clean up attachments"), err.Error())
+			return
+		}
+		ui.Info(i18n.T("Clean up attachments"), i18n.Tf("Removed %d unused files.", n))
+	})
+
+	// ── Find duplicates ───────────────────────────────────────────────────
+
+	findDuplicates := inDialog(func() {
+		title := i18n.T("Find duplicates")
+		groups := queue.DuplicateGroups(q.GetAll())
+		if len(groups) == 0 {
+			ui.Info(title, i18n.T("No duplicate tasks found."))
+			return
+		}
+		var drop []string
+		for i, g := range groups {
+			keep, ok := q.GetByID(g[0])
+			if !ok {
+				continue
+			}
+			msg := i18n.Tf("Group %d of %d: %d tasks have the text\n\n%s\n\nKeep the oldest one, added %s, and delete the other %d?",
+				i+1, len(groups), len(g), taskPreview(keep.Text), keep.CreatedAt.Local().Format("02.01.2006 15:04"), len(g)-1)
+			if ui.ConfirmMerge(msg) {
+				drop = append(drop, g[1:]...)
+			}
+		}
+		if len(drop) == 0 {
+			return
+		}
+		head, hadHead := q.Peek()
+		n, err := q.DeleteMany(drop)
+		if err != nil {
+			ui.Error(title, err.Error())
+			return
+		}
+		if cur, ok := q.Peek(); hadHead && (!ok || cur.ID != head.ID) {
+			timerStop()
+		}
+		refreshAll()
+		ui.Info(title, i18n.Tf("Deleted %d duplicate tasks.", n))
+	})
+
+	// ── Restore from backup ───────────────────────────────────────────────
+
+	restoreBackup := inDialog(func() {
+		title := i18n.T("Restore from backup")
+		backups := q.Backups()
+		if len(backups) == 0 {
+			ui.Info(title, i18n.T("There are no backups yet. Turn them on in Settings → Queue; a copy is kept on every save from then on."))
+			return
+		}
+		labels := make([]string, len(backups))
+		for i, b := range backups {
+			if b.Tasks < 0 {
+				labels[i] = i18n.Tf("%s — unreadable", b.SavedAt.Local().Format("02.01.2006 15:04:05"))
+			} else {
+				labels[i] = i18n.Tf("%s — %d tasks", b.SavedAt.Local().Format("02.01.2006 15:04:05"), b.Tasks)
+			}
+		}
+		i, ok, err := ui.PickBackup(labels)
+		if err != nil {
+			ui.Error(title, err.Error())
+			return
+		}
+		if !ok {
+			return
+		}
+		if !ui.Confirm(title, i18n.Tf("Replace the %d queued tasks with the %s version?\nThe queue as it is now is kept as the newest backup.", q.Count(), labels[i]), i18n.T("Restore")) {
+			return
+		}
+		head, hadHead := q.Peek()
+		n, err := q.RestoreBackup(backups[i].Path)
+		if err != nil {
+			ui.Error(title, err.Error())
+			return
+		}
+		if cur, ok := q.Peek(); hadHead && (!ok || cur.ID != head.ID) {
+			timerStop()
+		}
+		refreshAll()
+		ui.Info(title, i18n.Tf("Restored %d tasks.", n))
+	})
+
+	// ── Archive ───────────────────────────────────────────────────────────
+
+	restoreArchived := inDialog(func() {
+		title := i18n.T("Archive")
+		archived := q.Archive().GetAll()
+		if len(archived) == 0 {
+			ui.Info(title, i18n.T("The archive is empty."))
+			return
+		}
+		ids, err := ui.PickTasks(title, i18n.T("Archived tasks, newest first. Select the ones to put back in the queue:"), i18n.T("Restore"), archived)
+		if err != nil {
+			ui.Error(title, err.Error())
+			return
+		}
+		if len(ids) == 0 {
+			return
+		}
+		n, err := q.RestoreArchived(ids)
+		if n > 0 {
+			refreshAll()
+		}
+		if err != nil {
+			if errors.Is(err, queue.ErrQueueFull) {
+				ui.Error(title, i18n.Tf("The queue is full (%d tasks).\nComplete or delete a task first, or raise the limit in Settings.", q.Count()))
+				return
+			}
+			ui.Error(title, err.Error())
+			return
+		}
+		ui.Info(title, i18n.Tf("Restored %d tasks.", n))
+	})
+
+	// ── Empty queue ───────────────────────────────────────────────────────
+
+	clearQueue := inDialog(func() {
+		n := q.Count()
+		if n == 0 {
+			return
+		}
+		if !ui.Confirm(i18n.T("Empty queue"),
+			i18n.Tf("Remove all %d tasks from the queue?\nA backup is saved first; import it to get the tasks back.", n), i18n.T("Empty")) {
+			return
+		}
+		var backup string
+		err := q.Exclusive(func() error {
+			var err error
+			backup, err = q.Clear()
+			return err
+		})
+		refreshAll()
+		if err != nil {
+			ui.Error(i18n.T("Empty queue"), err.Error())
+			return
+		}
+		ui.Info(i18n.T("Empty queue"), i18n.Tf("The queue is empty. The tasks were saved to:\n%s\n\nUse Import… with this file to restore them.", backup))
+	})
+
+	// ── Search ────────────────────────────────────────────────────────────
+
+	search := inDialog(func() {
+		query, ok, err := ui.SearchQuery()
+		if err != nil {
+			ui.Error(i18n.T("Search"), err.Error())
+			return
+		}
+		if !ok {
+			return
+		}
+		if len(q.Search(query)) == 0 {
+			ui.Info(i18n.T("Search"), i18n.Tf("No tasks match \"%s\".", query))
+			return
+		}
+		_ = openURL("/search?q=" + url.QueryEscape(query))
+	})
+
+	// ── Edit current task ─────────────────────────────────────────────────
+
+	editTask := inDialog(func() {
+		task, ok := q.Peek()
+		if !ok {
+			return
+		}
+		// The native entry is single-line; multi-line Markdown goes to the web editor.
+		if strings.Contains(task.Text, "\n") {
+			_ = openURL("/")
+			return
+		}
+		text, ok, err := ui.EditText(task.Text)
+		if err != nil {
+			ui.Error(i18n.T("Edit task"), err.Error())
+			return
+		}
+		if !ok {
+			return
+		}
+		if err := q.UpdateText(task.ID, text); err != nil {
+			ui.Error(i18n.T("Edit task"), err.Error())
+			return
+		}
+		if len(task.Attachments) > 0 && ui.Confirm(i18n.T("Edit task"), i18n.T("Remove all attachments from this task?"), i18n.T("Remove")) {
+			if err := q.ClearAttachments(task.ID); err != nil {
+				ui.Error(i18n.T("Edit task"), err.Error())
+			}
+		}
+		refreshAll()
+	})
+
+	// ── Move to front ─────────────────────────────────────────────────────
+
+	promoteTask := inDialog(func() {
+		id, ok, err := ui.PickTask(i18n.T("Move to front"), i18n.T("Select the task to work on next:"), q.GetAll())
+		if err != nil {
+			ui.Error(i18n.T("Move to front"), err.Error())
+			return
+		}
+		if !ok {
+			return
+		}
+		if err := q.Promote(id); err != nil {
+			ui.Error(i18n.T("Move to front"), err.Error())
+			return
+		}
+		refreshAll()
+	})
+
+	// ── Pin ───────────────────────────────────────────────────────────────
+
+	// pinTask unpins the pinned task, or asks which task to pin when there
+	// is none.
+	pinTask := inDialog(func() {
+		if _, ok := q.Pinned(); ok {
+			if err := q.Unpin(); err != nil {
+				ui.Error(i18n.T("Pin task"), err.Error())
+				return
+			}
+			refreshAll()
+			return
+		}
+		id, ok, err := ui.PickTask(i18n.T("Pin task"), i18n.T("Select the task to keep current:"), q.GetAll())
+		if err != nil {
+			ui.Error(i18n.T("Pin task"), err.Error())
+			return
+		}
+		if !ok {
+			return
+		}
+		if err := q.Pin(id); err != nil {
+			ui.Error(i18n.T("Pin task"), err.Error())
+			return
+		}
+		refreshAll()
+	})
+
+	// ── Snooze ────────────────────────────────────────────────────────────
+
+	snoozeTask := inDialog(func() {
+		head, ok := q.Peek()
+		if !ok {
+			return
+		}
+		until, ok, err := ui.PickSnooze(timeNow())
+		if err != nil {
+			ui.Error(i18n.T("Snooze"), err.Error())
+			return
+		}
+		if !ok {
+			return
+		}
+		if err := q.Snooze(head.ID, until); err != nil {
+			ui.Error(i18n.T("Snooze"), err.Error())
+			return
+		}
+		timerStop()
+		refreshAll()
+	})
+
+	// ── Skip ──────────────────────────────────────────────────────────────
+
+	skipCurrent := func() {
+		if err := q.Skip(); err != nil {
+			log.Printf("[app] skip: %v", err)
+		}
+		refreshAll()
+	}
+
+	// ── Copy current task text ────────────────────────────────────────────
+
+	copyCurrent := inDialog(func() {
+		t, ok := q.Peek()
+		if !ok {
+			return
+		}
+		if err := util.CopyText(t.Text); err != nil {
+			ui.Error(i18n.T("Copy text"), err.Error())
+			return
+		}
+		notify(i18n.T("Copied to clipboard"), taskPreview(t.Text))
+	})
+
+	// ── Open attachment ───────────────────────────────────────────────────
+
+	openAttachment := inDialog(func() {
+		t, ok := q.Peek()
+		if !ok || len(t.Attachments) == 0 {
+			return
+		}
+		idx := 0
+		if len(t.Attachments) > 1 {
+			names := make([]string, len(t.Attachments))
+			for i, a := range t.Attachments {
+				names[i] = filepath.Base(a.Path)
+			}
+			i, ok, err := ui.PickAttachment(names)
+			if err != nil {
+				ui.Error(i18n.T("Open attachment"), err.Error())
+				return
+			}
+			if !ok {
+				return
+			}
+			idx = i
+		}
+		path, err := q.OpenablePath(t.Attachments[idx])
+		if err != nil {
+			ui.Error(i18n.T("Open attachment"), err.Error())
+			return
+		}
+		if err := util.OpenWithSystem(path); err != nil {
+			ui.Error(i18n.T("Open attachment"), err.Error())
+		}
+	})
+
+	// ── Duplicate task ────────────────────────────────────────────────────
+
+	duplicateTask := inDialog(func() {
+		id, ok, err := ui.PickTask(i18n.T("Duplicate task"), i18n.T("Select the task to copy:"), q.GetAll())
+		if err != nil {
+			ui.Error(i18n.T("Duplicate task"), err.Error())
+			return
+		}
+		if !ok {
+			return
+		}
+		if _, err := q.Duplicate(id); err != nil {
+			ui.Error(i18n.T("Duplicate task"), err.Error())
+			return
+		}
+		refreshAll()
+	})
+
+	// ── Templates ─────────────────────────────────────────────────────────
+
+	saveTemplate := inDialog(func() {
+		title := i18n.T("Save as template")
+		t, ok := q.Peek()
+		if !ok {
+			return
+		}
+		tmpl := queue.TemplateFromTask("", t)
+		name, ok, err := ui.TemplateName(tmpl.Name)
+		if err != nil {
+			ui.Error(title, err.Error())
+			return
+		}
+		if !ok {
+			return
+		}
+		tmpl.Name = name
+		replaced, err := q.Templates().Save(tmpl)
+		if err != nil {
+			ui.Error(title, err.Error())
+			return
+		}
+		if replaced {
+			ui.Info(title, i18n.Tf("Template \"%s\" updated.", name))
+		} else {
+			ui.Info(title, i18n.Tf("Template \"%s\" saved.", name))
+		}
+	})
+
+	addFromTemplate := inDialog(func() {
+		title := i18n.T("Add from template")
+		tmpls := q.Templates().GetAll()
+		if len(tmpls) == 0 {
+			ui.Info(title, i18n.T("There are no templates yet. Use Save as template… on the current task to make one."))
+			return
+		}
+		labels := make([]string, len(tmpls), len(tmpls)+1)
+		for i, tp := range tmpls {
+			labels[i] = tp.Name
+			if len(tp.Tags) > 0 {
+				labels[i] += "  #" + strings.Join(tp.Tags, " #")
+			}
+		}
+		i, ok, err := ui.PickTemplate(title, i18n.T("Select the template to add:"), i18n.T("Add"), append(labels, i18n.T("Delete a template…")))
+		if err != nil {
+			ui.Error(title, err.Error())
+			return
+		}
+		if !ok {
+			return
+		}
+		if i < len(tmpls) {
+			enqueueNew(tmpls[i].NewTask(timeNow()))
+			return
+		}
+
+		title = i18n.T("Delete template")
+		i, ok, err = ui.PickTemplate(title, i18n.T("Select the template to delete:"), i18n.T("Delete"), labels)
+		if err != nil {
+			ui.Error(title, err.Error())
+			return
+		}
+		if !ok || !ui.Confirm(title, i18n.Tf("Delete the template \"%s\"?", tmpls[i].Name), i18n.T("Delete")) {
+			return
+		}
+		if err := q.Templates().Delete(tmpls[i].Name); err != nil {
+			ui.Error(title, err.Error())
+		}
+	})
+
+	// ── Delete specific task ──────────────────────────────────────────────
+
+	deleteTask := inDialog(func() {
+		id, ok, err := ui.PickTask(i18n.T("Delete task"), i18n.T("Select the task to delete:"), q.GetAll())
+		if err != nil {
+			ui.Error(i18n.T("Delete task"), err.Error())
+			return
+		}
+		if !ok {
+			return
+		}
+		t, found := q.GetByID(id)
+		if !found || !confirmRemove(i18n.T("Delete task"), i18n.T("Delete this task?"), i18n.T("Delete"), t) {
+			return
+		}
+		head, hadHead := q.Peek()
+		if _, err := q.DeleteByID(id); err != nil {
+			ui.Error(i18n.T("Delete task"), err.Error())
+			return
+		}
+		if hadHead && head.ID == id {
+			timerStop()
+		}
+		refreshAll()
+	})
+
+	bulkActions := inDialog(func() {
+		title := i18n.T("Bulk actions")
+		ids, err := ui.PickTasks(title, i18n.T("Select the tasks:"), i18n.T("Next"), q.GetAll())
+		if err != nil {
+			ui.Error(title, err.Error())
+			return
+		}
+		if len(ids) == 0 {
+			return
+		}
+		action, ok, err := ui.PickBulkAction(len(ids))
+		if err != nil {
+			ui.Error(title, err.Error())
+			return
+		}
+		if !ok {
+			return
+		}
+		head, hadHead := q.Peek()
+		var n int
+		var msg string
+		switch action {
+		case ui.BulkComplete:
+			if confirmRemoval.Load() && !ui.Confirm(title, i18n.Tf("Complete %d tasks? This cannot be undone.", len(ids)), i18n.T("Complete")) {
+				return
+			}
+			n, err = q.CompleteMany(ids)
+			msg = i18n.Tf("Completed %d tasks.", n)
+		case ui.BulkSkip:
+			n, err = q.SkipMany(ids)
+			msg = i18n.Tf("Moved %d tasks to the end of the queue.", n)
+			if n < len(ids) {
+				msg += "\n" + i18n.T("Pinned tasks stay in place.")
+			}
+		case ui.BulkDelete:
+			if confirmRemoval.Load() && !ui.Confirm(title, i18n.Tf("Delete %d tasks? This cannot be undone.", len(ids)), i18n.T("Delete")) {
+				return
+			}
+			n, err = q.DeleteMany(ids)
+			msg = i18n.Tf("Deleted %d tasks.", n)
+		}
+		if err != nil {
+			ui.Error(title, err.Error())
+			return
+		}
+		if cur, ok := q.Peek(); hadHead && (!ok || cur.ID != head.ID) {
+			timerStop()
+		}
+		refreshAll()
+		ui.Info(title, msg)
+	})
+
+	// ── Complete current task ─────────────────────────────────────────────
+
+	completeCurrent := inDialog(func() {
+		head, ok := q.Peek()
+		if !ok || !confirmRemove(i18n.T("Complete task"), i18n.T("Complete this task?"), i18n.T("Complete"), head) {
+			return
+		}
+		if _, err := q.Complete(); err != nil {
+			ui.Error(i18n.T("Complete task"), err.Error())
+		}
+		timerStop()
+		refreshAll()
+	})
+
+	completeWithNote := inDialog(func() {
+		head, ok := q.Peek()
+		if !ok {
+			return
+		}
+		// The note prompt doubles as the confirmation.
+		note, ok, err := ui.CompletionNote(taskPreview(head.Text))
+		if err != nil {
+			ui.Error(i18n.T("Complete task"), err.Error())
+			return
+		}
+		if !ok {
+			return
+		}
+		if _, err := q.CompleteWithNote(note); err != nil {
+			ui.Error(i18n.T("Complete task"), err.Error())
+		}
+		timerStop()
+		refreshAll()
+	})
+
+	// ── Hotkeys ───────────────────────────────────────────────────────────
+
+	actions := map[string]func(){
+		hotkeys.ActionShowFirst:        showCurrent,
+		hotkeys.ActionAddQuick:         quickAdd,
+		hotkeys.ActionManageQueue:      func() { _ = openURL("/") },
+		hotkeys.ActionAddFromClipboard: func() { _ = openURL("/add") },
+		hotkeys.ActionSkip:             skipCurrent,
+		hotkeys.ActionComplete:         completeCurrent,
+		hotkeys.ActionBrowseNext:       func() { browseStep(1) },
+		hotkeys.ActionBrowsePrev:       func() { browseStep(-1) },
+	}
+
+	type menuItem struct {
+		item   *systray.MenuItem
+		base   string
+		action string
+	}
+	hotkeyMenuItems := []menuItem{}
+	if mAddQuick != nil {
+		hotkeyMenuItems = append(hotkeyMenuItems, menuItem{mAddQuick, i18n.T("Quick add"), hotkeys.ActionAddQuick})
+	}
+	if mAddAdvanced != nil {
+		hotkeyMenuItems = append(hotkeyMenuItems, menuItem{mAddAdvanced, i18n.T("Open advanced editor in browser"), hotkeys.ActionAddFromClipboard})
+	}
+	if mSkip != nil {
+		hotkeyMenuItems = append(hotkeyMenuItems, menuItem{mSkip, i18n.T("Move current task to the end"), hotkeys.ActionSkip})
+	}
+	if mDone != nil {
+		hotkeyMenuItems = append(hotkeyMenuItems, menuItem{mDone, i18n.T("Complete current task"), hotkeys.ActionComplete})
+	}
+	if mQueue != nil {
+		hotkeyMenuItems = append(hotkeyMenuItems, menuItem{mQueue, i18n.T("View and manage all tasks"), hotkeys.ActionManageQueue})
+	}
+	if mBrowseNext != nil {
+		hotkeyMenuItems = append(hotkeyMenuItems, menuItem{mBrowseNext, i18n.T("Show the next task in line without changing the order"), hotkeys.ActionBrowseNext})
+		hotkeyMenuItems = append(hotkeyMenuItems, menuItem{mBrowsePrev, i18n.T("Show the previous task in line without changing the order"), hotkeys.ActionBrowsePrev})
+	}
+
+	applyTooltips := func(c hotkeys.KeyConfig) {
+		for _, m := range hotkeyMenuItems {
+			tooltip := m.base
+			if hc, ok := c.Hotkeys[m.action]; ok && hc.Enabled && hc.Combo != "" {
+				tooltip += "  " + hotkeys.FormatCombo(hc.Combo)
+			}
+			m.item.SetTooltip(tooltip)
+		}
+	}
+
+	if cfgErr != nil {
+		ui.Error(i18n.T("Hotkeys"), cfgErr.Error())
+	} else {
+		applyTooltips(cfg)
+		hkRegs, err = hotkeys.Register(cfg, actions)
+		if err != nil {
+			ui.Error(i18n.T("Hotkeys"), err.Error()+"\nConfig: "+cfgPath)
+		}
+	}
+
 	mgr.SetReloadFn(func() error {
 		hotkeys.Unregister(hkRegs)
+		hkRegs = nil
 		newCfg, _, err := hotkeys.LoadOrCreate(dataDir)
 		if err != nil {
 			return err
 		}
-		newRegs, err := hotkeys.Register(newCfg, actions)
-		if err != nil {
-			return err
-		}
+		// Keep whatever registered; apply the rest of the settings regardless.
+		newRegs, regErr := hotkeys.Register(newCfg, actions)
 		hkRegs = newRegs
 		applyTooltips(newCfg)
 		applyVisibility(newCfg.EffectiveTrayGroups())
 		timerMu.Lock()
 		timerDuration = newCfg.TimerDuration()
 		timerMu.Unlock()
-		return nil
+		maxAttachmentSize.Store(newCfg.MaxAttachmentSize())
+		applyAttachmentTypes(newCfg)
+		confirmRemoval.Store(newCfg.IsConfirmRemovalEnabled())
+		doneIcon.Store(newCfg.DoneIcon)
+		nativeTaskView.Store(newCfg.NativeTaskView)
+		autoArchiveDays.Store(int64(newCfg.AutoArchiveDays()))
+		doneSound.Store(newCfg.DoneSound)
+		doneSoundFile.Store(&newCfg.DoneSoundFile)
+		ui.SetDialogTimeout(newCfg.DialogTimeout())
+		q.SetMaxLen(newCfg.QueueLimit())
+		q.SetBackupCount(newCfg.BackupCount())
+		q.SetDueFirst(newCfg.SortByDue)
+		completionWebhook.Store(webhook.New(newCfg.WebhookURL, newCfg.WebhookSecret))
+		return regErr
 	})
 
 	// ── Background update check ───────────────────────────────────────────
 
-	go func() {
-		time.Sleep(15 * time.Second) // don't check immediately on startup
+	goBackground(func(ctx context.Context) {
+		select {
+		case <-time.After(15 * time.Second): // don't check immediately on startup
+		case <-ctx.Done():
+			return
+		}
 		doCheck := func() {
 			info, err := updater.Check()
 			mgr.SetUpdateInfo(info, err)
 			if info != nil {
-				sendNotification("Queue — Update available", fmt.Sprintf(
+				notify(i18n.T("Queue — Update available"), i18n.Tf(
 					"Version %s is available. Open Settings to install.", info.Version))
 			}
 		}
 		doCheck()
-		for range time.Tick(24 * time.Hour) {
-			doCheck()
-		}
-	}()
+		every(ctx, 24*time.Hour, doCheck)
+	})
 
-	// ── Ticker ────────────────────────────────────────────────────────────
+	// ── Due date and task reminders ───────────────────────────────────────
 
-	stopTicker := make(chan struct{})
-	go func() {
-		ticker := time.NewTicker(time.Second)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ticker.C:
-				var expired bool
-				timerMu.Lock()
-				if timerActive && !timerPaused && time.Now().After(timerEnd) {
-					timerActive = false
-					expired = true
-				}
-				timerMu.Unlock()
-				if expired {
-					sendNotification("Queue Timer", "Время вышло! Сделай перерыв.")
+	goBackground(func(ctx context.Context) {
+		notified := map[string]bool{}
+		check := func() {
+			now := timeNow()
+			for _, t := range q.GetAll() {
+				if !t.IsOverdue(now) || notified[t.ID] {
+					continue
 				}
-				refreshAll()
-			case <-stopTicker:
+				notified[t.ID] = true
+				notify(i18n.T("Queue — Task overdue"), taskPreview(t.Text))
+			}
+			fired, err := q.FireReminders(now)
+			if err != nil {
+				log.Printf("[app] reminders: %v", err)
+			}
+			for _, t := range fired {
+				notify(i18n.T("Queue — Reminder"), taskPreview(t.Text))
+			}
+		}
+		check()
+		every(ctx, time.Minute, check)
+	})
+
+	// ── Last opened task ──────────────────────────────────────────────────
+
+	if cfg.RestoreLast {
+		goBackground(func(ctx context.Context) { restoreLastTask() })
+	}
+
+	// ── Auto-archive ──────────────────────────────────────────────────────
+
+	goBackground(func(ctx context.Context) {
+		check := func() {
+			days := int(autoArchiveDays.Load())
+			moved, err := q.ArchiveOlderThan(days, timeNow())
+			if err != nil {
+				log.Printf("[app] auto-archive: %v", err)
+				return
+			}
+			if len(moved) == 0 {
 				return
 			}
+			refreshAll()
+			notify(i18n.T("Queue — Tasks archived"), i18n.Tf("%d tasks older than %d days were moved to the archive. Restore them with Archive….", len(moved), days))
 		}
+		check()
+		every(ctx, time.Hour, check)
+	})
+
+	// ── External changes (CLI, manual edits of queue.json) ────────────────
+
+	goBackground(func(ctx context.Context) {
+		every(ctx, 2*time.Second, func() {
+			changed, err := q.ReloadIfChanged()
+			if err != nil {
+				log.Printf("queue reload: %v", err)
+				return
+			}
+			if changed {
+				refreshAll()
+			}
+		})
+	})
+
+	// ── Ticker ────────────────────────────────────────────────────────────
+
+	goBackground(func(ctx context.Context) {
+		every(ctx, time.Second, func() {
+			var expired bool
+			timerMu.Lock()
+			if timerActive && !timerPaused && time.Now().After(timerEnd) {
+				timerActive = false
+				expired = true
+			}
+			timerMu.Unlock()
+			if expired {
+				notify(i18n.T("Queue Timer"), i18n.T("Time is up! Take a break."))
+			}
+			refreshAll()
+		})
+	})
+
+	// A cancelled context (SIGINT, SIGTERM) quits like the menu item does.
+	go func() {
+		<-appCtx.Done()
+		systray.Quit()
 	}()
 
 	// ── Menu event loop ───────────────────────────────────────────────────
@@ -481,13 +2113,45 @@ func onReady() {
 				}
 			}
 
-			add(mTaskTitle, func() { _ = openURL("/") })
+			add(mTaskTitle, showCurrent)
 			add(mTimer, func() { timerToggle(); refreshAll() })
-			add(mSkip, func() { _ = q.Skip(); refreshAll() })
-			add(mDone, func() { _, _ = q.Complete(); timerStop(); refreshAll() })
+			add(mBrowseNext, func() { browseStep(1) })
+			add(mBrowsePrev, func() { browseStep(-1) })
+			add(mSkip, skipCurrent)
+			add(mDone, completeCurrent)
+			add(mDoneNote, completeWithNote)
+			add(mUndo, undo)
+			add(mEdit, editTask)
+			add(mCopy, copyCurrent)
+			add(mOpenAttach, openAttachment)
+			add(mPromote, promoteTask)
+			add(mPin, pinTask)
+			add(mSnooze, snoozeTask)
+			add(mDuplicate, duplicateTask)
+			add(mSaveTmpl, saveTemplate)
+			add(mDelete, deleteTask)
+			add(mBulk, bulkActions)
 			add(mAddQuick, quickAdd)
+			add(mAddText, addTextOnly)
+			add(mFromTmpl, addFromTemplate)
 			add(mAddAdvanced, func() { _ = openURL("/add") })
+			add(mFocus, func() { _ = openURL("/focus") })
 			add(mQueue, func() { _ = openURL("/") })
+			add(mList, func() { _ = openURL("/list") })
+			add(mFilter, filterByTag)
+			add(mSearch, search)
+			add(mHistory, func() { _ = openURL("/history") })
+			add(mStats, func() { _ = openURL("/stats") })
+			add(mImport, importTasks)
+			add(mExport, exportQueue)
+			add(mExportTask, exportTask)
+			add(mCopyMD, copyMarkdown)
+			add(mDataDir, openDataDir)
+			add(mCleanup, cleanupAttachments)
+			add(mDedup, findDuplicates)
+			add(mRestore, restoreBackup)
+			add(mArchive, restoreArchived)
+			add(mClear, clearQueue)
 			add(mSettings, func() { _ = openURL("/settings") })
 
 			// select requires static cases — fall back to individual goroutines
@@ -509,46 +2173,423 @@ func onReady() {
 		for {
 			select {
 			case <-ch(mTaskTitle):
-				_ = openURL("/")
+				showCurrent()
 			case <-ch(mTimer):
 				timerToggle()
 				refreshAll()
+			case <-ch(mBrowseNext):
+				browseStep(1)
+			case <-ch(mBrowsePrev):
+				browseStep(-1)
 			case <-ch(mSkip):
-				_ = q.Skip()
-				refreshAll()
+				skipCurrent()
 			case <-ch(mDone):
-				_, _ = q.Complete()
-				timerStop()
-				refreshAll()
+				completeCurrent()
+			case <-ch(mDoneNote):
+				completeWithNote()
+			case <-ch(mUndo):
+				undo()
+			case <-ch(mEdit):
+				editTask()
+			case <-ch(mCopy):
+				copyCurrent()
+			case <-ch(mOpenAttach):
+				openAttachment()
+			case <-ch(mPromote):
+				promoteTask()
+			case <-ch(mPin):
+				pinTask()
+			case <-ch(mSnooze):
+				snoozeTask()
+			case <-ch(mDuplicate):
+				duplicateTask()
+			case <-ch(mSaveTmpl):
+				saveTemplate()
+			case <-ch(mDelete):
+				deleteTask()
+			case <-ch(mBulk):
+				bulkActions()
 			case <-ch(mAddQuick):
 				quickAdd()
+			case <-ch(mAddText):
+				addTextOnly()
+			case <-ch(mFromTmpl):
+				addFromTemplate()
 			case <-ch(mAddAdvanced):
 				_ = openURL("/add")
+			case <-ch(mFocus):
+				_ = openURL("/focus")
 			case <-ch(mQueue):
 				_ = openURL("/")
+			case <-ch(mList):
+				_ = openURL("/list")
+			case <-ch(mFilter):
+				filterByTag()
+			case <-ch(mSearch):
+				search()
+			case <-ch(mHistory):
+				_ = openURL("/history")
+			case <-ch(mStats):
+				_ = openURL("/stats")
+			case <-ch(mImport):
+				importTasks()
+			case <-ch(mExport):
+				exportQueue()
+			case <-ch(mExportTask):
+				exportTask()
+			case <-ch(mCopyMD):
+				copyMarkdown()
+			case <-ch(mDataDir):
+				openDataDir()
+			case <-ch(mCleanup):
+				cleanupAttachments()
+			case <-ch(mDedup):
+				findDuplicates()
+			case <-ch(mRestore):
+				restoreBackup()
+			case <-ch(mArchive):
+				restoreArchived()
+			case <-ch(mClear):
+				clearQueue()
 			case <-ch(mSettings):
 				_ = openURL("/settings")
 			case <-ch(mQuit):
-				close(stopTicker)
 				systray.Quit()
 				return
+			case <-appCtx.Done():
+				return
 			}
 		}
 	}()
 }
 
+// onExit stops everything started by onReady: background goroutines and the
+// HTTP servers get shutdownTimeout to finish, then the queue is flushed and
+// closed.
 func onExit() {
+	appCancel()
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if mgr != nil {
+		if err := mgr.Shutdown(ctx); err != nil {
+			log.Printf("[app] manage server shutdown: %v", err)
+		}
+	}
+	if apiSrv != nil {
+		if err := apiSrv.Shutdown(ctx); err != nil {
+			log.Printf("[app] api shutdown: %v", err)
+		}
+	}
+	done := make(chan struct{})
+	go func() {
+		bg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Printf("[app] background work still running after %s; exiting anyway", shutdownTimeout)
+	}
+
 	hotkeys.Unregister(hkRegs)
+	if q != nil {
+		if err := q.Flush(); err != nil {
+			log.Printf("[app] final save: %v", err)
+		}
+		q.DiscardUndo()
+		if err := q.RemoveOpenedCopies(); err != nil {
+			log.Printf("[app] remove decrypted copies: %v", err)
+		}
+		if err := q.Close(); err != nil {
+			log.Printf("[app] close queue: %v", err)
+		}
+	}
+	if instance != nil {
+		_ = instance.Release()
+	}
+	if logFile != nil {
+		log.Printf("[app] exited")
+		log.SetOutput(os.Stderr)
+		_ = logFile.Close()
+	}
+}
+
+// importAttachments validates the given files and copies them into the
+// attachments directory. Nothing is copied unless every file passes; on a
+// copy error, files copied so far are removed again.
+func importAttachments(srcs []string) ([]queue.Attachment, error) {
+	types := make([]queue.AttachmentType, len(srcs))
+	for i, src := range srcs {
+		at, err := validateAttachment(src)
+		if err != nil {
+			return nil, err
+		}
+		types[i] = at
+	}
+	var out []queue.Attachment
+	for i, src := range srcs {
+		at := types[i]
+		dst := filepath.Join(q.AttachmentsDir(), queue.StagedName(src))
+		if err := util.CopyFile(src, dst); err != nil {
+			removeImported(out)
+			return nil, fmt.Errorf("copy attachment: %w", err)
+		}
+		out = append(out, queue.Attachment{Path: dst, Type: at})
+	}
+	return out, nil
+}
+
+// applyAttachmentTypes allows the extra attachment types from cfg. Entries
+// that cannot be allowed are logged and left out.
+func applyAttachmentTypes(cfg hotkeys.KeyConfig) {
+	exts, err := queue.ParseAttachmentExts(cfg.AttachTypes)
+	if err != nil {
+		log.Printf("[app] attachment_types: %v", err)
+	}
+	queue.SetExtraAttachmentExts(exts)
+}
+
+// validateAttachment checks the file type by extension (the picker filters are
+// only a hint) and rejects files larger than maxAttachmentSize.
+func validateAttachment(src string) (queue.AttachmentType, error) {
+	name := filepath.Base(src)
+	at, ok := queue.AttachmentTypeForExt(strings.ToLower(filepath.Ext(src)))
+	if !ok {
+		return at, fmt.Errorf("%s: unsupported attachment type", name)
+	}
+	fi, err := os.Stat(src)
+	if err != nil {
+		return at, err
+	}
+	if !fi.Mode().IsRegular() {
+		return at, fmt.Errorf("%s: not a regular file", name)
+	}
+	if limit := maxAttachmentSize.Load(); limit > 0 && fi.Size() > limit {
+		return at, fmt.Errorf("%s is %d MB, the limit is %d MB", name, fi.Size()>>20, limit>>20)
+	}
+	return at, nil
+}
+
+// shareWarnSize is the total attachment size above which exporting a task as
+// HTML asks first, since the files are inlined into the page.
+const shareWarnSize = 10 << 20
+
+// attachmentsSize returns the total size of t's attachment files. Missing
+// files count as empty.
+func attachmentsSize(t queue.Task) int64 {
+	var n int64
+	for _, a := range t.Attachments {
+		if fi, err := os.Stat(a.Path); err == nil {
+			n += fi.Size()
+		}
+	}
+	return n
+}
+
+// exportBundle writes the queue bundle to path, removing a partial file on error.
+func exportBundle(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := q.ExportBundle(f); err != nil {
+		f.Close()
+		_ = os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
+
+// pasteClipboardImage stores the clipboard image as a PNG attachment.
+func pasteClipboardImage() (queue.Attachment, error) {
+	dst := filepath.Join(q.AttachmentsDir(), fmt.Sprintf("%d.png", timeNowNano()))
+	if err := util.SaveClipboardImage(dst); err != nil {
+		return queue.Attachment{}, err
+	}
+	return queue.Attachment{Path: dst, Type: queue.AttachmentImage}, nil
+}
+
+// downloadTimeout bounds fetching an attachment from a URL, body included.
+const downloadTimeout = 60 * time.Second
+
+// downloadAttachment fetches rawURL into attachmentsDir. The type comes from
+// the Content-Type header, or from the URL's extension when the server does
+// not name a specific type or the extension is one allowed in the settings;
+// anything else is rejected, and so is a body larger than maxAttachmentSize,
+// which is checked while downloading rather than trusting Content-Length.
+func downloadAttachment(rawURL string) (queue.Attachment, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return queue.Attachment{}, fmt.Errorf("%q is not an http or https URL", rawURL)
+	}
+	ctx, cancel := context.WithTimeout(appCtx, downloadTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return queue.Attachment{}, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return queue.Attachment{}, fmt.Errorf("could not download %s:\n%v", u.Host, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return queue.Attachment{}, fmt.Errorf("could not download %s: the server answered %s", u.Host, resp.Status)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	ext, ok := queue.AttachmentExtForMIME(mediaType)
+	if !ok {
+		urlExt := strings.ToLower(path.Ext(u.Path))
+		t, known := queue.AttachmentTypeForExt(urlExt)
+		if known && (mediaType == "" || mediaType == "application/octet-stream" || t == queue.AttachmentFile) {
+			ext, ok = urlExt, true
+		}
+	}
+	if !ok {
+		if mediaType == "" {
+			mediaType = "unknown type"
+		}
+		return queue.Attachment{}, fmt.Errorf("%s is not a supported attachment type (%s)", path.Base(u.Path), mediaType)
+	}
+	at, _ := queue.AttachmentTypeForExt(ext)
+
+	limit := maxAttachmentSize.Load()
+	tooLarge := fmt.Errorf("the file is larger than the %d MB attachment limit", limit>>20)
+	if limit > 0 && resp.ContentLength > limit {
+		return queue.Attachment{}, tooLarge
+	}
+	body := io.Reader(resp.Body)
+	if limit > 0 {
+		body = io.LimitReader(resp.Body, limit+1)
+	}
+	dst := filepath.Join(q.AttachmentsDir(), fmt.Sprintf("%d%s", timeNowNano(), ext))
+	f, err := os.Create(dst)
+	if err != nil {
+		return queue.Attachment{}, err
+	}
+	n, err := io.Copy(f, body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && limit > 0 && n > limit {
+		err = tooLarge
+	}
+	if err != nil {
+		_ = os.Remove(dst)
+		return queue.Attachment{}, err
+	}
+	return queue.Attachment{Path: dst, Type: at}, nil
+}
+
+// captureScreenshot lets the user take a screenshot with the platform's
+// tool and imports it as an image attachment, with the checks of any other
+// file. The capture goes to a temporary file that is removed afterwards.
+func captureScreenshot() (queue.Attachment, error) {
+	tmp := filepath.Join(os.TempDir(), fmt.Sprintf("systray-queue-screenshot-%d.png", timeNowNano()))
+	defer os.Remove(tmp)
+	if err := util.CaptureScreen(tmp); err != nil {
+		return queue.Attachment{}, err
+	}
+	as, err := importAttachments([]string{tmp})
+	if err != nil {
+		return queue.Attachment{}, err
+	}
+	return as[0], nil
+}
+
+// audioRecordLimit caps a voice memo recorded from the tray.
+const audioRecordLimit = 60 * time.Second
+
+// recordAudio records a voice memo from the default microphone into a WAV
+// attachment, until the user presses Stop or the limit is reached.
+func recordAudio() (queue.Attachment, error) {
+	dst := filepath.Join(q.AttachmentsDir(), fmt.Sprintf("%d.wav", timeNowNano()))
+	rec, err := util.StartRecording(dst, audioRecordLimit)
+	if err != nil {
+		return queue.Attachment{}, err
+	}
+	stopped, err := ui.RecordingDialog(audioRecordLimit, rec.Done())
+	if err != nil {
+		_ = rec.Stop()
+		_ = os.Remove(dst)
+		return queue.Attachment{}, err
+	}
+	if stopped {
+		err = rec.Stop()
+	} else {
+		err = rec.Err()
+	}
+	if err != nil {
+		_ = os.Remove(dst)
+		return queue.Attachment{}, fmt.Errorf("recording failed, is a microphone connected?\n%v", err)
+	}
+	if err := util.ValidateWAV(dst); err != nil {
+		_ = os.Remove(dst)
+		return queue.Attachment{}, fmt.Errorf("nothing was recorded, is a microphone connected?\n%v", err)
+	}
+	return queue.Attachment{Path: dst, Type: queue.AttachmentAudio}, nil
+}
+
+func removeImported(as []queue.Attachment) {
+	for _, a := range as {
+		_ = os.Remove(a.Path)
+	}
 }
 
 func timeNow() time.Time { return time.Now() }
 func timeNowNano() int64 { return time.Now().UnixNano() }
 
+// rememberLastTask records id in settings.json as the task last opened from
+// the tray, for restoreLastTask.
+func rememberLastTask(id string) {
+	s, err := util.LoadSettings()
+	if err == nil && s.LastTaskID == id {
+		return
+	}
+	if err == nil {
+		s.LastTaskID = id
+		err = util.SaveSettings(s)
+	}
+	if err != nil {
+		log.Printf("[app] remember last task: %v", err)
+	}
+}
+
+// openBrowserQuiet is openURL without the error dialog, for callers that
+// have another way to show the page's content. A panic on the way, such as
+// from a manage server that failed to start, is reported as an error.
+func openBrowserQuiet(path string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("open browser: %v", r)
+			log.Printf("[app] %v", err)
+		}
+	}()
+	if mgr == nil {
+		return errors.New("manage UI is not running")
+	}
+	base, err := mgr.URL()
+	if err != nil {
+		log.Printf("[app] manage UI: %v", err)
+		return err
+	}
+	if err := manage.OpenBrowser(strings.TrimRight(base, "/") + path); err != nil {
+		log.Printf("[app] open browser: %v", err)
+		return err
+	}
+	return nil
+}
+
 func openURL(path string) error {
 	base, err := mgr.URL()
 	if err != nil {
-		ui.Error("Manage UI", err.Error())
+		ui.Error(i18n.T("Manage UI"), err.Error())
+		return err
+	}
+	if err := manage.OpenBrowser(strings.TrimRight(base, "/") + path); err != nil {
+		log.Printf("[app] open browser: %v", err)
 		return err
 	}
-	return manage.OpenBrowser(strings.TrimRight(base, "/") + path)
+	return nil
 }
@@ -0,0 +1,255 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ameight/systray-queue-app/internal/hotkeys"
+	"github.com/Ameight/systray-queue-app/internal/queue"
+	"github.com/Ameight/systray-queue-app/internal/util"
+	"github.com/Ameight/systray-queue-app/internal/webhook"
+)
+
+// webhookTimeout bounds how long a command waits for the completion webhook,
+// retries included.
+const webhookTimeout = time.Minute
+
+const usage = `usage: systray-queue-app <command> [args]
+
+commands:
+  add <text>   append a task to the end of the queue
+  list         print the queue, current task first
+  complete     complete the current task
+  skip         move the current task to the end of the queue
+  replay       print the queue rebuilt from events.jsonl;
+               with --apply, replace the queue with it
+  validate [path]
+               check a queue file (queue.json by default) without
+               changing it; exits with 1 if there are problems
+  help         show this message
+
+Without a command the tray app is started.
+`
+
+// IsCommand reports whether arg is a CLI subcommand rather than a flag or
+// other argument meant for the tray app.
+func IsCommand(arg string) bool {
+	switch arg {
+	case "add", "list", "complete", "skip", "replay", "validate", "help", "-h", "--help":
+		return true
+	}
+	return false
+}
+
+// Run executes a CLI command against the shared queue.json and returns the
+// process exit code. args[0] is the command name.
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	cmd, rest := args[0], args[1:]
+	if cmd == "help" || cmd == "-h" || cmd == "--help" {
+		fmt.Fprint(stdout, usage)
+		return 0
+	}
+
+	dataDir, err := util.AppDataDir()
+	if err != nil {
+		fmt.Fprintf(stderr, "appDataDir: %v\n", err)
+		return 1
+	}
+	if err := util.CheckWritable(dataDir); err != nil {
+		fmt.Fprintf(stderr, "data directory %s is not writable (set %s to use another one): %v\n", dataDir, util.EnvDataDir, err)
+		return 1
+	}
+	if cmd == "validate" {
+		// Also runs without opening the queue: the file may not be the
+		// queue's, or not fit to be loaded.
+		ok, err := validate(dataDir, rest, stdout)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", cmd, err)
+			return 1
+		}
+		if !ok {
+			return 1
+		}
+		return 0
+	}
+	if cmd == "replay" {
+		// Runs without opening the queue, which may be what is broken.
+		if err := replay(dataDir, rest, stdout); err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", cmd, err)
+			return 1
+		}
+		return 0
+	}
+	q, err := queue.NewTaskQueue(dataDir)
+	if err != nil {
+		fmt.Fprintf(stderr, "queue init: %v\n", err)
+		return 1
+	}
+	defer q.Close()
+	var hook *webhook.Sender
+	if cfg, _, err := hotkeys.LoadOrCreate(dataDir); err == nil {
+		q.SetMaxLen(cfg.QueueLimit())
+		q.SetBackupCount(cfg.BackupCount())
+		q.SetDueFirst(cfg.SortByDue)
+		hook = webhook.New(cfg.WebhookURL, cfg.WebhookSecret)
+	}
+	// The process is about to exit, so completed tasks are posted to the
+	// webhook in the foreground once the command is done.
+	var completed []queue.Task
+	q.SetOnComplete(func(t queue.Task) { completed = append(completed, t) })
+	defer func() {
+		if hook == nil || len(completed) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+		defer cancel()
+		for _, t := range completed {
+			if err := hook.Send(ctx, t); err != nil {
+				fmt.Fprintf(stderr, "webhook: %v\n", err)
+			}
+		}
+	}()
+	// There is no undo across invocations; delete the attachments of a
+	// completed task right away.
+	defer q.DiscardUndo()
+
+	switch cmd {
+	case "add":
+		err = add(q, rest, stdout)
+	case "list":
+		err = q.Exclusive(func() error {
+			list(q, stdout)
+			return nil
+		})
+	case "complete":
+		err = q.Exclusive(func() error {
+			t, err := q.Complete()
+			if err != nil {
+				return err
+			}
+			if t.ID == "" {
+				fmt.Fprintln(stdout, "queue is empty")
+				return nil
+			}
+			fmt.Fprintf(stdout, "completed: %s\n", firstLine(t.Text))
+			return nil
+		})
+	case "skip":
+		err = q.Exclusive(func() error {
+			if err := q.Skip(); err != nil {
+				return err
+			}
+			if t, ok := q.Peek(); ok {
+				fmt.Fprintf(stdout, "current: %s\n", firstLine(t.Text))
+			}
+			return nil
+		})
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n\n%s", cmd, usage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", cmd, err)
+		return 1
+	}
+	return 0
+}
+
+func add(q *queue.TaskQueue, args []string, stdout io.Writer) error {
+	text := strings.TrimSpace(strings.Join(args, " "))
+	if text == "" {
+		return fmt.Errorf("task text required")
+	}
+	t := queue.Task{
+		ID:        strconv.FormatInt(time.Now().UnixNano(), 10),
+		Text:      text,
+		CreatedAt: time.Now(),
+	}
+	return q.Exclusive(func() error {
+		if err := q.Enqueue(t); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "added: %s (%d in queue)\n", firstLine(t.Text), q.Count())
+		return nil
+	})
+}
+
+func list(q *queue.TaskQueue, stdout io.Writer) {
+	tasks := q.GetAll()
+	if len(tasks) == 0 {
+		fmt.Fprintln(stdout, "queue is empty")
+		return
+	}
+	for i, t := range tasks {
+		fmt.Fprintf(stdout, "%d. %s\n", i+1, firstLine(t.Text))
+	}
+}
+
+// replay prints the queue as the event log has it, or with --apply makes it
+// the queue, for when queue.json is lost or damaged.
+func replay(dataDir string, args []string, stdout io.Writer) error {
+	if len(args) > 0 && args[0] == "--apply" {
+		n, err := queue.RestoreFromEvents(dataDir)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "restored %d tasks from the event log\n", n)
+		return nil
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("unknown argument %q", args[0])
+	}
+	tasks, err := queue.ReplayEvents(dataDir)
+	if err != nil {
+		return err
+	}
+	if len(tasks) == 0 {
+		fmt.Fprintln(stdout, "the event log leaves the queue empty")
+		return nil
+	}
+	for i, t := range tasks {
+		fmt.Fprintf(stdout, "%d. %s\n", i+1, firstLine(t.Text))
+	}
+	return nil
+}
+
+// validate reports the problems queue.ValidateFile finds in the file named
+// by args, or in the queue's own file. ok is false when there are any.
+func validate(dataDir string, args []string, stdout io.Writer) (ok bool, err error) {
+	if len(args) > 1 {
+		return false, fmt.Errorf("unexpected argument %q", args[1])
+	}
+	path := filepath.Join(dataDir, "queue.json")
+	if len(args) == 1 {
+		path = args[0]
+	}
+	n, problems, err := queue.ValidateFile(path)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range problems {
+		fmt.Fprintln(stdout, p)
+	}
+	if len(problems) > 0 {
+		fmt.Fprintf(stdout, "%s: %d tasks, %d problems\n", path, n, len(problems))
+		return false, nil
+	}
+	fmt.Fprintf(stdout, "%s: %d tasks, no problems found\n", path, n)
+	return true, nil
+}
+
+func firstLine(text string) string {
+	if idx := strings.IndexByte(text, '\n'); idx >= 0 {
+		text = text[:idx]
+	}
+	return strings.TrimSpace(text)
+}
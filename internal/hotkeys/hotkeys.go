@@ -1,6 +1,7 @@
 package hotkeys
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -23,6 +24,8 @@ const (
 	ActionSkip             = "skip"
 	ActionComplete         = "complete"
 	ActionManageQueue      = "manage_queue"
+	ActionBrowseNext       = "browse_next"
+	ActionBrowsePrev       = "browse_prev"
 )
 
 type HotkeyConfig struct {
@@ -40,6 +43,23 @@ type KeyConfig struct {
 	Version        int                     `yaml:"version"                    json:"version"`
 	WhisperEnabled *bool                   `yaml:"whisper_enabled,omitempty"  json:"whisper_enabled"`
 	TimerMinutes   int                     `yaml:"timer_minutes,omitempty"    json:"timer_minutes,omitempty"`
+	MaxAttachMB    int                     `yaml:"max_attachment_mb,omitempty" json:"max_attachment_mb,omitempty"`
+	AttachTypes    []string                `yaml:"attachment_types,omitempty" json:"attachment_types,omitempty"`
+	ConfirmRemoval *bool                   `yaml:"confirm_removal,omitempty"  json:"confirm_removal"`
+	DialogMinutes  int                     `yaml:"dialog_timeout_minutes,omitempty" json:"dialog_timeout_minutes,omitempty"`
+	MaxQueueLen    int                     `yaml:"max_queue_len,omitempty"    json:"max_queue_len,omitempty"`
+	Backups        int                     `yaml:"backup_count,omitempty"     json:"backup_count,omitempty"`
+	ArchiveDays    int                     `yaml:"auto_archive_days,omitempty" json:"auto_archive_days,omitempty"`
+	ChecklistAuto  bool                    `yaml:"checklist_auto_complete,omitempty" json:"checklist_auto_complete,omitempty"`
+	ListDensity    string                  `yaml:"list_density,omitempty"     json:"list_density,omitempty"`
+	DoneIcon       bool                    `yaml:"done_icon,omitempty"        json:"done_icon,omitempty"`
+	SortByDue      bool                    `yaml:"sort_by_due,omitempty"      json:"sort_by_due,omitempty"`
+	NativeTaskView bool                    `yaml:"native_task_view,omitempty" json:"native_task_view,omitempty"`
+	DoneSound      bool                    `yaml:"completion_sound,omitempty" json:"completion_sound,omitempty"`
+	RestoreLast    bool                    `yaml:"restore_last_task,omitempty" json:"restore_last_task,omitempty"`
+	DoneSoundFile  string                  `yaml:"completion_sound_file,omitempty" json:"completion_sound_file,omitempty"`
+	WebhookURL     string                  `yaml:"webhook_url,omitempty"      json:"webhook_url,omitempty"`
+	WebhookSecret  string                  `yaml:"webhook_secret,omitempty"   json:"webhook_secret,omitempty"`
 	TrayGroups     []TrayGroupConfig       `yaml:"tray_groups,omitempty"      json:"tray_groups,omitempty"`
 	Hotkeys        map[string]HotkeyConfig `yaml:"hotkeys"                    json:"hotkeys"`
 }
@@ -50,6 +70,12 @@ func (cfg KeyConfig) IsWhisperEnabled() bool {
 	return cfg.WhisperEnabled == nil || *cfg.WhisperEnabled
 }
 
+// IsConfirmRemovalEnabled returns true if completing or deleting a task from
+// the tray asks for confirmation first. Defaults to true when never set.
+func (cfg KeyConfig) IsConfirmRemovalEnabled() bool {
+	return cfg.ConfirmRemoval == nil || *cfg.ConfirmRemoval
+}
+
 // DefaultTrayGroupOrder is the canonical group order used when config is absent.
 var DefaultTrayGroupOrder = []string{"task", "timer", "actions", "navigation", "system"}
 
@@ -84,6 +110,54 @@ func (cfg KeyConfig) TimerDuration() time.Duration {
 	return time.Duration(cfg.TimerMinutes) * time.Minute
 }
 
+// MaxAttachmentSize returns the largest accepted attachment in bytes (default 50 MB).
+func (cfg KeyConfig) MaxAttachmentSize() int64 {
+	if cfg.MaxAttachMB <= 0 {
+		return 50 << 20
+	}
+	return int64(cfg.MaxAttachMB) << 20
+}
+
+// DialogTimeout returns how long a native dialog may stay open before it is
+// closed as if cancelled (default 5 min).
+func (cfg KeyConfig) DialogTimeout() time.Duration {
+	if cfg.DialogMinutes <= 0 {
+		return 5 * time.Minute
+	}
+	return time.Duration(cfg.DialogMinutes) * time.Minute
+}
+
+// QueueLimit returns the maximum number of queued tasks, or 0 for no limit
+// (the default).
+func (cfg KeyConfig) QueueLimit() int {
+	return max(cfg.MaxQueueLen, 0)
+}
+
+// BackupCount returns how many previous versions of queue.json to keep, or
+// 0 for none (the default).
+func (cfg KeyConfig) BackupCount() int {
+	return max(cfg.Backups, 0)
+}
+
+// AutoArchiveDays returns the age in days after which queued tasks are moved
+// to the archive, or 0 to keep them (the default).
+func (cfg KeyConfig) AutoArchiveDays() int {
+	return max(cfg.ArchiveDays, 0)
+}
+
+// List densities for the Show queue table, see KeyConfig.ListDensity. An
+// empty value means DensityComfortable.
+const (
+	DensityComfortable = "comfortable"
+	DensityCompact     = "compact"
+)
+
+// IsCompactList reports whether the Show queue table uses tight rows
+// without attachment previews.
+func (cfg KeyConfig) IsCompactList() bool {
+	return cfg.ListDensity == DensityCompact
+}
+
 type Registered struct {
 	Action string
 	HK     *hotkey.Hotkey
@@ -102,6 +176,8 @@ func defaultKeyConfig() KeyConfig {
 			ActionSkip:             {Enabled: true, Combo: "ctrl+alt+s"},
 			ActionComplete:         {Enabled: true, Combo: "ctrl+alt+d"},
 			ActionManageQueue:      {Enabled: true, Combo: "ctrl+alt+m"},
+			ActionBrowseNext:       {Enabled: true, Combo: "ctrl+alt+j"},
+			ActionBrowsePrev:       {Enabled: true, Combo: "ctrl+alt+k"},
 		},
 	}
 }
@@ -144,6 +220,11 @@ func Save(dataDir string, cfg KeyConfig) error {
 
 // Validate checks that all enabled hotkey combos can be parsed.
 func Validate(cfg KeyConfig) error {
+	switch cfg.ListDensity {
+	case "", DensityComfortable, DensityCompact:
+	default:
+		return fmt.Errorf("list_density %q: want %s or %s", cfg.ListDensity, DensityComfortable, DensityCompact)
+	}
 	for action, hc := range cfg.Hotkeys {
 		if !hc.Enabled {
 			continue
@@ -186,8 +267,11 @@ func LoadOrCreate(dataDir string) (KeyConfig, string, error) {
 }
 
 // Register registers global hotkeys and starts goroutines to dispatch actions.
+// A combo that fails to parse or register is logged and skipped; the others
+// are still registered and the failures are returned joined.
 func Register(cfg KeyConfig, actionFn map[string]func()) ([]Registered, error) {
 	var regs []Registered
+	var errs []error
 
 	for action, hc := range cfg.Hotkeys {
 		if !hc.Enabled {
@@ -200,12 +284,18 @@ func Register(cfg KeyConfig, actionFn map[string]func()) ([]Registered, error) {
 
 		mods, key, err := parseHotkeyCombo(hc.Combo)
 		if err != nil {
-			return nil, fmt.Errorf("hotkey %s (%q): %w", action, hc.Combo, err)
+			err = fmt.Errorf("hotkey %s (%q): %w", action, hc.Combo, err)
+			log.Printf("[hotkeys] %v", err)
+			errs = append(errs, err)
+			continue
 		}
 
 		hk := hotkey.New(mods, key)
 		if err := hk.Register(); err != nil {
-			return nil, fmt.Errorf("failed to register hotkey %s (%q): %w", action, hc.Combo, err)
+			err = fmt.Errorf("failed to register hotkey %s (%q): %w", action, hc.Combo, err)
+			log.Printf("[hotkeys] %v", err)
+			errs = append(errs, err)
+			continue
 		}
 		log.Printf("[hotkeys] registered %s → %s", action, hc.Combo)
 
@@ -218,7 +308,7 @@ func Register(cfg KeyConfig, actionFn map[string]func()) ([]Registered, error) {
 		}(fn, hk)
 	}
 
-	return regs, nil
+	return regs, errors.Join(errs...)
 }
 
 // Unregister unregisters all registered hotkeys.
@@ -0,0 +1,496 @@
+package i18n
+
+// ru is the Russian catalog. Format verbs must match the English key.
+var ru = map[string]string{
+	// Tray menu
+	"Queue":                      "Очередь",
+	"No tasks":                   "Нет задач",
+	"All tasks snoozed":          "Все задачи отложены",
+	"Click to view current task": "Открыть текущую задачу",
+	"Upcoming":                   "Дальше в очереди",
+	"The next tasks in line":     "Следующие задачи",
+	"Next task":                  "Следующая задача",
+	"Previous task":              "Предыдущая задача",
+	"Show the next task in line without changing the order":     "Показать следующую задачу, не меняя порядок",
+	"Show the previous task in line without changing the order": "Показать предыдущую задачу, не меняя порядок",
+	"Task %d of %d":                "Задача %d из %d",
+	"Task %d of %d (current)":      "Задача %d из %d (текущая)",
+	"Preview this task":            "Посмотреть задачу",
+	"Start timer":                  "Запустить таймер",
+	"Start a focus timer":          "Запустить таймер фокуса",
+	"▶ Resume":                     "▶ Продолжить",
+	"⏸ Pause":                      "⏸ Пауза",
+	"Skip":                         "Пропустить",
+	"Move current task to the end": "Переместить текущую задачу в конец",
+	"Done":                         "Готово",
+	"Complete current task":        "Завершить текущую задачу",
+	"Done with note…":              "Готово с заметкой…",
+	"Complete current task and record the outcome in history": "Завершить текущую задачу и записать результат в историю",
+	"Undo":          "Отменить",
+	"Undo complete": "Отменить завершение",
+	"Undo skip":     "Отменить пропуск",
+	"Undo delete":   "Отменить удаление",
+	"Revert the last complete, skip or delete": "Отменить последнее завершение, пропуск или удаление",
+	"Edit task…":             "Изменить задачу…",
+	"Edit current task text": "Изменить текст текущей задачи",
+	"Copy text":              "Копировать текст",
+	"Copy the current task's text to the clipboard":             "Скопировать текст текущей задачи в буфер обмена",
+	"Open attachment…":                                          "Открыть вложение…",
+	"Open an attachment of the current task in its default app": "Открыть вложение текущей задачи в программе по умолчанию",
+	"Move to front…":                                            "Сделать текущей…",
+	"Pick a task to make current":                               "Выбрать задачу, которая станет текущей",
+	"Pin task…":                                                 "Закрепить задачу…",
+	"Unpin task":                                                "Открепить задачу",
+	"Keep a task current, even after skips":                     "Держать задачу текущей, даже после пропусков",
+	"Snooze…":                                                   "Отложить…",
+	"Hide the current task for a while":                         "Скрыть текущую задачу на время",
+	"Duplicate task…":                                           "Дублировать задачу…",
+	"Pick a task to copy to the end of the queue":               "Выбрать задачу, копия которой встанет в конец очереди",
+	"Save as template…":                                         "Сохранить как шаблон…",
+	"Keep the current task's text, tags, priority and recurrence to add again later": "Запомнить текст, теги, приоритет и повторение текущей задачи, чтобы добавлять её снова",
+	"Delete task…":          "Удалить задачу…",
+	"Pick a task to delete": "Выбрать задачу для удаления",
+	"Bulk actions…":         "Массовые действия…",
+	"Complete, skip or delete several tasks at once": "Завершить, пропустить или удалить несколько задач сразу",
+	"Add task":       "Добавить задачу",
+	"Quick add":      "Быстрое добавление",
+	"Add text only…": "Только текст…",
+	"Add a task from a single line of text, no further questions": "Добавить задачу одной строкой текста, без других вопросов",
+	"Add from template…":                                "Создать из шаблона…",
+	"Add a task from a saved template":                  "Добавить задачу по сохранённому шаблону",
+	"Add task (advanced)":                               "Добавить задачу (расширенно)",
+	"Open advanced editor in browser":                   "Открыть расширенный редактор в браузере",
+	"Focus":                                             "Фокус",
+	"Show only the current task, with Done and Skip":    "Показать только текущую задачу с кнопками «Готово» и «Пропустить»",
+	"All tasks":                                         "Все задачи",
+	"View and manage all tasks":                         "Просмотр и управление всеми задачами",
+	"Show queue":                                        "Показать очередь",
+	"Overview of the whole queue; drag rows to reorder": "Вся очередь; строки можно перетаскивать, чтобы поменять порядок",
+	"Filter by tag…":                                    "Фильтр по тегу…",
+	"Show tasks with a tag":                             "Показать задачи с тегом",
+	"Search…":                                           "Поиск…",
+	"Find tasks by text or tag":                         "Найти задачи по тексту или тегу",
+	"History":                                           "История",
+	"View completed tasks":                              "Завершённые задачи",
+	"Statistics":                                        "Статистика",
+	"Completed tasks per day":                           "Завершённые задачи по дням",
+	"Import…":                                           "Импорт…",
+	"Add tasks from a .txt/.csv file or an export bundle": "Добавить задачи из файла .txt/.csv или архива экспорта",
+	"Export…": "Экспорт…",
+	"Save the queue with attachments as a zip": "Сохранить очередь с вложениями в zip",
+	"Export task…": "Экспорт задачи…",
+	"Save the current task as a self-contained HTML file to share": "Сохранить текущую задачу в один HTML-файл, чтобы поделиться ею",
+	"Copy as Markdown…":                                            "Скопировать как Markdown…",
+	"Copy or save the queue as a Markdown checklist":               "Скопировать или сохранить очередь списком задач в Markdown",
+	"Open data folder":                                             "Открыть папку данных",
+	"Show queue.json, history and attachments in the file manager": "Показать queue.json, историю и вложения в файловом менеджере",
+	"Clean up attachments…":                                        "Очистить вложения…",
+	"Delete attachment files no task uses":                         "Удалить файлы вложений, которые не нужны ни одной задаче",
+	"Find duplicates…":                                             "Найти дубликаты…",
+	"Find tasks with the same text and delete the copies":          "Найти задачи с одинаковым текстом и удалить копии",
+	"Empty queue…":                                                 "Очистить очередь…",
+	"Remove every task after saving a backup":                      "Удалить все задачи, сначала сохранив резервную копию",
+	"Restore from backup…":                                         "Восстановить из резервной копии…",
+	"Put the queue back as it was at an earlier save":              "Вернуть очередь к состоянию одного из прошлых сохранений",
+	"Archive…": "Архив…",
+	"View the tasks moved out of the queue for their age and restore them": "Задачи, убранные из очереди по возрасту, — посмотреть и вернуть",
+	"Settings":                       "Настройки",
+	"Configure hotkeys":              "Настроить горячие клавиши",
+	"Quit":                           "Выход",
+	"Tasks: %d":                      "Задач: %d",
+	"Tasks: %d · %s on current task": "Задач: %d · %s на текущей задаче",
+	"Last completed: %s":             "Последнее завершение: %s",
+	"just now":                       "только что",
+	"%d min ago":                     "%d мин назад",
+	"%d h ago":                       "%d ч назад",
+	"%d d ago":                       "%d дн. назад",
+
+	// Notifications
+	"Queue — Task added":                                 "Очередь — задача добавлена",
+	"Queue — Reminder":                                   "Очередь — напоминание",
+	"The queue is empty — great job!":                    "Очередь пуста — отличная работа!",
+	"Queue — Task overdue":                               "Очередь — срок задачи истёк",
+	"Queue — Update available":                           "Очередь — доступно обновление",
+	"Queue Timer":                                        "Таймер очереди",
+	"Time is up! Take a break.":                          "Время вышло! Сделай перерыв.",
+	"Copied to clipboard":                                "Скопировано в буфер обмена",
+	"Version %s is available. Open Settings to install.": "Доступна версия %s. Установить её можно в настройках.",
+	"%d of %d attachments are missing from %s. See app.log for the tasks.": "%d из %d вложений нет в %s. Задачи перечислены в app.log.",
+
+	// Dialogs
+	"Queue is already running: use its icon in the menu bar or system tray.\n\nThe command-line interface can be used alongside it.": "Очередь уже запущена: используйте её значок в строке меню или системном трее.\n\nКомандную строку можно использовать параллельно.",
+	"Add":               "Добавить",
+	"Cancel":            "Отмена",
+	"Next":              "Далее",
+	"Save":              "Сохранить",
+	"Delete":            "Удалить",
+	"Remove":            "Убрать",
+	"Complete":          "Завершить",
+	"Download":          "Скачать",
+	"Stop":              "Стоп",
+	"Empty":             "Очистить",
+	"Task text:":        "Текст задачи:",
+	"Edit task":         "Изменить задачу",
+	"Notes (optional):": "Заметки (необязательно):",
+	"No notes":          "Без заметок",
+	"Tags (comma-separated), leave empty for none:": "Теги через запятую, пусто — без тегов:",
+	"No tags":                                    "Без тегов",
+	"Search":                                     "Поиск",
+	"Find tasks containing:":                     "Найти задачи, содержащие:",
+	"Filter by tag":                              "Фильтр по тегу",
+	"Show tasks tagged:":                         "Показать задачи с тегом:",
+	"Open attachment":                            "Открыть вложение",
+	"Open with the default app:":                 "Открыть в программе по умолчанию:",
+	"Attach files":                               "Прикрепить файлы",
+	"Paste image from clipboard":                 "Вставить изображение из буфера обмена",
+	"Take a screenshot":                          "Сделать скриншот",
+	"Attach from URL":                            "Прикрепить по ссылке",
+	"Record audio (up to 60 s)":                  "Записать аудио (до 60 с)",
+	"Attach something to this task?":             "Прикрепить что-нибудь к задаче?",
+	"No attachment":                              "Без вложения",
+	"Image, audio or video URL (http or https):": "Ссылка на изображение, аудио или видео (http или https):",
+	"Recording":                                  "Запись",
+	"Recording… %v left. Press Stop to finish.":  "Идёт запись… осталось %v. Нажмите «Стоп», чтобы закончить.",
+	"Images":                                     "Изображения",
+	"Audio":                                      "Аудио",
+	"Video":                                      "Видео",
+	"Other files":                                "Другие файлы",
+	"Attachment %d (Cancel to finish)":           "Вложение %d («Отмена» — закончить)",
+	"Import tasks":                               "Импорт задач",
+	"Task lists and bundles":                     "Списки задач и архивы",
+	"Export queue":                               "Экспорт очереди",
+	"Zip archive":                                "Архив zip",
+	"Export task":                                "Экспорт задачи",
+	"HTML page":                                  "Страница HTML",
+	"The attachments of this task take %d MB; the HTML file will be about %d MB.\nExport anyway?": "Вложения задачи занимают %d МБ; HTML-файл получится около %d МБ.\nВсё равно экспортировать?",
+	"Saved the task to %s.":                     "Задача сохранена в файл %s.",
+	"Due date:":                                 "Срок:",
+	"Time (HH:MM):":                             "Время (ЧЧ:ММ):",
+	"Invalid time, expected HH:MM: %s":          "Неверное время, ожидается ЧЧ:ММ: %s",
+	"%s has already passed. Pick a later time.": "%s уже прошло. Выберите более позднее время.",
+	"No due date":                               "Без срока",
+	"Remind me:":                                "Напомнить:",
+	"No reminder":                               "Без напоминания",
+	"In 30 minutes":                             "Через 30 минут",
+	"In 1 hour":                                 "Через час",
+	"In 2 hours":                                "Через 2 часа",
+	"30 minutes before due":                     "За 30 минут до срока",
+	"1 hour before due":                         "За час до срока",
+	"1 day before due":                          "За день до срока",
+	"Estimated time (minutes, or e.g. 1h30m), leave empty for none:": "Оценка времени (в минутах или, например, 1h30m), пусто — без оценки:",
+	"No estimate":                    "Без оценки",
+	"Complete task":                  "Завершить задачу",
+	"Complete this task?":            "Завершить эту задачу?",
+	"Note (optional):":               "Заметка (необязательно):",
+	"Normal":                         "Обычный",
+	"High":                           "Высокий",
+	"Urgent":                         "Срочный",
+	"Priority:":                      "Приоритет:",
+	"Once":                           "Один раз",
+	"Daily":                          "Каждый день",
+	"Weekly":                         "Каждую неделю",
+	"Repeat:":                        "Повтор:",
+	"Snooze":                         "Отложить",
+	"1 hour":                         "1 час",
+	"3 hours":                        "3 часа",
+	"Tomorrow, 09:00":                "Завтра, 09:00",
+	"Snooze the current task for:":   "Отложить текущую задачу на:",
+	"Pick a date…":                   "Выбрать дату…",
+	"Snooze the current task until:": "Отложить текущую задачу до:",
+	"Red":                            "Красный",
+	"Orange":                         "Оранжевый",
+	"Yellow":                         "Жёлтый",
+	"Green":                          "Зелёный",
+	"Blue":                           "Синий",
+	"Purple":                         "Фиолетовый",
+	"Gray":                           "Серый",
+	"Color label:":                   "Цветная метка:",
+	"No color":                       "Без цвета",
+	"Blocked by (the task waits until these are done):": "Зависит от (задача ждёт, пока эти не будут сделаны):",
+	"Not blocked":                      "Без зависимостей",
+	"Move to front":                    "Сделать текущей",
+	"Pin task":                         "Закрепить задачу",
+	"Select the task to keep current:": "Выберите задачу, которая останется текущей:",
+	"Select the task to work on next:": "Выберите задачу, которой заняться следующей:",
+	"Duplicate task":                   "Дублировать задачу",
+	"Select the task to copy:":         "Выберите задачу для копирования:",
+	"Save as template":                 "Сохранить как шаблон",
+	"Template name:":                   "Название шаблона:",
+	"Template \"%s\" saved.":           "Шаблон «%s» сохранён.",
+	"Template \"%s\" updated.":         "Шаблон «%s» обновлён.",
+	"Add from template":                "Создать из шаблона",
+	"There are no templates yet. Use Save as template… on the current task to make one.": "Шаблонов пока нет. Создайте его из текущей задачи пунктом «Сохранить как шаблон…».",
+	"Select the template to add:":    "Выберите шаблон для новой задачи:",
+	"Delete a template…":             "Удалить шаблон…",
+	"Delete template":                "Удалить шаблон",
+	"Select the template to delete:": "Выберите шаблон для удаления:",
+	"Copy as Markdown":               "Скопировать как Markdown",
+	"Copy":                           "Скопировать",
+	"Save as file…":                  "Сохранить в файл…",
+	"Copy the %d queued tasks to the clipboard as a Markdown checklist, or save them to a .md file?": "Скопировать %d задач очереди в буфер обмена списком в Markdown или сохранить их в файл .md?",
+	"%d tasks as Markdown":  "%d задач в формате Markdown",
+	"Saved %d tasks to %s.": "%d задач сохранено в %s.",
+	"Due: %s":               "Срок: %s",
+	"Tags: %s":              "Теги: %s",
+	"Attachment: %s":        "Вложение: %s",
+	"Archive":               "Архив",
+	"The archive is empty.": "Архив пуст.",
+	"Archived tasks, newest first. Select the ones to put back in the queue:": "Задачи в архиве, сначала новые. Выберите те, что нужно вернуть в очередь:",
+	"Queue — Tasks archived": "Очередь — задачи в архиве",
+	"%d tasks older than %d days were moved to the archive. Restore them with Archive….": "%d задач старше %d дн. перенесено в архив. Вернуть их можно пунктом «Архив…».",
+	"Delete the template \"%s\"?":               "Удалить шаблон «%s»?",
+	"Delete task":                               "Удалить задачу",
+	"Select the task to delete:":                "Выберите задачу для удаления:",
+	"Delete this task?":                         "Удалить эту задачу?",
+	"Bulk actions":                              "Массовые действия",
+	"Select the tasks:":                         "Выберите задачи:",
+	"Do what with the %d selected tasks?":       "Что сделать с выбранными задачами (%d)?",
+	"Complete %d tasks? This cannot be undone.": "Завершить задачи (%d)? Отменить это нельзя.",
+	"Delete %d tasks? This cannot be undone.":   "Удалить задачи (%d)? Отменить это нельзя.",
+	"Completed %d tasks.":                       "Завершено задач: %d.",
+	"Moved %d tasks to the end of the queue.":   "Перенесено в конец очереди задач: %d.",
+	"Pinned tasks stay in place.":               "Закреплённые задачи остаются на месте.",
+	"Deleted %d tasks.":                         "Удалено задач: %d.",
+	"Remove all attachments from this task?":    "Убрать все вложения из этой задачи?",
+	"The queue is full (%d tasks).\nComplete or delete a task first, or raise the limit in Settings.": "Очередь заполнена (%d задач).\nСначала завершите или удалите задачу либо увеличьте лимит в настройках.",
+	"The clipboard has no image — pick a file instead.":                                               "В буфере обмена нет изображения — выберите файл.",
+	"No screenshot tool was found — pick a file instead.":                                             "Программа для скриншотов не найдена — выберите файл.",
+	"No tasks have tags yet.":            "Пока ни у одной задачи нет тегов.",
+	"No tasks match \"%s\".":             "Нет задач, подходящих под «%s».",
+	"Import":                             "Импорт",
+	"Imported %d tasks from the bundle.": "Из архива импортировано задач: %d.",
+	"Imported %d tasks.":                 "Импортировано задач: %d.",
+	"Skipped %d malformed rows.":         "Пропущено некорректных строк: %d.",
+	"Export":                             "Экспорт",
+	"Exported %d tasks to %s.":           "Экспортировано задач: %d, файл %s.",
+	"Clean up attachments":               "Очистка вложений",
+	"Delete attachment files that no queued task or history entry refers to?": "Удалить файлы вложений, на которые не ссылается ни одна задача в очереди или истории?",
+	"Removed %d unused files.":  "Удалено неиспользуемых файлов: %d.",
+	"Find duplicates":           "Найти дубликаты",
+	"No duplicate tasks found.": "Дубликатов не найдено.",
+	"Group %d of %d: %d tasks have the text\n\n%s\n\nKeep the oldest one, added %s, and delete the other %d?": "Группа %d из %d: у задач (%d) одинаковый текст\n\n%s\n\nОставить самую раннюю, добавленную %s, и удалить остальные (%d)?",
+	"Delete copies":               "Удалить копии",
+	"Keep all":                    "Оставить все",
+	"Deleted %d duplicate tasks.": "Удалено дубликатов: %d.",
+	"Restore from backup":         "Восстановить из резервной копии",
+	"Restore":                     "Восстановить",
+	"Replace the queue with the version saved at:":                                                           "Заменить очередь версией, сохранённой:",
+	"There are no backups yet. Turn them on in Settings → Queue; a copy is kept on every save from then on.": "Резервных копий пока нет. Включите их в Settings → Queue — после этого копия сохраняется при каждом изменении.",
+	"%s — unreadable": "%s — не читается",
+	"%s — %d tasks":   "%s — задач: %d",
+	"Replace the %d queued tasks with the %s version?\nThe queue as it is now is kept as the newest backup.": "Заменить задачи в очереди (%d) версией %s?\nТекущая очередь сохранится как самая новая резервная копия.",
+	"Restored %d tasks.": "Восстановлено задач: %d.",
+	"Empty queue":        "Очистить очередь",
+	"Remove all %d tasks from the queue?\nA backup is saved first; import it to get the tasks back.":               "Удалить из очереди все задачи (%d)?\nСначала сохраняется резервная копия; импортируйте её, чтобы вернуть задачи.",
+	"The queue is empty. The tasks were saved to:\n%s\n\nUse Import… with this file to restore them.":              "Очередь пуста. Задачи сохранены в файл:\n%s\n\nЧтобы вернуть их, импортируйте этот файл через «Импорт…».",
+	"Could not save the queue: %v\n\nThe change was undone. Check that the data folder is writable and try again.": "Не удалось сохранить очередь: %v\n\nИзменение отменено. Проверьте, что в папку данных можно писать, и повторите.",
+	"Could not open %s: %v": "Не удалось открыть %s: %v",
+	"Hotkeys":               "Горячие клавиши",
+	"Manage UI":             "Управление очередью",
+
+	// Page titles
+	"Current task": "Текущая задача",
+
+	// Manage pages
+	"Task #%d":        "Задача #%d",
+	"Queue is empty.": "Очередь пуста.",
+	"Queue is empty. You can close this window.": "Очередь пуста. Это окно можно закрыть.",
+	"Back to queue":           "К очереди",
+	"Manage order":            "Порядок очереди",
+	"Manage queue":            "Управление очередью",
+	"Save order":              "Сохранить порядок",
+	"Enter — Done, S — Skip":  "Enter — выполнить, S — пропустить",
+	"Copied ✓":                "Скопировано ✓",
+	"Could not copy: %s":      "Не удалось скопировать: %s",
+	"Edit":                    "Изменить",
+	"Pin":                     "Закрепить",
+	"Unpin":                   "Открепить",
+	"Open %s":                 "Открыть %s",
+	"Open file":               "Открыть файл",
+	"Open in the default app": "Открыть в приложении по умолчанию",
+	"Reminder: %s":            "Напоминание: %s",
+	"Estimate: %s":            "Оценка: %s",
+	"overdue":                 "просрочено",
+	"None":                    "Нет",
+	"Checklist":               "Чек-лист",
+	"Attachment %s is missing: the file was moved or deleted.": "Вложение %s не найдено: файл перемещён или удалён.",
+	"Attachment:":                             "Вложение:",
+	"unknown type %s":                         "неизвестный тип %s",
+	"Write task in Markdown...":               "Опишите задачу в Markdown...",
+	"Notes (optional, Markdown)":              "Заметки (необязательно, Markdown)",
+	"Checklist (optional), one item per line": "Чек-лист (необязательно), по пункту в строке",
+	"Tags:":                "Теги:",
+	"work, home":           "работа, дом",
+	"Due date (optional):": "Срок (необязательно):",
+	"Estimate:":            "Оценка:",
+	"30 or 1h30m":          "30 или 1h30m",
+	"Color:":               "Цвет:",
+	"Remind:":              "Напомнить:",
+	"Blocked by (optional, Ctrl/⌘-click for several):": "Ждёт задачи (необязательно, Ctrl/⌘-клик для нескольких):",
+	"Attachments:": "Вложения:",
+	"Markdown supported. Paste image (Ctrl+V / ⌘V) to attach. You can also record a voice note.": "Поддерживается Markdown. Вставьте изображение (Ctrl+V / ⌘V), чтобы приложить его. Можно также записать голосовую заметку.",
+	"Paste image (Ctrl+V / ⌘V) to attach":                                                        "Вставьте изображение (Ctrl+V / ⌘V), чтобы приложить его",
+	"Record voice note":                                                                          "Записать голосовую заметку",
+	"Stop recording":                                                                             "Остановить запись",
+	"Recording…":                                                                                 "Идёт запись…",
+	"Recording saved.":                                                                           "Запись сохранена.",
+	"Transcribing…":                                                                              "Распознавание…",
+	"Transcribed.":                                                                               "Распознано.",
+	"Transcription error: %s":                                                                    "Ошибка распознавания: %s",
+	"Microphone access denied: %s":                                                               "Нет доступа к микрофону: %s",
+	"Voice note:":                                                                                "Голосовая заметка:",
+	"Uploading image…":                                                                           "Загрузка изображения…",
+	"Upload error: %s":                                                                           "Ошибка загрузки: %s",
+	"%d image(s) attached (paste again to add more)":                                             "Приложено изображений: %d (вставьте ещё, чтобы добавить)",
+	"%d file(s) attached, last pasted: %s":                                                       "Приложено файлов: %d, последний вставлен: %s",
+	"Saving…":                                                                                    "Сохранение…",
+	"Saved":                                                                                      "Сохранено",
+	"Loading…":                                                                                   "Загрузка…",
+	"Error: ":                                                                                    "Ошибка: ",
+	"Error: %s":                                                                                  "Ошибка: %s",
+	"Drag to reorder · Click to preview":                                                         "Перетащите, чтобы изменить порядок · Щёлкните для просмотра",
+	"Click a task to preview it":                                                                 "Щёлкните задачу, чтобы просмотреть её",
+	"Whisper is not installed":                                                                   "Whisper не установлен",
+	"Transcription failed":                                                                       "Ошибка транскрипции",
+	"Could not download the Whisper model: no network access": "Не удалось загрузить модель Whisper — нет доступа к сети",
+	"Could not load the Whisper model":                        "Ошибка загрузки модели Whisper",
+	"The audio file was not found":                            "Аудиофайл не найден",
+	"Could not read the transcription":                        "Не удалось прочитать результат транскрипции",
+
+	// Settings page
+	"Click a button and press the key combination. Escape cancels.": "Нажмите кнопку и введите сочетание клавиш. Escape — отмена.",
+	"Action":                     "Действие",
+	"On":                         "Вкл",
+	"Shortcut":                   "Сочетание",
+	"Clear":                      "Очистить",
+	"View current task":          "Показать текущую задачу",
+	"Add task (quick dialog)":    "Добавить задачу (быстрый диалог)",
+	"Add task (advanced editor)": "Добавить задачу (расширенный редактор)",
+	"Skip task":                  "Пропустить задачу",
+	"Show next task":             "Показать следующую задачу",
+	"Show previous task":         "Показать предыдущую задачу",
+	"Tray":                       "Трей",
+	"Timer length:":              "Длительность таймера:",
+	"min":                        "мин",
+	"Menu group order; changes take effect after a restart.":    "Порядок групп — изменения вступают в силу после перезапуска.",
+	"Current task (task title, Upcoming, Next / Previous task)": "Текущая задача (заголовок задачи, Upcoming, Next / Previous task)",
+	"Timer (Start/Pause)": "Таймер (Start/Pause)",
+	"Actions (Skip / Done / Done with note / Undo / Snooze / Edit / Copy / Open attachment / Pin / Duplicate / Save as template / Delete / Bulk)": "Действия (Skip / Done / Done with note / Undo / Snooze / Edit / Copy / Open attachment / Pin / Duplicate / Save as template / Delete / Bulk)",
+	"Navigation (Add / Add text only / Add from template / Focus / View / Manage / Search / History / Stats)":                                     "Навигация (Add / Add text only / Add from template / Focus / View / Manage / Search / History / Stats)",
+	"System (Import / Export / Export task / Markdown / Data folder / Cleanup / Duplicates / Empty queue / Restore / Archive / Settings / Quit)":  "Система (Import / Export / Export task / Markdown / Data folder / Cleanup / Duplicates / Empty queue / Restore / Archive / Settings / Quit)",
+	"Up":                              "Вверх",
+	"Down":                            "Вниз",
+	"conflict":                        "конфликт",
+	"Press the keys…":                 "Нажмите клавиши…",
+	"Fix the conflicts before saving": "Исправьте конфликты перед сохранением",
+	"Dialogs":                         "Диалоги",
+	"Ask before completing or deleting a task from the tray menu or hotkeys":                    "Спрашивать подтверждение перед выполнением или удалением задачи из меню трея или горячими клавишами",
+	"Show the current task in a plain dialog instead of the browser (no images or players)":     "Показывать текущую задачу в простом диалоге вместо браузера (без изображений и плееров)",
+	"Close unanswered dialogs after":                                                            "Закрывать диалоги без ответа через",
+	"Complete a task when the last item of its checklist is checked":                            "Выполнять задачу, когда отмечен последний пункт её чек-листа",
+	"Make the task with the earliest due date current; tasks without one follow in queue order": "Делать текущей задачу с ближайшим сроком; задачи без срока идут по порядку очереди",
+	"On start, reopen the task last opened from the tray if it is still queued":                 "При запуске снова открывать задачу, открытую из трея последней, если она ещё в очереди",
+	"Show a check mark in the tray once the last task is done, until a task is added":           "Показывать галочку в трее после выполнения последней задачи, пока не добавлена новая",
+	"Maximum queue length:":                        "Максимальная длина очереди:",
+	"tasks (0 = unlimited)":                        "задач (0 = без ограничения)",
+	"Keep the last":                                "Хранить последние",
+	"versions of queue.json as backups (0 = none)": "версий queue.json как резервные копии (0 = не хранить)",
+	"Archive tasks older than":                     "Архивировать задачи старше",
+	"days (0 = never)":                             "дней (0 = никогда)",
+	"Show queue density:":                          "Плотность списка очереди:",
+	"Comfortable":                                  "Свободная",
+	"Compact — tight rows, no image previews":      "Компактная — плотные строки, без превью изображений",
+	"Play a sound when a task is completed":        "Проигрывать звук при выполнении задачи",
+	"Sound file:":                                  "Звуковой файл:",
+	"Full path to an audio file; WAV works on every system. Leave empty for the built-in chime.": "Полный путь к аудиофайлу; WAV работает на любой системе. Оставьте пустым для встроенного сигнала.",
+	"built-in chime": "встроенный сигнал",
+	"Every completed task is POSTed as JSON to this URL. Leave empty to turn it off.": "Каждая выполненная задача отправляется на этот URL POST-запросом в JSON. Оставьте пустым, чтобы отключить.",
+	"Secret:": "Секрет:",
+	"With a secret, requests carry %s, an HMAC-SHA256 of the body.": "С секретом запросы содержат %s — HMAC-SHA256 тела.",
+	"Attachments":              "Вложения",
+	"Maximum attachment size:": "Максимальный размер вложения:",
+	"MB":                       "МБ",
+	"Other allowed types:":     "Другие разрешённые типы:",
+	"Images, audio and video are always allowed and shown inline. Types listed here are stored the same way and offered as a download; programs such as %s or %s are not accepted.": "Изображения, аудио и видео разрешены всегда и показываются на странице. Перечисленные здесь типы хранятся так же и предлагаются для скачивания; программы вроде %s или %s не принимаются.",
+	"Data folder":      "Папка данных",
+	"Folder:":          "Папка:",
+	"default location": "расположение по умолчанию",
+	"An absolute path; leave empty for the default. Takes effect after a restart, existing data is not moved.": "Абсолютный путь; оставьте пустым для значения по умолчанию. Вступает в силу после перезапуска, существующие данные не переносятся.",
+	"In use:":                         "Используется:",
+	"%s is set and takes precedence.": "Задана переменная %s, она имеет приоритет.",
+	"Autostart":                       "Автозапуск",
+	"Launch automatically when the system starts":                     "Запускать автоматически при старте системы",
+	"Voice transcription (Whisper)":                                   "Распознавание речи (Whisper)",
+	"Enable Whisper transcription (voice recording in Add task form)": "Включить распознавание Whisper (запись голоса в форме добавления задачи)",
+	"Back":                    "Назад",
+	"Updates":                 "Обновления",
+	"Current version:":        "Текущая версия:",
+	"Check for updates":       "Проверить обновления",
+	"Checking…":               "Проверка…",
+	"Up to date (checked %s)": "Установлена последняя версия (проверено %s)",
+	"Update available: %s":    "Доступно обновление: %s",
+	"Release notes":           "Что нового",
+	"Install %s and restart":  "Установить %s и перезапустить",
+	"No binary for this platform — install manually from the link above.": "Нет сборки для этой платформы — установите вручную по ссылке выше.",
+	"Downloading and installing…":                                         "Загрузка и установка…",
+	"Done! Restarting…":                                                   "Готово! Перезапуск…",
+	"Check failed: %s":                                                    "Ошибка проверки: %s",
+	"Install error: %s":                                                   "Ошибка установки: %s",
+
+	// Queue list and search
+	"Tasks tagged #%s":                  "Задачи с тегом #%s",
+	"#%d in queue":                      "#%d в очереди",
+	"No tasks with this tag.":           "Задач с этим тегом нет.",
+	"Queue (%d)":                        "Очередь (%d)",
+	"Sort:":                             "Сортировка:",
+	"Queue order":                       "Порядок очереди",
+	"Newest first":                      "Сначала новые",
+	"Priority":                          "Приоритет",
+	"Alphabetical":                      "По алфавиту",
+	"Due date":                          "По сроку",
+	"The queue is empty.":               "Очередь пуста.",
+	"About %s of work queued":           "Работы в очереди примерно на %s",
+	"%d of %d tasks estimated":          "оценено задач: %d из %d",
+	"under a day":                       "меньше дня",
+	"under a week":                      "меньше недели",
+	"older":                             "старше",
+	"Prev":                              "Назад",
+	"Page %d of %d · tasks %d–%d of %d": "Страница %d из %d · задачи %d–%d из %d",
+	"Created":                           "Создана",
+	"Due":                               "Срок",
+	"Est.":                              "Оценка",
+	"Task":                              "Задача",
+	"%d attachment(s)":                  "Вложений: %d",
+	"until %s":                          "до %s",
+	"waits for %s":                      "ждёт %s",
+	"View":                              "Открыть",
+	"Sorted for display only; the queue order is unchanged. %s to drag rows.": "Сортировка только для показа, порядок очереди не меняется. %s, чтобы перетаскивать строки.",
+	"Switch to queue order":                       "Переключитесь на порядок очереди",
+	"Drag rows to reorder them within this page.": "Перетаскивайте строки, чтобы менять порядок на этой странице.",
+	"Drag rows to reorder the queue.":             "Перетаскивайте строки, чтобы менять порядок очереди.",
+	"attachment file is missing":                  "файл вложения не найден",
+	"image":                                       "изображение",
+	"%dd %dh in queue":                            "%d д %d ч в очереди",
+	"%dh %dm in queue":                            "%d ч %d мин в очереди",
+	"%dm in queue":                                "%d мин в очереди",
+	"Added":                                       "Добавлена",
+	"yesterday":                                   "вчера",
+	"No matching tasks.":                          "Подходящих задач нет.",
+
+	// History and statistics
+	"Today":            "Сегодня",
+	"Yesterday":        "Вчера",
+	"This week":        "За неделю",
+	"This month":       "За месяц",
+	"Total in history": "Всего в истории",
+	"No tasks completed in the last %d days.": "За последние %d дней задач не выполнено.",
+	"Completed in the last %d days":           "Выполнено за последние %d дней",
+	"History is empty.":                       "История пуста.",
+	"Clear all history":                       "Очистить всю историю",
+	"Started: %s · Finished: %s · %s":         "Начало: %s · Конец: %s · %s",
+	"Completed: %s":                           "Завершено: %s",
+	"Created: %s":                             "Создано: %s",
+	"Return to queue":                         "Вернуть в очередь",
+	"The task is back in the queue, but some attachments are gone: ": "Задача возвращена в очередь, но вложений уже нет: ",
+	"Delete all history?": "Удалить всю историю?",
+}
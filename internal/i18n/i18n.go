@@ -0,0 +1,101 @@
+// Package i18n translates the strings of the tray menu, native dialogs and
+// notifications. Messages are keyed by their English text, which is also
+// what is shown when the active language has no catalog or no entry for a
+// key, so an untranslated string never shows up blank.
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// EnvLang overrides the detected language, e.g. QUEUE_LANG=en or ru.
+const EnvLang = "QUEUE_LANG"
+
+// English is the language of the message keys; it needs no catalog.
+const English = "en"
+
+// catalogs maps a language code to its translations, keyed by English text.
+var catalogs = map[string]map[string]string{
+	"ru": ru,
+}
+
+var lang atomic.Value // string
+
+// Init picks the language from EnvLang or, without it, the OS settings.
+// Call it once at startup, before any menu or dialog is built.
+func Init() {
+	code := os.Getenv(EnvLang)
+	if code == "" {
+		code = systemLocale()
+	}
+	SetLang(code)
+}
+
+// SetLang switches to the language of a locale such as "ru", "ru_RU.UTF-8"
+// or "en-US". Languages without a catalog give English.
+func SetLang(locale string) {
+	code := normalize(locale)
+	if _, ok := catalogs[code]; !ok {
+		code = English
+	}
+	lang.Store(code)
+}
+
+// Lang returns the active language code.
+func Lang() string {
+	if code, ok := lang.Load().(string); ok {
+		return code
+	}
+	return English
+}
+
+// T returns the translation of key, or key itself when there is none.
+func T(key string) string {
+	if s, ok := catalogs[Lang()][key]; ok {
+		return s
+	}
+	return key
+}
+
+// Tf translates the format key and fills it in like fmt.Sprintf.
+func Tf(key string, args ...any) string {
+	return fmt.Sprintf(T(key), args...)
+}
+
+// JSON returns the catalog of the active language as a JSON object, for
+// page scripts to look strings up the same way; English gives {}.
+func JSON() string {
+	b, err := json.Marshal(catalogs[Lang()])
+	if err != nil || string(b) == "null" {
+		return "{}"
+	}
+	return string(b)
+}
+
+// normalize reduces a locale to its lowercase language code: "ru_RU.UTF-8",
+// "ru-RU" and "RU" all give "ru". "C" and "POSIX" give "".
+func normalize(locale string) string {
+	code := strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(code, "_-.@"); i >= 0 {
+		code = code[:i]
+	}
+	if code == "c" || code == "posix" {
+		return ""
+	}
+	return code
+}
+
+// envLocale returns the locale from the POSIX variables, in the order they
+// take effect.
+func envLocale() string {
+	for _, v := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if s := os.Getenv(v); s != "" {
+			return s
+		}
+	}
+	return ""
+}
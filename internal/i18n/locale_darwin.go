@@ -0,0 +1,25 @@
+package i18n
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// systemLocale returns the POSIX locale when one is set, as it is in a
+// terminal, and otherwise the first language of System Settings: apps
+// started from Finder get no LANG.
+func systemLocale() string {
+	if s := envLocale(); s != "" {
+		return s
+	}
+	out, err := exec.Command("defaults", "read", "-g", "AppleLanguages").Output()
+	if err != nil {
+		return ""
+	}
+	// The output is a plist array: ( "ru-RU", "en-US" ).
+	fields := strings.FieldsFunc(string(out), func(r rune) bool { return strings.ContainsRune("(),\"\n\t ", r) })
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
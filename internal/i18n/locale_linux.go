@@ -0,0 +1,6 @@
+package i18n
+
+// systemLocale returns the locale from the POSIX variables.
+func systemLocale() string {
+	return envLocale()
+}
@@ -0,0 +1,12 @@
+package i18n
+
+import "golang.org/x/sys/windows"
+
+// systemLocale returns the user's first display language, falling back to
+// the POSIX variables some shells set.
+func systemLocale() string {
+	if langs, err := windows.GetUserPreferredUILanguages(windows.MUI_LANGUAGE_NAME); err == nil && len(langs) > 0 {
+		return langs[0]
+	}
+	return envLocale()
+}
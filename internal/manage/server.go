@@ -1,10 +1,15 @@
 package manage
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
 	"io"
 	"log"
+	"mime"
 	"mime/multipart"
 	"net"
 	"net/http"
@@ -12,7 +17,6 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -20,10 +24,12 @@ import (
 
 	"github.com/Ameight/systray-queue-app/internal/autostart"
 	"github.com/Ameight/systray-queue-app/internal/hotkeys"
+	"github.com/Ameight/systray-queue-app/internal/i18n"
 	"github.com/Ameight/systray-queue-app/internal/queue"
 	"github.com/Ameight/systray-queue-app/internal/ui"
 	"github.com/Ameight/systray-queue-app/internal/updater"
 	"github.com/Ameight/systray-queue-app/internal/util"
+	"github.com/Ameight/systray-queue-app/internal/webhook"
 )
 
 type Server struct {
@@ -34,6 +40,7 @@ type Server struct {
 	once sync.Once
 	url  string
 	err  error
+	srv  *http.Server // set by start
 
 	reloadHotkeys func() error
 
@@ -47,6 +54,17 @@ func New(q *queue.TaskQueue, baseDir string, favicon []byte) *Server {
 	return &Server{q: q, baseDir: baseDir, favicon: favicon}
 }
 
+// tr translates key with i18n.T and escapes it for HTML. Page scripts use
+// the T and Tf that ui.RenderPage defines instead.
+func tr(key string) string {
+	return html.EscapeString(i18n.T(key))
+}
+
+// trf is tr for a format key; args are plain text, escaped with the rest.
+func trf(key string, args ...any) string {
+	return html.EscapeString(i18n.Tf(key, args...))
+}
+
 // SetUpdateInfo stores the result of an update check for use by the settings page.
 func (s *Server) SetUpdateInfo(info *updater.UpdateInfo, err error) {
 	s.updateMu.Lock()
@@ -76,6 +94,18 @@ func (s *Server) URL() (string, error) {
 	return s.url, s.err
 }
 
+// Shutdown stops the server, waiting until ctx is done for open requests to
+// finish. Later calls to URL fail instead of starting it again.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.once.Do(func() {
+		s.err = http.ErrServerClosed
+	})
+	if s.srv == nil {
+		return nil
+	}
+	return s.srv.Shutdown(ctx)
+}
+
 func (s *Server) start() (string, error) {
 	ln, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
@@ -90,8 +120,10 @@ func (s *Server) start() (string, error) {
 	mux.HandleFunc("/add", s.handleAdd)
 	mux.HandleFunc("/add_submit", s.handleAddSubmit)
 	mux.HandleFunc("/view", s.handleView)
-	mux.HandleFunc("/action", s.handleAction)
+	mux.HandleFunc("/focus", s.handleFocus)
+	mux.HandleFunc("/focus/task", s.handleFocusTask)
 	mux.HandleFunc("/attachment", s.handleAttachment)
+	mux.HandleFunc("/attachment_open", s.handleAttachmentOpen)
 	mux.HandleFunc("/settings", s.handleSettings)
 	mux.HandleFunc("/settings/save", s.handleSettingsSave)
 	mux.HandleFunc("/transcribe", s.handleTranscribe)
@@ -99,10 +131,16 @@ func (s *Server) start() (string, error) {
 	mux.HandleFunc("/task_raw", s.handleTaskRaw)
 	mux.HandleFunc("/task_update", s.handleTaskUpdate)
 	mux.HandleFunc("/task_action", s.handleTaskAction)
+	mux.HandleFunc("/task_checklist", s.handleTaskChecklist)
 	mux.HandleFunc("/attachment_upload", s.handleAttachmentUpload)
+	mux.HandleFunc("/tag", s.handleTag)
+	mux.HandleFunc("/search", s.handleSearch)
+	mux.HandleFunc("/list", s.handleList)
 	mux.HandleFunc("/history", s.handleHistory)
 	mux.HandleFunc("/history/delete", s.handleHistoryDelete)
+	mux.HandleFunc("/history/requeue", s.handleHistoryRequeue)
 	mux.HandleFunc("/history/clear", s.handleHistoryClear)
+	mux.HandleFunc("/stats", s.handleStats)
 	mux.HandleFunc("/update/check", s.handleUpdateCheck)
 	mux.HandleFunc("/update/install", s.handleUpdateInstall)
 
@@ -110,7 +148,12 @@ func (s *Server) start() (string, error) {
 		Handler:           mux,
 		ReadHeaderTimeout: 5 * time.Second,
 	}
-	go func() { _ = srv.Serve(ln) }()
+	s.srv = srv
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("[manage] %v", err)
+		}
+	}()
 	cfg, _, _ := hotkeys.LoadOrCreate(s.baseDir)
 	go preloadWhisperModel(cfg.IsWhisperEnabled())
 
@@ -143,22 +186,20 @@ func (s *Server) handleReorder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	var req struct {
-		Order []int `json:"order"`
+		IDs []string `json:"ids"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "bad json", http.StatusBadRequest)
 		return
 	}
-	ord := append([]int(nil), req.Order...)
-	sort.Ints(ord)
-	for i := range ord {
-		if ord[i] != i {
-			http.Error(w, "bad permutation", http.StatusBadRequest)
-			return
+	// By ID: rejected unless it is a permutation of the queue as it is now,
+	// so a page rendered before another change cannot scramble the order.
+	if err := s.q.ReorderByIDs(req.IDs); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, queue.ErrStaleOrder) {
+			status = http.StatusConflict
 		}
-	}
-	if err := s.q.ReorderByIndices(req.Order); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), status)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
@@ -171,7 +212,7 @@ func (s *Server) handleAdd(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	cfg, _, _ := hotkeys.LoadOrCreate(s.baseDir)
-	page := ui.RenderPage("Add task", renderAddHTML(cfg.IsWhisperEnabled()))
+	page := ui.RenderPage(i18n.T("Add task"), renderAddHTML(cfg.IsWhisperEnabled(), s.q.GetAll()))
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	io.WriteString(w, page)
 }
@@ -187,80 +228,443 @@ func (s *Server) handleAddSubmit(w http.ResponseWriter, r *http.Request) {
 	}
 	text := strings.TrimSpace(r.FormValue("text"))
 
-	var attachmentPath string
-	var attachmentType queue.AttachmentType = queue.AttachmentNone
-
-	file, hdr, err := r.FormFile("attachment")
-	if err == nil {
-		defer file.Close()
-		attachmentPath, attachmentType, err = s.saveUploadedAttachment(file, hdr)
+	// Validate every upload before writing any of them to disk.
+	cfg, _, _ := hotkeys.LoadOrCreate(s.baseDir)
+	uploads := r.MultipartForm.File["attachment"]
+	for _, hdr := range uploads {
+		if err := validateUpload(hdr, cfg.MaxAttachmentSize()); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	var attachments []queue.Attachment
+	for _, hdr := range uploads {
+		a, err := s.saveUploadedAttachment(hdr)
 		if err != nil {
+			for _, saved := range attachments {
+				_ = os.Remove(saved.Path)
+			}
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
+		attachments = append(attachments, a)
 	}
 
-	// If no manual attachment but a voice recording was transcribed, use it.
-	if attachmentPath == "" {
-		voiceFn := strings.TrimSpace(r.FormValue("voice_attachment"))
-		if voiceFn != "" && !strings.Contains(voiceFn, "/") && !strings.Contains(voiceFn, "\\") && !strings.Contains(voiceFn, "..") {
-			candidate := filepath.Join(s.q.AttachmentsDir(), voiceFn)
-			inside, err := util.IsPathInsideDir(candidate, s.q.AttachmentsDir())
-			if err == nil && inside {
-				if _, err := os.Stat(candidate); err == nil {
-					attachmentPath = candidate
-					attachmentType = queue.AttachmentAudio
-				}
+	// A recorded voice note is kept as an extra audio attachment.
+	voiceFn := strings.TrimSpace(r.FormValue("voice_attachment"))
+	if voiceFn != "" && !strings.Contains(voiceFn, "/") && !strings.Contains(voiceFn, "\\") && !strings.Contains(voiceFn, "..") {
+		candidate := filepath.Join(s.q.AttachmentsDir(), voiceFn)
+		inside, err := util.IsPathInsideDir(candidate, s.q.AttachmentsDir())
+		if err == nil && inside {
+			if _, err := os.Stat(candidate); err == nil {
+				attachments = append(attachments, queue.Attachment{Path: candidate, Type: queue.AttachmentAudio})
 			}
 		}
 	}
 
 	// Text is required only when there is no attachment.
-	if text == "" && attachmentPath == "" {
+	if text == "" && len(attachments) == 0 {
 		http.Error(w, "text or attachment required", http.StatusBadRequest)
 		return
 	}
 
-	t := queue.Task{
-		ID:             strconv.FormatInt(time.Now().UnixNano(), 10),
-		Text:           text,
-		CreatedAt:      time.Now(),
-		AttachmentPath: attachmentPath,
-		AttachmentType: attachmentType,
+	var due *time.Time
+	if raw := strings.TrimSpace(r.FormValue("due_date")); raw != "" {
+		d, err := time.ParseInLocation(dueDateInputLayout, raw, time.Local)
+		if err != nil {
+			http.Error(w, "bad due date: "+raw, http.StatusBadRequest)
+			return
+		}
+		due = &d
 	}
-	if err := s.q.Enqueue(t); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+	// Options before the due date are dropped without one, or when already past.
+	var reminders []queue.Reminder
+	for _, raw := range r.MultipartForm.Value["reminder"] {
+		i, err := strconv.Atoi(raw)
+		if err != nil || i < 0 || i >= len(queue.ReminderOptions) {
+			http.Error(w, "bad reminder: "+raw, http.StatusBadRequest)
+			return
+		}
+		if at, ok := queue.ReminderOptions[i].At(time.Now(), due); ok {
+			reminders = append(reminders, queue.Reminder{At: at})
+		}
+	}
+
+	prio, _ := strconv.Atoi(r.FormValue("priority"))
+
+	estimate, err := queue.ParseEstimate(r.FormValue("estimate"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	recur := r.FormValue("recurrence")
+	if !validRecurrence(recur) {
+		http.Error(w, "bad recurrence: "+recur, http.StatusBadRequest)
+		return
+	}
+
+	color := r.FormValue("color")
+	if !queue.ValidColor(color) {
+		http.Error(w, "bad color: "+color, http.StatusBadRequest)
+		return
+	}
+
+	t := queue.Task{
+		ID:              strconv.FormatInt(time.Now().UnixNano(), 10),
+		Text:            text,
+		Notes:           strings.TrimSpace(r.FormValue("notes")),
+		CreatedAt:       time.Now(),
+		DueDate:         due,
+		Priority:        prio,
+		Attachments:     attachments,
+		Tags:            queue.ParseTags(r.FormValue("tags")),
+		Recurrence:      recur,
+		EstimateMinutes: estimate,
+		BlockedBy:       r.MultipartForm.Value["blocked_by"],
+		Color:           color,
+		Checklist:       queue.ParseChecklist(r.FormValue("checklist")),
+		Reminders:       reminders,
+	}
+	if err := s.q.EnqueueWithPriority(t); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, queue.ErrQueueFull):
+			status = http.StatusTooManyRequests
+		case errors.Is(err, queue.ErrDependencyCycle):
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
 		return
 	}
 
 	http.Redirect(w, r, "/view", http.StatusSeeOther)
 }
 
-func (s *Server) saveUploadedAttachment(file multipart.File, hdr *multipart.FileHeader) (string, queue.AttachmentType, error) {
-	name := hdr.Filename
-	ext := strings.ToLower(filepath.Ext(name))
-	var t queue.AttachmentType
-	switch ext {
-	case ".png", ".jpg", ".jpeg", ".webp", ".gif":
-		t = queue.AttachmentImage
-	case ".m4a", ".mp3", ".wav", ".ogg":
-		t = queue.AttachmentAudio
-	default:
-		return "", queue.AttachmentNone, fmt.Errorf("unsupported attachment type: %s", ext)
+// dueDateInputLayout matches the value format of <input type="datetime-local">.
+const dueDateInputLayout = "2006-01-02T15:04"
+
+// renderDueHTML returns muted due-date, estimate and next-reminder lines for
+// a task, or "" if it has none of them.
+func renderDueHTML(t queue.Task) string {
+	est := ""
+	if t.EstimateMinutes > 0 {
+		est = `<p class="muted">` + trf("Estimate: %s", queue.FormatEstimate(t.EstimateMinutes)) + `</p>`
+	}
+	if at, ok := t.NextReminder(); ok {
+		est += `<p class="muted">⏰ ` + trf("Reminder: %s", at.Local().Format("02 Jan 2006, 15:04")) + `</p>`
+	}
+	if t.DueDate == nil {
+		return est
+	}
+	label := trf("Due: %s", t.DueDate.Local().Format("02 Jan 2006, 15:04"))
+	if t.IsOverdue(time.Now()) {
+		return `<p class="muted" style="color:#c00">` + label + ` · ` + tr("overdue") + `</p>` + est
 	}
+	return `<p class="muted">` + label + `</p>` + est
+}
 
-	fn := fmt.Sprintf("%d%s", time.Now().UnixNano(), ext)
-	path := filepath.Join(s.q.AttachmentsDir(), fn)
+func renderPriorityOptions() string {
+	var b strings.Builder
+	for _, p := range ui.PriorityLabels {
+		b.WriteString(fmt.Sprintf(`<option value="%d">%s</option>`, p.Priority, tr(p.Label)))
+	}
+	return b.String()
+}
+
+func renderColorOptions() string {
+	var b strings.Builder
+	b.WriteString(`<option value="">` + tr("None") + `</option>`)
+	for _, c := range queue.Palette {
+		b.WriteString(fmt.Sprintf(`<option value="%s" style="color:%s">● %s</option>`, c.Name, c.Hex, tr(strings.ToUpper(c.Name[:1])+c.Name[1:])))
+	}
+	return b.String()
+}
+
+// colorStyle returns an inline CSS left border in the task's color, or "" if
+// it has none. Only Palette values are emitted.
+func colorStyle(t queue.Task, width int) string {
+	hex, ok := queue.ColorHex(t.Color)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("border-left:%dpx solid %s;padding-left:10px;", width, hex)
+}
+
+func renderRecurrenceOptions() string {
+	var b strings.Builder
+	for _, r := range ui.RecurrenceLabels {
+		b.WriteString(fmt.Sprintf(`<option value="%s">%s</option>`, r.Recurrence, tr(r.Label)))
+	}
+	return b.String()
+}
+
+// renderReminderOptions lists queue.ReminderOptions as checkboxes; the form
+// sends the indices of the checked ones as reminder.
+func renderReminderOptions() string {
+	var b strings.Builder
+	for i, o := range queue.ReminderOptions {
+		b.WriteString(fmt.Sprintf(`<label style="margin-right:12px;white-space:nowrap"><input type="checkbox" name="reminder" value="%d"> %s</label>`, i, tr(o.Label)))
+	}
+	return b.String()
+}
+
+// renderBlockerSelect lists the queued tasks the new task can wait for; the
+// form sends the selected IDs as blocked_by.
+func renderBlockerSelect(tasks []queue.Task) string {
+	if len(tasks) == 0 {
+		return ""
+	}
+	esc := func(s string) string {
+		return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&#39;").Replace(s)
+	}
+	var b strings.Builder
+	b.WriteString(`<p><label>` + tr("Blocked by (optional, Ctrl/⌘-click for several):") + `<br><select name="blocked_by" multiple size="` + strconv.Itoa(min(len(tasks), 5)) + `" style="min-width:320px">`)
+	for i, t := range tasks {
+		b.WriteString(fmt.Sprintf(`<option value="%s">%d. %s</option>`, esc(t.ID), i+1, esc(firstLine(t.Text))))
+	}
+	b.WriteString(`</select></label></p>`)
+	return b.String()
+}
+
+func firstLine(text string) string {
+	if idx := strings.IndexByte(text, '\n'); idx >= 0 {
+		text = text[:idx]
+	}
+	return strings.TrimSpace(text)
+}
+
+func validRecurrence(r string) bool {
+	for _, known := range ui.RecurrenceLabels {
+		if known.Recurrence == r {
+			return true
+		}
+	}
+	return false
+}
+
+// recurrenceMarker returns "↻ " for recurring tasks, for list labels.
+func recurrenceMarker(r string) string {
+	if r == queue.RecurNone {
+		return ""
+	}
+	return "↻ "
+}
+
+// pinMarker returns "📌 " for the pinned task, for list labels.
+func pinMarker(t queue.Task) string {
+	if !t.Pinned {
+		return ""
+	}
+	return "📌 "
+}
+
+// priorityMarker returns "!" repeated once per priority level, for list labels.
+func priorityMarker(p int) string {
+	if p <= 0 {
+		return ""
+	}
+	return strings.Repeat("!", p) + " "
+}
+
+// renderTagsHTML renders tags as small links to the /tag filter view.
+func renderTagsHTML(tags []string) string {
+	var b strings.Builder
+	for _, tag := range tags {
+		b.WriteString(fmt.Sprintf(` <a class="tag" href="/tag?name=%s" style="font-size:12px;color:#1a73e8;text-decoration:none">#%s</a>`,
+			url.QueryEscape(tag), strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&#39;").Replace(tag)))
+	}
+	return b.String()
+}
+
+// validateUpload rejects unsupported extensions and files over limit bytes.
+func validateUpload(hdr *multipart.FileHeader, limit int64) error {
+	if _, ok := queue.AttachmentTypeForExt(strings.ToLower(filepath.Ext(hdr.Filename))); !ok {
+		return fmt.Errorf("%s: unsupported attachment type", hdr.Filename)
+	}
+	if hdr.Size > limit {
+		return fmt.Errorf("%s is %d MB, the limit is %d MB", hdr.Filename, hdr.Size>>20, limit>>20)
+	}
+	return nil
+}
+
+func (s *Server) saveUploadedAttachment(hdr *multipart.FileHeader) (queue.Attachment, error) {
+	ext := strings.ToLower(filepath.Ext(hdr.Filename))
+	t, ok := queue.AttachmentTypeForExt(ext)
+	if !ok {
+		return queue.Attachment{}, fmt.Errorf("unsupported attachment type: %s", ext)
+	}
+
+	file, err := hdr.Open()
+	if err != nil {
+		return queue.Attachment{}, err
+	}
+	defer file.Close()
+
+	path := filepath.Join(s.q.AttachmentsDir(), queue.StagedName(hdr.Filename))
 	out, err := os.Create(path)
 	if err != nil {
-		return "", queue.AttachmentNone, err
+		return queue.Attachment{}, err
 	}
 	defer out.Close()
 	if _, err := io.Copy(out, file); err != nil {
-		return "", queue.AttachmentNone, err
+		_ = os.Remove(path)
+		return queue.Attachment{}, err
 	}
 	_ = out.Sync()
-	return path, t, nil
+	return queue.Attachment{Path: path, Type: t}, nil
+}
+
+// attachmentsMarkdown returns Markdown/HTML that embeds every attachment via
+// the /attachment endpoint so the browser can load them. Attachments whose
+// file is gone get a note instead of a broken embed; ones that cannot be
+// previewed get an Open file link as well, see attachmentLinkJS.
+func (s *Server) attachmentsMarkdown(t queue.Task) string {
+	var b strings.Builder
+	for i, a := range t.Attachments {
+		if a.Path == "" {
+			continue
+		}
+		if !queue.AttachmentExists(a) {
+			b.WriteString("\n\n<p>⚠️ " + trf("Attachment %s is missing: the file was moved or deleted.", filepath.Base(a.Path)) + "</p>\n")
+			continue
+		}
+		name := url.QueryEscape(s.q.AttachmentName(a.Path))
+		switch a.Type {
+		case queue.AttachmentImage:
+			b.WriteString("\n\n![attachment](/attachment?name=" + name + ")\n")
+		case queue.AttachmentAudio:
+			b.WriteString("\n\n<audio controls src=\"/attachment?name=" + name + "\"></audio>\n")
+		case queue.AttachmentVideo:
+			b.WriteString("\n\n<video controls src=\"/attachment?name=" + name + "\"></video>\n")
+		case queue.AttachmentFile:
+			b.WriteString("\n\n<p>📄 <a href=\"/attachment?name=" + name + "\" download>" + html.EscapeString(filepath.Base(a.Path)) + "</a> · " + openFileLink(t.ID, i) + "</p>\n")
+		default:
+			// A type from a newer version: still show that the file is there.
+			// /attachment serves it only if its extension is allowed; the
+			// Open button works either way.
+			b.WriteString("\n\n<p>📎 " + tr("Attachment:") + " <a href=\"/attachment?name=" + name + "\" download>" + html.EscapeString(filepath.Base(a.Path)) + "</a> (" + trf("unknown type %s", a.Type) + ") · " + openFileLink(t.ID, i) + "</p>\n")
+		}
+		if label := a.MetaLabel(); label != "" {
+			b.WriteString("\n\n<p><small>" + label + "</small></p>\n")
+		}
+	}
+	return b.String()
+}
+
+// openFileLink returns an Open file link for attachment i of the task with
+// id. The page's attachmentLinkJS turns the click into /attachment_open; the
+// href is a plain fragment so it survives sanitizing.
+func openFileLink(id string, i int) string {
+	return fmt.Sprintf(`<a href="#open-attachment/%s/%d">%s</a>`, url.PathEscape(id), i, tr("Open file"))
+}
+
+// attachmentLinkJS opens the attachment behind a link made by openFileLink
+// in the default app. Browsers do not follow file:// links from the page,
+// and files of types they cannot show would only be downloaded.
+const attachmentLinkJS = `
+document.addEventListener('click', async e => {
+  const a = e.target.closest('a[href^="#open-attachment/"]');
+  if(!a) return;
+  e.preventDefault();
+  const [, id, index] = a.getAttribute('href').split('/');
+  const res = await fetch('/attachment_open', {method:'POST', headers:{'Content-Type':'application/json'}, body: JSON.stringify({id: decodeURIComponent(id), index: parseInt(index, 10)})});
+  if(!res.ok) alert(await res.text());
+});
+`
+
+// renderTaskBody renders the task text, its notes and then the attachments,
+// embedded via the /attachment endpoint so the browser can load them.
+func (s *Server) renderTaskBody(t queue.Task) (string, error) {
+	// Pass no attachments so RenderTaskHTML does not add its own file:// audio tags.
+	frag, err := ui.RenderTaskHTML(queue.Task{ID: t.ID, Text: t.Text, Notes: t.Notes, CreatedAt: t.CreatedAt})
+	if err != nil {
+		return "", err
+	}
+	frag += renderChecklistHTML(t)
+	md := s.attachmentsMarkdown(t)
+	if md == "" {
+		return frag, nil
+	}
+	att, err := ui.RenderTaskHTML(queue.Task{ID: t.ID, Text: md})
+	if err != nil {
+		return "", err
+	}
+	return frag + att, nil
+}
+
+// renderChecklistHTML returns the task's checklist as checkboxes that call
+// checklistJS, or "" if it has none.
+func renderChecklistHTML(t queue.Task) string {
+	if len(t.Checklist) == 0 {
+		return ""
+	}
+	done, total := t.ChecklistProgress()
+	var b strings.Builder
+	fmt.Fprintf(&b, `<div class="checklist" style="margin-top:12px"><div class="muted">%s <span class="checklist-progress">%d/%d</span></div>`, tr("Checklist"), done, total)
+	for i, it := range t.Checklist {
+		checked := ""
+		if it.Done {
+			checked = " checked"
+		}
+		fmt.Fprintf(&b, `<label style="display:flex;align-items:center;gap:8px;margin:6px 0;cursor:pointer"><input type="checkbox" data-id="%s" data-index="%d" onchange="toggleChecklistItem(this)"%s style="width:16px;height:16px">%s</label>`,
+			html.EscapeString(t.ID), i, checked, html.EscapeString(it.Text))
+	}
+	b.WriteString(`</div>`)
+	return b.String()
+}
+
+// checklistJS defines toggleChecklistItem for the checkboxes of
+// renderChecklistHTML. Pages that show a task body include it.
+const checklistJS = `
+async function toggleChecklistItem(box){
+  const res = await fetch('/task_checklist', {method:'POST', headers:{'Content-Type':'application/json'}, body: JSON.stringify({id: box.dataset.id, index: parseInt(box.dataset.index, 10)})});
+  if(!res.ok){ box.checked = !box.checked; alert(await res.text()); return; }
+  const data = await res.json();
+  // The last item completed the task; show what comes next.
+  if(data.completed){ location.reload(); return; }
+  const progress = box.closest('.checklist').querySelector('.checklist-progress');
+  if(progress) progress.textContent = data.done + '/' + data.total;
+}
+`
+
+// taskPageJS defines openAttachment and copyText for the pages of a single
+// task, which set taskID and taskText before it. It is passed to
+// fmt.Sprintf as an argument, not concatenated into the format, so the %s
+// of the Tf key below reaches the page as written.
+const taskPageJS = `
+async function openAttachment(i){
+  const res = await fetch('/attachment_open', {method:'POST', headers:{'Content-Type':'application/json'}, body: JSON.stringify({id: taskID, index: i})});
+  if(!res.ok) alert(await res.text());
+}
+async function copyText(){
+  const btn = document.getElementById('copy-btn');
+  try {
+    await navigator.clipboard.writeText(taskText);
+  } catch (e) {
+    // Fallback for browsers that do not expose the async clipboard API.
+    const ta = document.createElement('textarea');
+    ta.value = taskText;
+    document.body.appendChild(ta);
+    ta.select();
+    const ok = document.execCommand('copy');
+    ta.remove();
+    if(!ok){ alert(Tf('Could not copy: %s', e)); return; }
+  }
+  btn.textContent = T('Copied ✓');
+  setTimeout(() => { btn.textContent = T('Copy text'); }, 1500);
+}
+`
+
+// checklistMarker returns "☑ done/total " for tasks with a checklist, for
+// list labels.
+func checklistMarker(t queue.Task) string {
+	done, total := t.ChecklistProgress()
+	if total == 0 {
+		return ""
+	}
+	return fmt.Sprintf("☑ %d/%d ", done, total)
 }
 
 func (s *Server) handleView(w http.ResponseWriter, r *http.Request) {
@@ -268,88 +672,211 @@ func (s *Server) handleView(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	// ?index=N previews the task at that position (0 is the head) read-only,
+	// without skipping anything.
+	if raw := r.URL.Query().Get("index"); raw != "" {
+		s.handlePreview(w, raw)
+		return
+	}
 	t, ok := s.q.Peek()
 	if !ok {
-		page := ui.RenderPage("Queue", `<h1>Queue</h1><p class="muted">Queue is empty.</p><div class="row"><button onclick="location.href='/add'">Add task</button><button onclick="location.href='/'">Manage order</button></div>`)
+		page := ui.RenderPage(i18n.T("Queue"), `<h1>`+tr("Queue")+`</h1><p class="muted">`+tr("Queue is empty.")+`</p><div class="row"><button onclick="location.href='/add'">`+tr("Add task")+`</button><button onclick="location.href='/'">`+tr("Manage order")+`</button></div>`)
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
 		io.WriteString(w, page)
 		return
 	}
 
-	// Embed image/audio via /attachment endpoint so the browser can load them.
-	taskText := t.Text
-	if t.AttachmentPath != "" {
-		name := filepath.Base(t.AttachmentPath)
-		switch t.AttachmentType {
-		case queue.AttachmentImage:
-			taskText += "\n\n![attachment](/attachment?name=" + url.QueryEscape(name) + ")\n"
-		case queue.AttachmentAudio:
-			taskText += "\n\n<audio controls src=\"/attachment?name=" + url.QueryEscape(name) + "\"></audio>\n"
-		}
-	}
-
-	// Pass AttachmentNone so RenderTaskHTML does not add its own file:// audio tag.
-	frag, err := ui.RenderTaskHTML(queue.Task{
-		ID:        t.ID,
-		Text:      taskText,
-		CreatedAt: t.CreatedAt,
-	})
+	frag, err := s.renderTaskBody(t)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	body := fmt.Sprintf(`<h1>Current task</h1>
+	// json.Marshal escapes <, > and & so the values cannot close the script tag.
+	textJS, _ := json.Marshal(t.Text)
+	idJS, _ := json.Marshal(t.ID)
+	body := fmt.Sprintf(`<h1 style="%s">%s`+tr("Current task")+`</h1>
 <div class="row">
-  <button onclick="doAction('done')">Done</button>
-  <button onclick="doAction('skip')">Skip</button>
-  <button onclick="location.href='/add'">Add</button>
-  <button onclick="location.href='/'">Manage order</button>
-  <button onclick="location.href='/history'">History</button>
+  <button onclick="doAction('done')">`+tr("Done")+`</button>
+  <button onclick="doAction('skip')">`+tr("Skip")+`</button>
+  <button id="copy-btn" onclick="copyText()">`+tr("Copy text")+`</button>
+  <button onclick="location.href='/add'">`+tr("Add")+`</button>
+  <button onclick="location.href='/'">`+tr("Manage order")+`</button>
+  <button onclick="location.href='/history'">`+tr("History")+`</button>
 </div>
-<div class="card">%s</div>
+<p class="muted">`+tr("Enter — Done, S — Skip")+`</p>
+%s<div class="card">%s</div>
+%s
 <script>
+const taskText = %s;
+const taskID = %s;
+%s`+checklistJS+attachmentLinkJS+`let busy = false;
 async function doAction(a){
-  const res = await fetch('/action', {method:'POST', headers:{'Content-Type':'application/json'}, body: JSON.stringify({action:a})});
-  if(!res.ok){ alert(await res.text()); return; }
+  if(busy) return;
+  busy = true;
+  // By ID, so the task acted on is the one on the page.
+  const res = await fetch('/task_action', {method:'POST', headers:{'Content-Type':'application/json'}, body: JSON.stringify({id: taskID, action: a})});
+  if(!res.ok){ busy = false; alert(await res.text()); return; }
   location.reload();
 }
-</script>`, frag)
+document.addEventListener('keydown', e => {
+  if(e.repeat || e.ctrlKey || e.metaKey || e.altKey || e.shiftKey) return;
+  const tag = (e.target.tagName || '').toLowerCase();
+  if(tag === 'input' || tag === 'textarea' || tag === 'select' || tag === 'button' || tag === 'a' || e.target.isContentEditable) return;
+  // S by its key position, so it works in any layout; Esc is left alone,
+  // as it should never change the queue.
+  if(e.key === 'Enter'){ e.preventDefault(); doAction('done'); }
+  else if(e.code === 'KeyS'){ e.preventDefault(); doAction('skip'); }
+});
+</script>`, colorStyle(t, 6), pinMarker(t), renderCreatedHTML(t, time.Now())+renderDueHTML(t), frag, renderOpenButtons(t), textJS, idJS, taskPageJS)
 
-	page := ui.RenderPage("Current task", body)
+	page := ui.RenderPage(i18n.T("Current task"), body)
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	io.WriteString(w, page)
 }
 
-func (s *Server) handleAction(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+// handlePreview shows the task at queue position raw like /view does, but
+// without Done/Skip: the task may not be the current one, and looking at it
+// must not change the queue.
+func (s *Server) handlePreview(w http.ResponseWriter, raw string) {
+	i, err := strconv.Atoi(raw)
+	if err != nil {
+		http.Error(w, "bad index: "+raw, http.StatusBadRequest)
 		return
 	}
-	var req struct {
-		Action string `json:"action"`
+	t, ok := s.q.At(i)
+	if !ok {
+		http.Error(w, fmt.Sprintf("no task at position %d", i+1), http.StatusNotFound)
+		return
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "bad json", http.StatusBadRequest)
+	frag, err := s.renderTaskBody(t)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	switch req.Action {
-	case "skip":
-		if err := s.q.Skip(); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-	case "done":
-		if _, err := s.q.Complete(); err != nil {
+	textJS, _ := json.Marshal(t.Text)
+	idJS, _ := json.Marshal(t.ID)
+	body := fmt.Sprintf(`<h1 style="%s">%s</h1>
+<div class="row">
+  <button onclick="location.href='/list'">`+tr("Back to queue")+`</button>
+  <button id="copy-btn" onclick="copyText()">`+tr("Copy text")+`</button>
+  <button onclick="location.href='/view'">`+tr("Current task")+`</button>
+</div>
+%s<div class="card">%s</div>
+%s
+<script>
+const taskText = %s;
+const taskID = %s;
+%s`+checklistJS+attachmentLinkJS+`</script>`, colorStyle(t, 6), trf("Task #%d", i+1), renderCreatedHTML(t, time.Now())+renderDueHTML(t), frag, renderOpenButtons(t), textJS, idJS, taskPageJS)
+
+	page := ui.RenderPage(i18n.Tf("Task #%d", i+1), body)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	io.WriteString(w, page)
+}
+
+// handleFocus is focus mode: the current task alone with Done and Skip, in a
+// page that loads the next task in place and closes itself when the queue
+// runs out. It also polls, so changes made from the tray show up.
+func (s *Server) handleFocus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body := `<style>
+  body{max-width:640px;padding:12px 16px}
+  #focus-empty{display:none}
+</style>
+<div class="row">
+  <button onclick="doAction('done')">` + tr("Done") + `</button>
+  <button onclick="doAction('skip')">` + tr("Skip") + `</button>
+  <span class="muted">` + tr("Enter — Done, S — Skip") + `</span>
+</div>
+<div id="focus-task"><div class="card" id="focus-body"></div></div>
+<p class="muted" id="focus-empty">` + tr("Queue is empty. You can close this window.") + `</p>
+<script>
+let currentID = null;
+let busy = false;
+async function load(){
+  const res = await fetch('/focus/task', {cache: 'no-store'});
+  if(!res.ok) return;
+  const data = await res.json();
+  if(!data.id){
+    currentID = null;
+    document.getElementById('focus-task').style.display = 'none';
+    document.getElementById('focus-empty').style.display = 'block';
+    document.title = T('Focus');
+    // Browsers only let a script close windows it opened itself; otherwise
+    // the message above stays.
+    window.close();
+    return;
+  }
+  document.getElementById('focus-task').style.display = '';
+  document.getElementById('focus-empty').style.display = 'none';
+  if(data.id === currentID) return;
+  currentID = data.id;
+  document.getElementById('focus-body').innerHTML = data.html;
+  document.title = data.title;
+}
+async function openAttachment(i){
+  const res = await fetch('/attachment_open', {method:'POST', headers:{'Content-Type':'application/json'}, body: JSON.stringify({id: currentID, index: i})});
+  if(!res.ok) alert(await res.text());
+}
+` + checklistJS + attachmentLinkJS + `async function doAction(a){
+  if(busy || !currentID) return;
+  busy = true;
+  try {
+    // Actions go by ID, so a task that became current since the last poll
+    // is never completed or skipped unseen.
+    const res = await fetch('/task_action', {method:'POST', headers:{'Content-Type':'application/json'}, body: JSON.stringify({id: currentID, action: a})});
+    if(!res.ok){ alert(await res.text()); return; }
+    currentID = null;
+    await load();
+  } finally {
+    busy = false;
+  }
+}
+document.addEventListener('keydown', e => {
+  if(e.repeat || e.ctrlKey || e.metaKey || e.altKey || e.shiftKey) return;
+  const tag = (e.target.tagName || '').toLowerCase();
+  if(tag === 'input' || tag === 'textarea' || tag === 'select' || tag === 'button' || tag === 'a' || e.target.isContentEditable) return;
+  // S by its key position, so it works in any layout; Esc is left alone,
+  // as it should never change the queue.
+  if(e.key === 'Enter'){ e.preventDefault(); doAction('done'); }
+  else if(e.code === 'KeyS'){ e.preventDefault(); doAction('skip'); }
+});
+load();
+setInterval(() => { if(!busy) load(); }, 3000);
+</script>`
+	page := ui.RenderPage(i18n.T("Focus"), body)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	io.WriteString(w, page)
+}
+
+// handleFocusTask returns the current task for the focus page: its ID, a
+// title and the rendered body, or an empty ID when the queue is empty.
+func (s *Server) handleFocusTask(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var resp struct {
+		ID    string `json:"id"`
+		Title string `json:"title,omitempty"`
+		HTML  string `json:"html,omitempty"`
+	}
+	if t, ok := s.q.Peek(); ok {
+		frag, err := s.renderTaskBody(t)
+		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
-	default:
-		http.Error(w, "unknown action", http.StatusBadRequest)
-		return
+		resp.ID = t.ID
+		resp.Title = firstLine(t.Text)
+		resp.HTML = frag + renderOpenButtons(t)
 	}
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	io.WriteString(w, `{"ok":true}`)
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(resp)
 }
 
 // handleAttachment serves files from the attachments directory.
@@ -359,39 +886,134 @@ func (s *Server) handleAttachment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	name := r.URL.Query().Get("name")
-	if name == "" || strings.Contains(name, "/") || strings.Contains(name, "\\") || strings.Contains(name, "..") {
+	path, ok := s.q.AttachmentPath(name)
+	if !ok {
 		http.Error(w, "invalid name", http.StatusBadRequest)
 		return
 	}
-	path := filepath.Join(s.q.AttachmentsDir(), name)
+	// Only media and the types allowed in the settings are served, the latter
+	// only as downloads, so a stray .html or .svg in the folder can never run
+	// script in the manage UI's origin.
+	at, ok := queue.AttachmentTypeForExt(strings.ToLower(filepath.Ext(name)))
+	if !ok {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
 	inside, err := util.IsPathInsideDir(path, s.q.AttachmentsDir())
 	if err != nil || !inside {
 		http.Error(w, "not found", http.StatusNotFound)
 		return
 	}
-	http.ServeFile(w, r, path)
+	fi, err := os.Stat(path)
+	if err != nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	// Attachments may be stored encrypted; serve the decrypted bytes.
+	data, err := s.q.ReadAttachment(path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	if at == queue.AttachmentFile {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filepath.Base(path)}))
+	}
+	http.ServeContent(w, r, name, fi.ModTime(), bytes.NewReader(data))
 }
 
-func renderAddHTML(whisperEnabled bool) string {
+// handleAttachmentOpen opens attachment index of task id in the default app
+// of the OS, outside the browser.
+func (s *Server) handleAttachmentOpen(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		ID    string `json:"id"`
+		Index int    `json:"index"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad json", http.StatusBadRequest)
+		return
+	}
+	t, ok := s.q.GetByID(req.ID)
+	if !ok || req.Index < 0 || req.Index >= len(t.Attachments) {
+		http.Error(w, "attachment not found", http.StatusNotFound)
+		return
+	}
+	path, err := s.q.OpenablePath(t.Attachments[req.Index])
+	if errors.Is(err, queue.ErrAttachmentMissing) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := util.OpenWithSystem(path); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	io.WriteString(w, `{"ok":true}`)
+}
+
+// attachmentAccept is the accept attribute of attachment file inputs.
+func attachmentAccept() string {
+	accept := "image/*,audio/*,video/*"
+	for _, ext := range queue.AttachmentExts(queue.AttachmentFile) {
+		accept += "," + ext
+	}
+	return accept
+}
+
+// renderOpenButtons adds one "Open" button per attachment of t, handing the
+// file to the default app of the OS.
+func renderOpenButtons(t queue.Task) string {
+	if len(t.Attachments) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(`<div class="row">`)
+	for i, a := range t.Attachments {
+		b.WriteString(fmt.Sprintf(`<button onclick="openAttachment(%d)" title="%s">%s</button>`, i, tr("Open in the default app"), trf("Open %s", filepath.Base(a.Path))))
+	}
+	b.WriteString(`</div>`)
+	return b.String()
+}
+
+func renderAddHTML(whisperEnabled bool, queued []queue.Task) string {
 	whisperJS := "false"
 	if whisperEnabled {
 		whisperJS = "true"
 	}
 
-	return `<h1>Add task</h1>
+	return `<h1>` + tr("Add task") + `</h1>
 <form id="task-form" action="/add_submit" method="post" enctype="multipart/form-data">
   <div class="row">
-    <button type="submit">Save</button>
-    <button type="button" onclick="location.href='/view'">Cancel</button>
+    <button type="submit">` + tr("Save") + `</button>
+    <button type="button" onclick="location.href='/view'">` + tr("Cancel") + `</button>
   </div>
-  <p class="muted">Markdown supported. Paste image (Ctrl+V / ⌘V) to attach. You can also record a voice note.</p>
-  <p><textarea name="text" id="task-text" placeholder="Write task in Markdown..."></textarea></p>
-  <p><label>Attachment: <input type="file" name="attachment" id="attach-input" accept="image/*,audio/*" /></label>
+  <p class="muted">` + tr("Markdown supported. Paste image (Ctrl+V / ⌘V) to attach. You can also record a voice note.") + `</p>
+  <p><textarea name="text" id="task-text" placeholder="` + tr("Write task in Markdown...") + `"></textarea></p>
+  <p><textarea name="notes" placeholder="` + tr("Notes (optional, Markdown)") + `" style="min-height:80px"></textarea></p>
+  <p><textarea name="checklist" placeholder="` + tr("Checklist (optional), one item per line") + `" style="min-height:80px"></textarea></p>
+  <p><label>` + tr("Attachments:") + ` <input type="file" name="attachment" id="attach-input" accept="` + attachmentAccept() + `" multiple /></label>
      <span id="paste-hint" class="muted" style="margin-left:8px"></span></p>
+  <p><label>` + tr("Due date (optional):") + ` <input type="datetime-local" name="due_date" /></label>
+     <label style="margin-left:12px">` + tr("Priority:") + ` <select name="priority">` + renderPriorityOptions() + `</select></label>
+     <label style="margin-left:12px">` + tr("Repeat:") + ` <select name="recurrence">` + renderRecurrenceOptions() + `</select></label>
+     <label style="margin-left:12px">` + tr("Color:") + ` <select name="color">` + renderColorOptions() + `</select></label></p>
+  <p><label>` + tr("Tags:") + ` <input type="text" name="tags" placeholder="` + tr("work, home") + `" style="width:240px" /></label>
+     <label style="margin-left:12px">` + tr("Estimate:") + ` <input type="text" name="estimate" placeholder="` + tr("30 or 1h30m") + `" style="width:100px" /></label></p>
+  <p><span class="muted">` + tr("Remind:") + `</span> ` + renderReminderOptions() + `</p>` + renderBlockerSelect(queued) + `
   <input type="hidden" name="voice_attachment" id="voice-attachment-name" value="">
   <div style="margin-top:12px">
     <div class="row">
-      <button type="button" id="rec-btn">Record voice note</button>
+      <button type="button" id="rec-btn">` + tr("Record voice note") + `</button>
       <span id="rec-status" class="muted"></span>
     </div>
     <div id="rec-player" style="display:none;margin-top:8px"></div>
@@ -417,9 +1039,10 @@ func renderAddHTML(whisperEnabled bool) string {
     e.preventDefault();
     const file = imgItem.getAsFile();
     const dt = new DataTransfer();
+    [...attachInput.files].forEach(f => dt.items.add(f));
     dt.items.add(file);
     attachInput.files = dt.files;
-    pasteHint.textContent = 'Image pasted (' + file.type + ')';
+    pasteHint.textContent = Tf('%d file(s) attached, last pasted: %s', dt.files.length, file.type);
   });
 
   // ── Voice recording ───────────────────────────────────────────────────
@@ -436,7 +1059,7 @@ func renderAddHTML(whisperEnabled bool) string {
     try {
       stream = await navigator.mediaDevices.getUserMedia({ audio: true });
     } catch (e) {
-      recStatus.textContent = 'Microphone access denied: ' + e.message;
+      recStatus.textContent = Tf('Microphone access denied: %s', e.message);
       return;
     }
 
@@ -448,7 +1071,7 @@ func renderAddHTML(whisperEnabled bool) string {
 
     mediaRecorder.onstop = async () => {
       stream.getTracks().forEach(t => t.stop());
-      btn.textContent = 'Record voice note';
+      btn.textContent = T('Record voice note');
 
       const blob = new Blob(chunks, { type: mediaRecorder.mimeType || 'audio/webm' });
 
@@ -458,9 +1081,9 @@ func renderAddHTML(whisperEnabled bool) string {
       player.style.display = '';
 
       if (whisperEnabled) {
-        recStatus.textContent = 'Transcribing…';
+        recStatus.textContent = T('Transcribing…');
       } else {
-        recStatus.textContent = 'Saving…';
+        recStatus.textContent = T('Saving…');
       }
 
       try {
@@ -478,22 +1101,22 @@ func renderAddHTML(whisperEnabled bool) string {
         }
 
         if (data.error) {
-          recStatus.textContent = 'Transcription error: ' + data.error;
+          recStatus.textContent = Tf('Transcription error: %s', data.error);
         } else if (data.text) {
-          const prefix = '\n\n---\n\u{1F3A4} **Голосовая заметка:**\n';
+          const prefix = '\n\n---\n\u{1F3A4} **' + T('Voice note:') + '**\n';
           textarea.value += prefix + data.text;
-          recStatus.textContent = 'Transcribed.';
+          recStatus.textContent = T('Transcribed.');
         } else {
-          recStatus.textContent = 'Recording saved.';
+          recStatus.textContent = T('Recording saved.');
         }
       } catch (e) {
-        recStatus.textContent = 'Upload error: ' + e.message;
+        recStatus.textContent = Tf('Upload error: %s', e.message);
       }
     };
 
     mediaRecorder.start();
-    btn.textContent = 'Stop recording';
-    recStatus.textContent = 'Recording…';
+    btn.textContent = T('Stop recording');
+    recStatus.textContent = T('Recording…');
   });
 })();
 </script>`
@@ -506,6 +1129,7 @@ func renderManageHTML(tasks []queue.Task) string {
 			"<", "&lt;",
 			">", "&gt;",
 			`"`, "&quot;",
+			"'", "&#39;",
 		)
 		return replacer.Replace(s)
 	}
@@ -513,7 +1137,7 @@ func renderManageHTML(tasks []queue.Task) string {
 	var b strings.Builder
 	b.WriteString(`<!doctype html><html><head><meta charset="utf-8">`)
 	b.WriteString(`<meta name="viewport" content="width=device-width, initial-scale=1">`)
-	b.WriteString(`<title>Manage queue</title>`)
+	b.WriteString(`<title>` + tr("Manage queue") + `</title>`)
 	b.WriteString(`<link rel="icon" type="image/png" href="/favicon.png">`)
 	b.WriteString(`<style>
         *{box-sizing:border-box}
@@ -548,9 +1172,13 @@ func renderManageHTML(tasks []queue.Task) string {
         pre code{padding:0}
         pre{padding:12px}
         audio{width:100%;margin:8px 0}
-    </style></head><body>`)
-	b.WriteString(`<h1>Manage queue</h1>`)
-	b.WriteString(`<div class="row"><button id="save">Save order</button><button onclick="location.href='/add'">Add</button><button onclick="location.href='/history'">History</button><button onclick="location.href='/settings'">Settings</button><span id="status"></span></div>`)
+        .notes{margin-top:12px;padding:10px 14px;border-left:3px solid #ddd;background:#fafafa;border-radius:0 8px 8px 0;color:#444;font-size:14px}
+        video{max-width:100%;max-height:480px;margin:8px 0;border-radius:8px;background:#000}
+    </style>`)
+	b.WriteString(ui.I18nScript())
+	b.WriteString(`</head><body>`)
+	b.WriteString(`<h1>` + tr("Manage queue") + `</h1>`)
+	b.WriteString(`<div class="row"><button id="save">` + tr("Save order") + `</button><button onclick="location.href='/add'">` + tr("Add") + `</button><button onclick="location.href='/history'">` + tr("History") + `</button><button onclick="location.href='/settings'">` + tr("Settings") + `</button><span id="status"></span></div>`)
 	b.WriteString(`<div class="main">`)
 	b.WriteString(`<div class="left-panel">`)
 	b.WriteString(`<ul id="list">`)
@@ -562,14 +1190,15 @@ func renderManageHTML(tasks []queue.Task) string {
 		if len(prev) > 100 {
 			prev = prev[:100] + "…"
 		}
-		b.WriteString(fmt.Sprintf(`<li draggable="true" data-idx="%d" data-id="%s">%d. %s</li>`, i, esc(t.ID), i+1, esc(prev)))
+		b.WriteString(fmt.Sprintf(`<li draggable="true" data-idx="%d" data-id="%s" data-pinned="%t">%d. %s%s%s%s%s%s</li>`, i, esc(t.ID), t.Pinned, i+1, pinMarker(t), priorityMarker(t.Priority), recurrenceMarker(t.Recurrence), checklistMarker(t), esc(prev), renderTagsHTML(t.Tags)))
 	}
 	b.WriteString(`</ul>`)
-	b.WriteString(`<div class="hint">Drag to reorder · Click to preview</div>`)
+	b.WriteString(`<div class="hint">` + tr("Drag to reorder · Click to preview") + `</div>`)
 	b.WriteString(`</div>`)
 	b.WriteString(`<div id="resizer" class="resizer"></div>`)
-	b.WriteString(`<div class="right-panel" id="preview-panel"><div class="empty-hint">← Click a task to preview it</div></div>`)
+	b.WriteString(`<div class="right-panel" id="preview-panel"><div class="empty-hint">← ` + tr("Click a task to preview it") + `</div></div>`)
 	b.WriteString(`</div>`)
+	b.WriteString(`<script>` + checklistJS + attachmentLinkJS + `</script>`)
 	b.WriteString(`<script>
         const list = document.getElementById('list');
         const status = document.getElementById('status');
@@ -631,28 +1260,32 @@ func renderManageHTML(tasks []queue.Task) string {
             selectedLi = li;
             li.classList.add('selected');
             currentId = li.dataset.id;
-            panel.innerHTML = '<div class="muted">Loading…</div>';
+            panel.innerHTML = '<div class="muted">' + escHTML(T('Loading…')) + '</div>';
             try {
                 const res = await fetch('/task_preview?id=' + encodeURIComponent(currentId));
                 if (!res.ok) throw new Error(await res.text());
                 showPreview(await res.text());
             } catch (err) {
-                panel.innerHTML = '<div class="muted">Error: ' + escHTML(err.message) + '</div>';
+                panel.innerHTML = '<div class="muted">' + escHTML(Tf('Error: %s', err.message)) + '</div>';
             }
         }
 
         function showPreview(html) {
             panel.innerHTML =
                 '<div class="preview-bar">' +
-                  '<button onclick="enterEdit()">Edit</button>' +
-                  '<button onclick="taskAction(\'done\')">Done</button>' +
-                  '<button onclick="taskAction(\'delete\')" style="color:#c00">Delete</button>' +
+                  '<button onclick="enterEdit()">' + escHTML(T('Edit')) + '</button>' +
+                  '<button onclick="taskAction(\'done\')">' + escHTML(T('Done')) + '</button>' +
+                  '<button onclick="taskAction(\'promote\')">' + escHTML(T('Move to front')) + '</button>' +
+                  (selectedLi.dataset.pinned === 'true'
+                    ? '<button onclick="taskAction(\'unpin\')">' + escHTML(T('Unpin')) + '</button>'
+                    : '<button onclick="taskAction(\'pin\')">' + escHTML(T('Pin')) + '</button>') +
+                  '<button onclick="taskAction(\'delete\')" style="color:#c00">' + escHTML(T('Delete')) + '</button>' +
                 '</div>' +
                 '<div id="preview-content">' + html + '</div>';
         }
 
         async function taskAction(action) {
-            if (action === 'delete' && !confirm('Delete this task?')) return;
+            if (action === 'delete' && !confirm(T('Delete this task?'))) return;
             try {
                 const res = await fetch('/task_action', {
                     method: 'POST',
@@ -662,36 +1295,34 @@ func renderManageHTML(tasks []queue.Task) string {
                 if (!res.ok) throw new Error(await res.text());
                 location.reload();
             } catch (err) {
-                setStatus('Error: ' + err.message);
+                setStatus(Tf('Error: %s', err.message));
             }
         }
 
         async function enterEdit() {
-            panel.innerHTML = '<div class="muted">Loading…</div>';
+            panel.innerHTML = '<div class="muted">' + escHTML(T('Loading…')) + '</div>';
             try {
                 const res = await fetch('/task_raw?id=' + encodeURIComponent(currentId));
                 if (!res.ok) throw new Error(await res.text());
                 const data = await res.json();
                 showEdit(data.text);
             } catch (err) {
-                panel.innerHTML = '<div class="muted">Error: ' + escHTML(err.message) + '</div>';
+                panel.innerHTML = '<div class="muted">' + escHTML(Tf('Error: %s', err.message)) + '</div>';
             }
         }
 
-        let editAttachFilename = '';
-        let editAttachType = '';
+        let editAttachments = [];
 
         function showEdit(text) {
-            editAttachFilename = '';
-            editAttachType = '';
+            editAttachments = [];
             panel.innerHTML =
                 '<div class="preview-bar">' +
-                  '<button class="primary" onclick="saveEdit()">Save</button>' +
-                  '<button onclick="cancelEdit()">Cancel</button>' +
+                  '<button class="primary" onclick="saveEdit()">' + escHTML(T('Save')) + '</button>' +
+                  '<button onclick="cancelEdit()">' + escHTML(T('Cancel')) + '</button>' +
                   '<span id="edit-status" class="muted" style="margin-left:6px"></span>' +
                 '</div>' +
                 '<textarea class="edit-area" id="edit-ta">' + escHTML(text) + '</textarea>' +
-                '<div id="edit-paste-hint" class="hint" style="margin-top:6px">Paste image (Ctrl+V / ⌘V) to attach</div>' +
+                '<div id="edit-paste-hint" class="hint" style="margin-top:6px">' + escHTML(T('Paste image (Ctrl+V / ⌘V) to attach')) + '</div>' +
                 '<div id="edit-attach-preview" style="margin-top:8px"></div>';
             const ta = document.getElementById('edit-ta');
             ta.focus();
@@ -705,7 +1336,7 @@ func renderManageHTML(tasks []queue.Task) string {
             e.preventDefault();
             const file = imgItem.getAsFile();
             const status = document.getElementById('edit-status');
-            status.textContent = 'Uploading image…';
+            status.textContent = T('Uploading image…');
             try {
                 const res = await fetch('/attachment_upload', {
                     method: 'POST',
@@ -714,27 +1345,22 @@ func renderManageHTML(tasks []queue.Task) string {
                 });
                 if (!res.ok) throw new Error(await res.text());
                 const data = await res.json();
-                editAttachFilename = data.filename;
-                editAttachType = data.type;
+                editAttachments.push({filename: data.filename, type: data.type});
                 const preview = document.getElementById('edit-attach-preview');
-                preview.innerHTML = '<img src="/attachment?name=' + encodeURIComponent(data.filename) + '" style="max-height:120px;border-radius:6px;border:1px solid #ddd">';
-                document.getElementById('edit-paste-hint').textContent = 'Image attached (paste again to replace)';
+                preview.insertAdjacentHTML('beforeend', '<img src="/attachment?name=' + encodeURIComponent(data.filename) + '" style="max-height:120px;border-radius:6px;border:1px solid #ddd;margin-right:6px">');
+                document.getElementById('edit-paste-hint').textContent = Tf('%d image(s) attached (paste again to add more)', editAttachments.length);
                 status.textContent = '';
             } catch (err) {
-                status.textContent = 'Upload error: ' + err.message;
+                status.textContent = Tf('Upload error: %s', err.message);
             }
         }
 
         async function saveEdit() {
             const text = document.getElementById('edit-ta').value;
             const s = document.getElementById('edit-status');
-            s.textContent = 'Saving…';
+            s.textContent = T('Saving…');
             try {
-                const body = {id: currentId, text};
-                if (editAttachFilename) {
-                    body.attachment_filename = editAttachFilename;
-                    body.attachment_type = editAttachType;
-                }
+                const body = {id: currentId, text, attachments: editAttachments};
                 const res = await fetch('/task_update', {
                     method: 'POST',
                     headers: {'Content-Type': 'application/json'},
@@ -753,7 +1379,7 @@ func renderManageHTML(tasks []queue.Task) string {
                 const r2 = await fetch('/task_preview?id=' + encodeURIComponent(currentId));
                 if (r2.ok) showPreview(await r2.text());
             } catch (err) {
-                document.getElementById('edit-status').textContent = 'Error: ' + escHTML(err.message);
+                document.getElementById('edit-status').textContent = Tf('Error: %s', err.message);
             }
         }
 
@@ -762,7 +1388,7 @@ func renderManageHTML(tasks []queue.Task) string {
                 const res = await fetch('/task_preview?id=' + encodeURIComponent(currentId));
                 if (res.ok) { showPreview(await res.text()); return; }
             } catch(_) {}
-            panel.innerHTML = '<div class="empty-hint">← Click a task to preview it</div>';
+            panel.innerHTML = '<div class="empty-hint">← ' + escHTML(T('Click a task to preview it')) + '</div>';
         }
 
         // ── Resizable left panel ─────────────────────────────────────────────
@@ -794,19 +1420,25 @@ func renderManageHTML(tasks []queue.Task) string {
 
         // ── Save order ───────────────────────────────────────────────────────
         document.getElementById('save').addEventListener('click', async () => {
-            const order = [...list.querySelectorAll('li')].map(li => parseInt(li.dataset.idx, 10));
-            setStatus('Saving…');
+            const ids = [...list.querySelectorAll('li')].map(li => li.dataset.id);
+            setStatus(T('Saving…'));
             try {
                 const res = await fetch('/reorder', {
                     method: 'POST',
                     headers: {'Content-Type': 'application/json'},
-                    body: JSON.stringify({order})
+                    body: JSON.stringify({ids})
                 });
+                if (res.status === 409) {
+                    // The queue changed elsewhere: show the real order.
+                    alert(await res.text());
+                    location.reload();
+                    return;
+                }
                 if (!res.ok) throw new Error(await res.text());
-                setStatus('Saved');
+                setStatus(T('Saved'));
                 setTimeout(() => setStatus(''), 1200);
             } catch (err) {
-                setStatus('Error: ' + err.message);
+                setStatus(Tf('Error: %s', err.message));
             }
         });
     </script>`)
@@ -825,23 +1457,13 @@ func (s *Server) handleTaskPreview(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "not found", http.StatusNotFound)
 		return
 	}
-	taskText := t.Text
-	if t.AttachmentPath != "" {
-		name := filepath.Base(t.AttachmentPath)
-		switch t.AttachmentType {
-		case queue.AttachmentImage:
-			taskText += "\n\n![attachment](/attachment?name=" + url.QueryEscape(name) + ")\n"
-		case queue.AttachmentAudio:
-			taskText += "\n\n<audio controls src=\"/attachment?name=" + url.QueryEscape(name) + "\"></audio>\n"
-		}
-	}
-	frag, err := ui.RenderTaskHTML(queue.Task{ID: t.ID, Text: taskText, CreatedAt: t.CreatedAt})
+	frag, err := s.renderTaskBody(t)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	io.WriteString(w, frag)
+	io.WriteString(w, renderCreatedHTML(t, time.Now())+renderDueHTML(t)+frag)
 }
 
 func (s *Server) handleTaskRaw(w http.ResponseWriter, r *http.Request) {
@@ -866,10 +1488,12 @@ func (s *Server) handleTaskUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	var req struct {
-		ID                 string `json:"id"`
-		Text               string `json:"text"`
-		AttachmentFilename string `json:"attachment_filename"`
-		AttachmentType     string `json:"attachment_type"`
+		ID          string `json:"id"`
+		Text        string `json:"text"`
+		Attachments []struct {
+			Filename string `json:"filename"`
+			Type     string `json:"type"`
+		} `json:"attachments"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "bad json", http.StatusBadRequest)
@@ -880,30 +1504,33 @@ func (s *Server) handleTaskUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.AttachmentFilename != "" &&
-		!strings.Contains(req.AttachmentFilename, "/") &&
-		!strings.Contains(req.AttachmentFilename, "\\") &&
-		!strings.Contains(req.AttachmentFilename, "..") {
-		candidate := filepath.Join(s.q.AttachmentsDir(), req.AttachmentFilename)
+	var added []queue.Attachment
+	for _, a := range req.Attachments {
+		if a.Filename == "" ||
+			strings.Contains(a.Filename, "/") ||
+			strings.Contains(a.Filename, "\\") ||
+			strings.Contains(a.Filename, "..") {
+			continue
+		}
+		candidate := filepath.Join(s.q.AttachmentsDir(), a.Filename)
 		inside, err := util.IsPathInsideDir(candidate, s.q.AttachmentsDir())
-		if err == nil && inside {
-			if _, err := os.Stat(candidate); err == nil {
-				at := queue.AttachmentImage
-				if req.AttachmentType == "audio" {
-					at = queue.AttachmentAudio
-				}
-				if err := s.q.UpdateTask(req.ID, req.Text, candidate, at); err != nil {
-					http.Error(w, err.Error(), http.StatusInternalServerError)
-					return
-				}
-				w.Header().Set("Content-Type", "application/json; charset=utf-8")
-				io.WriteString(w, `{"ok":true}`)
-				return
-			}
+		if err != nil || !inside {
+			continue
 		}
+		if _, err := os.Stat(candidate); err != nil {
+			continue
+		}
+		at := queue.AttachmentImage
+		switch a.Type {
+		case "audio":
+			at = queue.AttachmentAudio
+		case "video":
+			at = queue.AttachmentVideo
+		}
+		added = append(added, queue.Attachment{Path: candidate, Type: at})
 	}
 
-	if err := s.q.UpdateText(req.ID, req.Text); err != nil {
+	if err := s.q.UpdateTask(req.ID, req.Text, added...); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -930,8 +1557,31 @@ func (s *Server) handleTaskAction(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+	case "skip":
+		if err := s.q.SkipByID(req.ID); errors.Is(err, queue.ErrPinned) {
+			http.Error(w, "The task is pinned; unpin it to skip.", http.StatusConflict)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
 	case "delete":
-		if err := s.q.DeleteByID(req.ID); err != nil {
+		if _, err := s.q.DeleteByID(req.ID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	case "promote":
+		if err := s.q.Promote(req.ID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	case "pin":
+		if err := s.q.Pin(req.ID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	case "unpin":
+		if err := s.q.Unpin(); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -943,12 +1593,50 @@ func (s *Server) handleTaskAction(w http.ResponseWriter, r *http.Request) {
 	io.WriteString(w, `{"ok":true}`)
 }
 
+// handleTaskChecklist checks or unchecks one checklist item. With
+// checklist_auto_complete set, checking the last open item completes the task.
+func (s *Server) handleTaskChecklist(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		ID    string `json:"id"`
+		Index int    `json:"index"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad json", http.StatusBadRequest)
+		return
+	}
+	t, err := s.q.ToggleChecklistItem(req.ID, req.Index)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var resp struct {
+		Done      int  `json:"done"`
+		Total     int  `json:"total"`
+		Completed bool `json:"completed"`
+	}
+	resp.Done, resp.Total = t.ChecklistProgress()
+	if cfg, _, _ := hotkeys.LoadOrCreate(s.baseDir); cfg.ChecklistAuto && t.ChecklistDone() {
+		if _, err := s.q.CompleteByID(t.ID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		resp.Completed = true
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(resp)
+}
+
 func (s *Server) handleAttachmentUpload(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	r.Body = http.MaxBytesReader(w, r.Body, 64<<20)
+	cfg, _, _ := hotkeys.LoadOrCreate(s.baseDir)
+	r.Body = http.MaxBytesReader(w, r.Body, cfg.MaxAttachmentSize())
 	ct := r.Header.Get("Content-Type")
 	ext := ".png"
 	switch {
@@ -1028,7 +1716,7 @@ func (s *Server) handleTranscribe(w http.ResponseWriter, r *http.Request) {
 		userMsg := err.Error()
 		if te, ok := err.(*transcribeError); ok {
 			log.Printf("[whisper] transcription failed: %s", te.Detail)
-			userMsg = te.UserMsg
+			userMsg = i18n.T(te.UserMsg)
 		} else {
 			log.Printf("[whisper] transcription failed: %v", err)
 		}
@@ -1131,7 +1819,8 @@ func clearProxyEnv(env []string) []string {
 const whisperModel = "tiny"
 
 // transcribeAudio runs the whisper CLI on audioPath and returns the transcribed text.
-// transcribeError carries a short user-facing message and a detailed cause for logging.
+// transcribeError carries a short user-facing message, an i18n key, and a
+// detailed cause for logging.
 type transcribeError struct {
 	UserMsg string
 	Detail  string
@@ -1143,7 +1832,7 @@ func transcribeAudio(audioPath string) (string, error) {
 	whisperBin, err := exec.LookPath("whisper")
 	if err != nil {
 		return "", &transcribeError{
-			UserMsg: "Whisper не установлен",
+			UserMsg: "Whisper is not installed",
 			Detail:  "binary not found in PATH; install with: pip install openai-whisper",
 		}
 	}
@@ -1154,15 +1843,15 @@ func transcribeAudio(audioPath string) (string, error) {
 	cmd.Env = clearProxyEnv(os.Environ())
 	out, err := cmd.CombinedOutput()
 	if err != nil {
-		userMsg := "Ошибка транскрипции"
+		userMsg := "Transcription failed"
 		outStr := string(out)
 		switch {
 		case strings.Contains(outStr, "URLError") || strings.Contains(outStr, "urlopen") || strings.Contains(outStr, "RemoteDisconnected"):
-			userMsg = "Не удалось загрузить модель Whisper — нет доступа к сети"
+			userMsg = "Could not download the Whisper model: no network access"
 		case strings.Contains(outStr, "load_model") || strings.Contains(outStr, "download"):
-			userMsg = "Ошибка загрузки модели Whisper"
+			userMsg = "Could not load the Whisper model"
 		case strings.Contains(outStr, "No such file"):
-			userMsg = "Аудиофайл не найден"
+			userMsg = "The audio file was not found"
 		}
 		return "", &transcribeError{UserMsg: userMsg, Detail: fmt.Sprintf("exit: %v\n%s", err, outStr)}
 	}
@@ -1172,7 +1861,7 @@ func transcribeAudio(audioPath string) (string, error) {
 	txtBytes, err := os.ReadFile(txtPath)
 	if err != nil {
 		return "", &transcribeError{
-			UserMsg: "Не удалось прочитать результат транскрипции",
+			UserMsg: "Could not read the transcription",
 			Detail:  err.Error(),
 		}
 	}
@@ -1190,14 +1879,20 @@ func (s *Server) handleSettings(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	page := ui.RenderPage("Settings", renderSettingsHTML(cfg))
+	settings, err := util.LoadSettings()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	page := ui.RenderPage(i18n.T("Settings"), renderSettingsHTML(cfg, settings, s.baseDir))
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	io.WriteString(w, page)
 }
 
 type settingsSaveRequest struct {
 	hotkeys.KeyConfig
-	AutostartEnabled *bool `json:"autostart_enabled"`
+	AutostartEnabled *bool   `json:"autostart_enabled"`
+	DataDir          *string `json:"data_dir"`
 }
 
 func (s *Server) handleSettingsSave(w http.ResponseWriter, r *http.Request) {
@@ -1218,6 +1913,43 @@ func (s *Server) handleSettingsSave(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	if cfg.Backups > queue.MaxBackups {
+		http.Error(w, fmt.Sprintf("backup_count %d: at most %d", cfg.Backups, queue.MaxBackups), http.StatusBadRequest)
+		return
+	}
+	cfg.DoneSoundFile = strings.TrimSpace(cfg.DoneSoundFile)
+	if cfg.DoneSoundFile != "" {
+		if fi, err := os.Stat(cfg.DoneSoundFile); err != nil {
+			http.Error(w, "completion_sound_file: "+err.Error(), http.StatusBadRequest)
+			return
+		} else if !fi.Mode().IsRegular() {
+			http.Error(w, "completion_sound_file: "+cfg.DoneSoundFile+" is not a file", http.StatusBadRequest)
+			return
+		}
+	}
+	cfg.WebhookURL = strings.TrimSpace(cfg.WebhookURL)
+	if err := webhook.ValidURL(cfg.WebhookURL); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	exts, err := queue.ParseAttachmentExts(cfg.AttachTypes)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	cfg.AttachTypes = exts
+	if req.DataDir != nil {
+		settings, err := util.LoadSettings()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		settings.DataDir = strings.TrimSpace(*req.DataDir)
+		if err := util.SaveSettings(settings); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
 	if err := hotkeys.Save(s.baseDir, cfg); err != nil {
 		http.Error(w, "save failed: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -1253,17 +1985,19 @@ var hotkeyMeta = []struct {
 	{hotkeys.ActionSkip, "Skip task"},
 	{hotkeys.ActionComplete, "Complete task"},
 	{hotkeys.ActionManageQueue, "Manage queue"},
+	{hotkeys.ActionBrowseNext, "Show next task"},
+	{hotkeys.ActionBrowsePrev, "Show previous task"},
 }
 
-func renderSettingsHTML(cfg hotkeys.KeyConfig) string {
+func renderSettingsHTML(cfg hotkeys.KeyConfig, settings util.Settings, dataDir string) string {
 	esc := func(s string) string {
 		return strings.NewReplacer(`"`, "&quot;", "&", "&amp;", "<", "&lt;").Replace(s)
 	}
 
 	var b strings.Builder
-	b.WriteString(`<h1>Settings</h1>`)
-	b.WriteString(`<h2 style="font-size:16px;margin:20px 0 10px">Hotkeys</h2>`)
-	b.WriteString(`<p class="muted">Нажмите кнопку и введите сочетание клавиш. Escape — отмена.</p>`)
+	b.WriteString(`<h1>` + tr("Settings") + `</h1>`)
+	b.WriteString(`<h2 style="font-size:16px;margin:20px 0 10px">` + tr("Hotkeys") + `</h2>`)
+	b.WriteString(`<p class="muted">` + tr("Click a button and press the key combination. Escape cancels.") + `</p>`)
 
 	b.WriteString(`<style>
 .hk-table{border-collapse:collapse;width:100%;max-width:600px}
@@ -1279,7 +2013,7 @@ func renderSettingsHTML(cfg hotkeys.KeyConfig) string {
 </style>`)
 
 	b.WriteString(`<table class="hk-table"><thead><tr>`)
-	b.WriteString(`<th>Действие</th><th style="text-align:center">Вкл</th><th>Сочетание</th><th></th>`)
+	b.WriteString(`<th>` + tr("Action") + `</th><th style="text-align:center">` + tr("On") + `</th><th>` + tr("Shortcut") + `</th><th></th>`)
 	b.WriteString(`</tr></thead><tbody>`)
 
 	for _, meta := range hotkeyMeta {
@@ -1301,8 +2035,8 @@ func renderSettingsHTML(cfg hotkeys.KeyConfig) string {
     <button class="hk-record" data-key="%s">%s</button>
     <span class="hk-conflict" data-key="%s"></span>
   </td>
-  <td><button class="hk-clear" data-key="%s" title="Очистить">×</button></td>
-</tr>`, esc(meta.Key), meta.Label, esc(meta.Key), checked, esc(meta.Key), esc(combo), esc(meta.Key), esc(meta.Key)))
+  <td><button class="hk-clear" data-key="%s" title="%s">×</button></td>
+</tr>`, esc(meta.Key), tr(meta.Label), esc(meta.Key), checked, esc(meta.Key), esc(combo), esc(meta.Key), esc(meta.Key), tr("Clear")))
 	}
 
 	b.WriteString(`</tbody></table>`)
@@ -1315,24 +2049,24 @@ func renderSettingsHTML(cfg hotkeys.KeyConfig) string {
 	}
 
 	trayGroupLabels := map[string]string{
-		"task":       "Текущая задача (заголовок задачи)",
-		"timer":      "Таймер (Start/Pause)",
-		"actions":    "Действия (Skip / Done)",
-		"navigation": "Навигация (Add / View / Manage)",
-		"system":     "Система (Settings / Quit)",
+		"task":       "Current task (task title, Upcoming, Next / Previous task)",
+		"timer":      "Timer (Start/Pause)",
+		"actions":    "Actions (Skip / Done / Done with note / Undo / Snooze / Edit / Copy / Open attachment / Pin / Duplicate / Save as template / Delete / Bulk)",
+		"navigation": "Navigation (Add / Add text only / Add from template / Focus / View / Manage / Search / History / Stats)",
+		"system":     "System (Import / Export / Export task / Markdown / Data folder / Cleanup / Duplicates / Empty queue / Restore / Archive / Settings / Quit)",
 	}
 
-	b.WriteString(`<h2 style="font-size:16px;margin:28px 0 10px">Трей</h2>`)
+	b.WriteString(`<h2 style="font-size:16px;margin:28px 0 10px">` + tr("Tray") + `</h2>`)
 	b.WriteString(fmt.Sprintf(`<div style="margin-bottom:16px">
   <label style="display:flex;align-items:center;gap:8px">
-    Длительность таймера:
+    %s
     <input type="number" id="timer-minutes" min="1" max="180" value="%d"
       style="width:64px;padding:5px 8px;border:1px solid #ccc;border-radius:6px;font-size:14px">
-    мин
+    %s
   </label>
-</div>`, timerMin))
+</div>`, tr("Timer length:"), timerMin, tr("min")))
 
-	b.WriteString(`<p class="muted" style="margin-bottom:8px">Порядок групп — изменения вступают в силу после перезапуска.</p>`)
+	b.WriteString(`<p class="muted" style="margin-bottom:8px">` + tr("Menu group order; changes take effect after a restart.") + `</p>`)
 	b.WriteString(`<style>
 .tg-row{display:flex;align-items:center;gap:8px;padding:7px 10px;background:#fff;border:1px solid #e8e8e8;border-radius:8px;margin-bottom:4px;font-size:14px}
 .tg-label{flex:1}
@@ -1346,16 +2080,16 @@ func renderSettingsHTML(cfg hotkeys.KeyConfig) string {
 		if g.Visible {
 			checked = " checked"
 		}
-		label := trayGroupLabels[g.ID]
+		label := i18n.T(trayGroupLabels[g.ID])
 		if label == "" {
 			label = g.ID
 		}
 		b.WriteString(fmt.Sprintf(`<div class="tg-row" data-id="%s">
   <input type="checkbox" class="tg-vis"%s style="width:15px;height:15px;cursor:pointer">
   <span class="tg-label">%s</span>
-  <button class="tg-btn tg-up" title="Вверх">↑</button>
-  <button class="tg-btn tg-dn" title="Вниз">↓</button>
-</div>`, esc(g.ID), checked, esc(label)))
+  <button class="tg-btn tg-up" title="%s">↑</button>
+  <button class="tg-btn tg-dn" title="%s">↓</button>
+</div>`, esc(g.ID), checked, esc(label), tr("Up"), tr("Down")))
 	}
 	b.WriteString(`</div>`)
 	b.WriteString(`<script>
@@ -1379,26 +2113,179 @@ func renderSettingsHTML(cfg hotkeys.KeyConfig) string {
 	if cfg.IsWhisperEnabled() {
 		whisperChecked = " checked"
 	}
-	b.WriteString(`<h2 style="font-size:16px;margin:28px 0 10px">Voice transcription (Whisper)</h2>`)
+	b.WriteString(`<h2 style="font-size:16px;margin:28px 0 10px">` + tr("Voice transcription (Whisper)") + `</h2>`)
 	b.WriteString(fmt.Sprintf(`<label style="display:flex;align-items:center;gap:10px;cursor:pointer">
   <input type="checkbox" id="whisper-enabled"%s style="width:16px;height:16px;cursor:pointer">
-  Enable Whisper transcription (voice recording in Add task form)
-</label>`, whisperChecked))
+  %s
+</label>`, whisperChecked, tr("Enable Whisper transcription (voice recording in Add task form)")))
+
+	// Dialogs section
+	confirmChecked := ""
+	if cfg.IsConfirmRemovalEnabled() {
+		confirmChecked = " checked"
+	}
+	b.WriteString(`<h2 style="font-size:16px;margin:28px 0 10px">` + tr("Dialogs") + `</h2>`)
+	b.WriteString(fmt.Sprintf(`<label style="display:flex;align-items:center;gap:10px;cursor:pointer">
+  <input type="checkbox" id="confirm-removal"%s style="width:16px;height:16px;cursor:pointer">
+  %s
+</label>`, confirmChecked, tr("Ask before completing or deleting a task from the tray menu or hotkeys")))
+	b.WriteString(fmt.Sprintf(`<label style="display:flex;align-items:center;gap:8px;margin-top:10px">
+  %s
+  <input type="number" id="dialog-timeout-minutes" min="1" max="1440" value="%d"
+    style="width:80px;padding:5px 8px;border:1px solid #ccc;border-radius:6px;font-size:14px">
+  %s
+</label>`, tr("Close unanswered dialogs after"), int(cfg.DialogTimeout()/time.Minute), tr("min")))
+
+	// Queue section
+	b.WriteString(`<h2 style="font-size:16px;margin:28px 0 10px">` + tr("Queue") + `</h2>`)
+	b.WriteString(fmt.Sprintf(`<label style="display:flex;align-items:center;gap:8px">
+  %s
+  <input type="number" id="max-queue-len" min="0" max="100000" value="%d"
+    style="width:80px;padding:5px 8px;border:1px solid #ccc;border-radius:6px;font-size:14px">
+  %s
+</label>`, tr("Maximum queue length:"), cfg.QueueLimit(), tr("tasks (0 = unlimited)")))
+	b.WriteString(fmt.Sprintf(`<label style="display:flex;align-items:center;gap:8px;margin-top:10px">
+  %s
+  <input type="number" id="backup-count" min="0" max="%d" value="%d"
+    style="width:80px;padding:5px 8px;border:1px solid #ccc;border-radius:6px;font-size:14px">
+  %s
+</label>`, tr("Keep the last"), queue.MaxBackups, cfg.BackupCount(), tr("versions of queue.json as backups (0 = none)")))
+	b.WriteString(fmt.Sprintf(`<label style="display:flex;align-items:center;gap:8px;margin-top:10px">
+  %s
+  <input type="number" id="auto-archive-days" min="0" max="3650" value="%d"
+    style="width:80px;padding:5px 8px;border:1px solid #ccc;border-radius:6px;font-size:14px">
+  %s
+</label>`, tr("Archive tasks older than"), cfg.AutoArchiveDays(), tr("days (0 = never)")))
+	densityOptions := ""
+	for _, d := range []struct{ value, label string }{
+		{hotkeys.DensityComfortable, "Comfortable"},
+		{hotkeys.DensityCompact, "Compact — tight rows, no image previews"},
+	} {
+		selected := ""
+		if d.value == cfg.ListDensity || (cfg.ListDensity == "" && d.value == hotkeys.DensityComfortable) {
+			selected = " selected"
+		}
+		densityOptions += fmt.Sprintf(`<option value="%s"%s>%s</option>`, d.value, selected, tr(d.label))
+	}
+	b.WriteString(`<label style="display:flex;align-items:center;gap:8px;margin-top:10px">
+  ` + tr("Show queue density:") + `
+  <select id="list-density" style="padding:5px 8px;border:1px solid #ccc;border-radius:6px;font-size:14px">` + densityOptions + `</select>
+</label>`)
+	checklistChecked := ""
+	if cfg.ChecklistAuto {
+		checklistChecked = " checked"
+	}
+	b.WriteString(fmt.Sprintf(`<label style="display:flex;align-items:center;gap:10px;margin-top:10px;cursor:pointer">
+  <input type="checkbox" id="checklist-auto-complete"%s style="width:16px;height:16px;cursor:pointer">
+  %s
+</label>`, checklistChecked, tr("Complete a task when the last item of its checklist is checked")))
+	doneIconChecked := ""
+	if cfg.DoneIcon {
+		doneIconChecked = " checked"
+	}
+	b.WriteString(fmt.Sprintf(`<label style="display:flex;align-items:center;gap:10px;margin-top:10px;cursor:pointer">
+  <input type="checkbox" id="done-icon"%s style="width:16px;height:16px;cursor:pointer">
+  %s
+</label>`, doneIconChecked, tr("Show a check mark in the tray once the last task is done, until a task is added")))
+	sortByDueChecked := ""
+	if cfg.SortByDue {
+		sortByDueChecked = " checked"
+	}
+	b.WriteString(fmt.Sprintf(`<label style="display:flex;align-items:center;gap:10px;margin-top:10px;cursor:pointer">
+  <input type="checkbox" id="sort-by-due"%s style="width:16px;height:16px;cursor:pointer">
+  %s
+</label>`, sortByDueChecked, tr("Make the task with the earliest due date current; tasks without one follow in queue order")))
+	nativeViewChecked := ""
+	if cfg.NativeTaskView {
+		nativeViewChecked = " checked"
+	}
+	b.WriteString(fmt.Sprintf(`<label style="display:flex;align-items:center;gap:10px;margin-top:10px;cursor:pointer">
+  <input type="checkbox" id="native-task-view"%s style="width:16px;height:16px;cursor:pointer">
+  %s
+</label>`, nativeViewChecked, tr("Show the current task in a plain dialog instead of the browser (no images or players)")))
+	restoreLastChecked := ""
+	if cfg.RestoreLast {
+		restoreLastChecked = " checked"
+	}
+	b.WriteString(fmt.Sprintf(`<label style="display:flex;align-items:center;gap:10px;margin-top:10px;cursor:pointer">
+  <input type="checkbox" id="restore-last-task"%s style="width:16px;height:16px;cursor:pointer">
+  %s
+</label>`, restoreLastChecked, tr("On start, reopen the task last opened from the tray if it is still queued")))
+	doneSoundChecked := ""
+	if cfg.DoneSound {
+		doneSoundChecked = " checked"
+	}
+	b.WriteString(fmt.Sprintf(`<label style="display:flex;align-items:center;gap:10px;margin-top:10px;cursor:pointer">
+  <input type="checkbox" id="completion-sound"%s style="width:16px;height:16px;cursor:pointer">
+  %s
+</label>
+<label style="display:flex;align-items:center;gap:8px;margin-top:10px">
+  %s
+  <input type="text" id="completion-sound-file" value="%s" placeholder="%s"
+    style="flex:1;padding:5px 8px;border:1px solid #ccc;border-radius:6px;font-size:14px">
+</label>
+<p class="muted" style="margin:4px 0 0">%s</p>`, doneSoundChecked, tr("Play a sound when a task is completed"), tr("Sound file:"), html.EscapeString(cfg.DoneSoundFile),
+		tr("built-in chime"), tr("Full path to an audio file; WAV works on every system. Leave empty for the built-in chime.")))
+
+	// Webhook section
+	b.WriteString(`<h2 style="font-size:16px;margin:28px 0 10px">Webhook</h2>`)
+	b.WriteString(`<p class="muted" style="margin:0 0 10px">` + tr("Every completed task is POSTed as JSON to this URL. Leave empty to turn it off.") + `</p>`)
+	b.WriteString(fmt.Sprintf(`<label style="display:flex;align-items:center;gap:8px">
+  URL:
+  <input type="url" id="webhook-url" value="%s" placeholder="https://example.com/hook"
+    style="flex:1;max-width:420px;padding:5px 8px;border:1px solid #ccc;border-radius:6px;font-size:14px">
+</label>`, esc(cfg.WebhookURL)))
+	b.WriteString(fmt.Sprintf(`<label style="display:flex;align-items:center;gap:8px;margin-top:10px">
+  %s
+  <input type="password" id="webhook-secret" value="%s" autocomplete="off"
+    style="flex:1;max-width:300px;padding:5px 8px;border:1px solid #ccc;border-radius:6px;font-size:14px">
+</label>`, tr("Secret:"), esc(cfg.WebhookSecret)))
+	b.WriteString(`<p class="muted" style="margin:8px 0 0">` + fmt.Sprintf(tr("With a secret, requests carry %s, an HMAC-SHA256 of the body."), `<code>`+webhook.SignatureHeader+`: sha256=…</code>`) + `</p>`)
+
+	// Attachments section
+	b.WriteString(`<h2 style="font-size:16px;margin:28px 0 10px">` + tr("Attachments") + `</h2>`)
+	b.WriteString(fmt.Sprintf(`<label style="display:flex;align-items:center;gap:8px">
+  %s
+  <input type="number" id="max-attachment-mb" min="1" max="10240" value="%d"
+    style="width:80px;padding:5px 8px;border:1px solid #ccc;border-radius:6px;font-size:14px">
+  %s
+</label>`, tr("Maximum attachment size:"), cfg.MaxAttachmentSize()>>20, tr("MB")))
+	b.WriteString(fmt.Sprintf(`<label style="display:flex;align-items:center;gap:8px;margin-top:10px">
+  %s
+  <input type="text" id="attachment-types" value="%s" placeholder=".pdf, .txt"
+    style="flex:1;max-width:300px;padding:5px 8px;border:1px solid #ccc;border-radius:6px;font-size:14px">
+</label>
+<p class="muted" style="margin:8px 0 0">%s</p>`, tr("Other allowed types:"), esc(strings.Join(cfg.AttachTypes, ", ")),
+		fmt.Sprintf(tr("Images, audio and video are always allowed and shown inline. Types listed here are stored the same way and offered as a download; programs such as %s or %s are not accepted."), "<code>.exe</code>", "<code>.sh</code>")))
+
+	// Data folder section
+	b.WriteString(`<h2 style="font-size:16px;margin:28px 0 10px">` + tr("Data folder") + `</h2>`)
+	b.WriteString(fmt.Sprintf(`<p class="muted" style="margin:0 0 10px">%s <code>%s</code></p>`, tr("In use:"), esc(dataDir)))
+	b.WriteString(fmt.Sprintf(`<label style="display:flex;align-items:center;gap:8px">
+  %s
+  <input type="text" id="data-dir" value="%s" placeholder="%s"
+    style="flex:1;max-width:420px;padding:5px 8px;border:1px solid #ccc;border-radius:6px;font-size:14px">
+</label>`, tr("Folder:"), esc(settings.DataDir), tr("default location")))
+	b.WriteString(`<p class="muted" style="margin:8px 0 0">` + tr("An absolute path; leave empty for the default. Takes effect after a restart, existing data is not moved."))
+	if os.Getenv(util.EnvDataDir) != "" {
+		b.WriteString(" " + trf("%s is set and takes precedence.", util.EnvDataDir))
+	}
+	b.WriteString(`</p>`)
 
 	// Autostart section
 	autostartChecked := ""
 	if autostart.IsEnabled() {
 		autostartChecked = " checked"
 	}
-	b.WriteString(`<h2 style="font-size:16px;margin:28px 0 10px">Autostart</h2>`)
+	b.WriteString(`<h2 style="font-size:16px;margin:28px 0 10px">` + tr("Autostart") + `</h2>`)
 	b.WriteString(fmt.Sprintf(`<label style="display:flex;align-items:center;gap:10px;cursor:pointer">
   <input type="checkbox" id="autostart-enabled"%s style="width:16px;height:16px;cursor:pointer">
-  Launch automatically when the system starts
-</label>`, autostartChecked))
+  %s
+</label>`, autostartChecked, tr("Launch automatically when the system starts")))
 
 	b.WriteString(`<div class="row" style="margin-top:20px">`)
-	b.WriteString(`<button id="save-btn">Save</button>`)
-	b.WriteString(`<button onclick="location.href='/'">Back</button>`)
+	b.WriteString(`<button id="save-btn">` + tr("Save") + `</button>`)
+	b.WriteString(`<button onclick="location.href='/'">` + tr("Back") + `</button>`)
 	b.WriteString(`<span id="status" class="muted"></span>`)
 	b.WriteString(`</div>`)
 
@@ -1478,8 +2365,8 @@ func renderSettingsHTML(cfg hotkeys.KeyConfig) string {
       const combo = combos[key] || '';
       if (!cb.checked || !combo) return;
       if (seen[combo] !== undefined) {
-        document.querySelector('.hk-conflict[data-key="' + seen[combo] + '"]').textContent = '⚠ конфликт';
-        document.querySelector('.hk-conflict[data-key="' + key + '"]').textContent = '⚠ конфликт';
+        document.querySelector('.hk-conflict[data-key="' + seen[combo] + '"]').textContent = '⚠ ' + T('conflict');
+        document.querySelector('.hk-conflict[data-key="' + key + '"]').textContent = '⚠ ' + T('conflict');
       } else {
         seen[combo] = key;
       }
@@ -1493,7 +2380,7 @@ func renderSettingsHTML(cfg hotkeys.KeyConfig) string {
       cancelListening();
       listeningKey = btn.dataset.key;
       listeningBtn = btn;
-      btn.textContent = 'Нажмите клавиши…';
+      btn.textContent = T('Press the keys…');
       btn.classList.add('listening');
       document.addEventListener('keydown', onKeyDown, true);
     });
@@ -1520,7 +2407,7 @@ func renderSettingsHTML(cfg hotkeys.KeyConfig) string {
     // Block save if conflicts exist
     const hasConflict = [...document.querySelectorAll('.hk-conflict')].some(s => s.textContent !== '');
     if (hasConflict) {
-      document.getElementById('status').textContent = 'Исправьте конфликты перед сохранением';
+      document.getElementById('status').textContent = T('Fix the conflicts before saving');
       return;
     }
     const status = document.getElementById('status');
@@ -1531,25 +2418,53 @@ func renderSettingsHTML(cfg hotkeys.KeyConfig) string {
     });
     const timerMinEl = document.getElementById('timer-minutes');
     const timerMinutes = timerMinEl ? parseInt(timerMinEl.value, 10) || 25 : 25;
+    const maxAttachEl = document.getElementById('max-attachment-mb');
+    const maxAttachmentMB = maxAttachEl ? parseInt(maxAttachEl.value, 10) || 50 : 50;
+    const dialogEl = document.getElementById('dialog-timeout-minutes');
+    const dialogMinutes = dialogEl ? parseInt(dialogEl.value, 10) || 5 : 5;
+    const backupEl = document.getElementById('backup-count');
+    const backupCount = backupEl ? Math.max(parseInt(backupEl.value, 10) || 0, 0) : 0;
+    const archiveEl = document.getElementById('auto-archive-days');
+    const autoArchiveDays = archiveEl ? Math.max(parseInt(archiveEl.value, 10) || 0, 0) : 0;
+    const maxQueueEl = document.getElementById('max-queue-len');
+    const maxQueueLen = maxQueueEl ? Math.max(parseInt(maxQueueEl.value, 10) || 0, 0) : 0;
     const trayGroups = window._collectTrayGroups ? window._collectTrayGroups() : [];
     const body = JSON.stringify({
       version: 1,
       timer_minutes: timerMinutes,
+      max_attachment_mb: maxAttachmentMB,
+      attachment_types: document.getElementById('attachment-types').value.split(',').map(s => s.trim()).filter(s => s),
+      dialog_timeout_minutes: dialogMinutes,
+      max_queue_len: maxQueueLen,
+      backup_count: backupCount,
+      auto_archive_days: autoArchiveDays,
+      checklist_auto_complete: document.getElementById('checklist-auto-complete').checked,
+      list_density: document.getElementById('list-density').value,
+      done_icon: document.getElementById('done-icon').checked,
+      sort_by_due: document.getElementById('sort-by-due').checked,
+      native_task_view: document.getElementById('native-task-view').checked,
+      restore_last_task: document.getElementById('restore-last-task').checked,
+      completion_sound: document.getElementById('completion-sound').checked,
+      completion_sound_file: document.getElementById('completion-sound-file').value.trim(),
+      webhook_url: document.getElementById('webhook-url').value,
+      webhook_secret: document.getElementById('webhook-secret').value,
       tray_groups: trayGroups,
       whisper_enabled: document.getElementById('whisper-enabled').checked,
+      confirm_removal: document.getElementById('confirm-removal').checked,
       hotkeys,
       autostart_enabled: document.getElementById('autostart-enabled').checked,
+      data_dir: document.getElementById('data-dir').value,
     });
-    status.textContent = 'Saving…';
+    status.textContent = T('Saving…');
     try {
       const res = await fetch('/settings/save', {
         method: 'POST', headers: {'Content-Type': 'application/json'}, body,
       });
       if (!res.ok) throw new Error(await res.text());
-      status.textContent = 'Saved';
+      status.textContent = T('Saved');
       setTimeout(() => status.textContent = '', 2000);
     } catch (err) {
-      status.textContent = 'Error: ' + err.message;
+      status.textContent = Tf('Error: %s', err.message);
     }
   });
 
@@ -1558,10 +2473,10 @@ func renderSettingsHTML(cfg hotkeys.KeyConfig) string {
 </script>`)
 
 	// ── Updates section ───────────────────────────────────────────────────
-	b.WriteString(`<h2 style="font-size:16px;margin:28px 0 10px">Updates</h2>`)
-	b.WriteString(fmt.Sprintf(`<p class="muted" style="margin:0 0 10px">Current version: <strong>%s</strong></p>`, esc(updater.Version)))
+	b.WriteString(`<h2 style="font-size:16px;margin:28px 0 10px">` + tr("Updates") + `</h2>`)
+	b.WriteString(fmt.Sprintf(`<p class="muted" style="margin:0 0 10px">%s <strong>%s</strong></p>`, tr("Current version:"), esc(updater.Version)))
 	b.WriteString(`<div class="row" style="align-items:center">
-  <button id="check-update-btn">Check for updates</button>
+  <button id="check-update-btn">` + tr("Check for updates") + `</button>
   <span id="update-status" class="muted"></span>
 </div>
 <div id="update-action" style="margin-top:10px"></div>
@@ -1579,33 +2494,51 @@ func renderSettingsHTML(cfg hotkeys.KeyConfig) string {
 
   function renderState(data) {
     if (data.error) {
-      updateStatus.textContent = 'Check failed: ' + data.error;
+      updateStatus.textContent = Tf('Check failed: %s', data.error);
       return;
     }
     if (data.available) {
-      updateStatus.textContent = 'Update available: ' + data.new_version;
-      let html = '<button id="install-btn" style="background:#1a73e8;color:#fff;border-color:#1a73e8">Install ' + data.new_version + ' and restart</button>';
-      if (data.page_url) html += ' <a href="' + data.page_url + '" target="_blank" style="font-size:13px;margin-left:8px">Release notes</a>';
-      if (!data.download_url) html += '<p class="muted" style="margin:6px 0 0">No binary for this platform — install manually from the link above.</p>';
-      updateAction.innerHTML = html;
-      const installBtn = document.getElementById('install-btn');
-      if (installBtn && !data.download_url) installBtn.disabled = true;
-      if (installBtn) installBtn.addEventListener('click', installUpdate);
+      updateStatus.textContent = Tf('Update available: %s', data.new_version);
+      // Release data comes from the network: build the nodes instead of
+      // concatenating HTML, and only link to https pages.
+      updateAction.replaceChildren();
+      const installBtn = document.createElement('button');
+      installBtn.style.cssText = 'background:#1a73e8;color:#fff;border-color:#1a73e8';
+      installBtn.textContent = Tf('Install %s and restart', data.new_version);
+      installBtn.disabled = !data.download_url;
+      installBtn.addEventListener('click', installUpdate);
+      updateAction.append(installBtn);
+      if (data.page_url && data.page_url.startsWith('https://')) {
+        const link = document.createElement('a');
+        link.href = data.page_url;
+        link.target = '_blank';
+        link.rel = 'noopener noreferrer';
+        link.style.cssText = 'font-size:13px;margin-left:8px';
+        link.textContent = T('Release notes');
+        updateAction.append(' ', link);
+      }
+      if (!data.download_url) {
+        const p = document.createElement('p');
+        p.className = 'muted';
+        p.style.margin = '6px 0 0';
+        p.textContent = T('No binary for this platform — install manually from the link above.');
+        updateAction.append(p);
+      }
     } else if (data.checked_at) {
-      updateStatus.textContent = 'Up to date (checked ' + data.checked_at + ')';
+      updateStatus.textContent = Tf('Up to date (checked %s)', data.checked_at);
       updateAction.innerHTML = '';
     }
   }
 
   async function installUpdate() {
-    updateStatus.textContent = 'Downloading and installing…';
+    updateStatus.textContent = T('Downloading and installing…');
     updateAction.innerHTML = '';
     try {
       const res = await fetch('/update/install', {method: 'POST'});
       if (!res.ok) throw new Error(await res.text());
-      updateStatus.textContent = 'Done! Restarting…';
+      updateStatus.textContent = T('Done! Restarting…');
     } catch (err) {
-      updateStatus.textContent = 'Install error: ' + err.message;
+      updateStatus.textContent = Tf('Install error: %s', err.message);
     }
   }
 
@@ -1614,7 +2547,7 @@ func renderSettingsHTML(cfg hotkeys.KeyConfig) string {
 
   checkBtn.addEventListener('click', async () => {
     checkBtn.disabled = true;
-    updateStatus.textContent = 'Checking…';
+    updateStatus.textContent = T('Checking…');
     updateAction.innerHTML = '';
     try {
       await fetch('/update/check', {method: 'POST'}); // trigger background check
@@ -1633,7 +2566,7 @@ func renderSettingsHTML(cfg hotkeys.KeyConfig) string {
         if (attempts > 15) { clearInterval(poll); checkBtn.disabled = false; }
       }, 700);
     } catch (err) {
-      updateStatus.textContent = 'Error: ' + err.message;
+      updateStatus.textContent = Tf('Error: %s', err.message);
       checkBtn.disabled = false;
     }
   });
@@ -1643,13 +2576,408 @@ func renderSettingsHTML(cfg hotkeys.KeyConfig) string {
 	return b.String()
 }
 
+// handleTag shows a read-only list of tasks carrying the given tag.
+func (s *Server) handleTag(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	tag := strings.TrimSpace(r.URL.Query().Get("name"))
+	if tag == "" {
+		http.Error(w, "tag required", http.StatusBadRequest)
+		return
+	}
+	all := s.q.GetAll()
+	var b strings.Builder
+	b.WriteString(`<h1>` + trf("Tasks tagged #%s", tag) + `</h1>`)
+	b.WriteString(`<div class="row"><button onclick="location.href='/'">` + tr("Manage order") + `</button></div>`)
+	n := 0
+	for i, t := range all {
+		if !t.HasTag(tag) {
+			continue
+		}
+		n++
+		frag, err := s.renderTaskBody(t)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		b.WriteString(fmt.Sprintf(`<div class="card"><div class="muted">%s%s</div>%s%s</div>`,
+			trf("#%d in queue", i+1), renderTagsHTML(t.Tags), renderDueHTML(t), frag))
+	}
+	if n == 0 {
+		b.WriteString(`<p class="muted">` + tr("No tasks with this tag.") + `</p>`)
+	}
+	b.WriteString(`<script>` + checklistJS + attachmentLinkJS + `</script>`)
+	page := ui.RenderPage("#"+tag, b.String())
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	io.WriteString(w, page)
+}
+
+// listPageSize is the number of rows /list shows per page.
+const listPageSize = 25
+
+// handleList shows the whole queue as a table. ?sort= picks a display order
+// (see queue.SortOrders); only queue order allows dragging rows, since the
+// rows must match the queue for /reorder to make sense.
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	esc := func(s string) string {
+		return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&#39;").Replace(s)
+	}
+	tasks := s.q.GetAll()
+	order := queue.ParseSortOrder(r.URL.Query().Get("sort"))
+	pageNum, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	rows, pageNum, pages := queue.Paginate(queue.Sorted(tasks, order), pageNum, listPageSize)
+	offset := (pageNum - 1) * listPageSize
+	// # always shows the position in the queue, whatever the display order.
+	pos := make(map[string]int, len(tasks))
+	for i, t := range tasks {
+		pos[t.ID] = i + 1
+	}
+	var b strings.Builder
+	b.WriteString(`<h1>` + trf("Queue (%d)", len(tasks)) + `</h1>`)
+	b.WriteString(`<div class="row"><button onclick="location.href='/'">` + tr("Manage order") + `</button><button onclick="location.href='/add'">` + tr("Add") + `</button></div>`)
+	if len(tasks) > 1 {
+		b.WriteString(`<div class="row"><span class="muted">` + tr("Sort:") + `</span>`)
+		for _, o := range queue.SortOrders {
+			if o.Order == order {
+				b.WriteString(fmt.Sprintf(`<button disabled>%s</button>`, tr(o.Label)))
+			} else {
+				b.WriteString(fmt.Sprintf(`<button onclick="location.href='/list?sort=%s'">%s</button>`, o.Order, tr(o.Label)))
+			}
+		}
+		b.WriteString(`</div>`)
+	}
+	draggable := order == queue.SortFIFO
+	cfg, _, _ := hotkeys.LoadOrCreate(s.baseDir)
+	compact := cfg.IsCompactList()
+	if len(tasks) == 0 {
+		b.WriteString(`<p class="muted">` + tr("The queue is empty.") + `</p>`)
+	} else {
+		now := time.Now()
+		b.WriteString(`<style>
+tr.age-new td:first-child{border-left:4px solid #34c759}
+tr.age-week td:first-child{border-left:4px solid #ffcc00}
+tr.age-old td:first-child{border-left:4px solid #ff3b30}
+tr.overdue{background:#fff1f0}
+.legend span{display:inline-block;margin-right:14px}
+.legend i{display:inline-block;width:10px;height:10px;border-radius:2px;margin-right:5px;vertical-align:middle}
+#rows tr[draggable=true]{cursor:grab}
+#rows tr.dragging{opacity:.4}
+</style>`)
+		if compact {
+			// The cells carry inline padding, hence !important.
+			b.WriteString(`<style>
+#queue-table{font-size:12px !important;line-height:1.3}
+#queue-table th,#queue-table td{padding:2px 6px !important}
+#queue-table button{padding:2px 8px;font-size:12px}
+</style>`)
+		}
+		total, estimated := 0, 0
+		for _, t := range tasks {
+			if t.EstimateMinutes > 0 {
+				total += t.EstimateMinutes
+				estimated++
+			}
+		}
+		if estimated > 0 {
+			b.WriteString(fmt.Sprintf(`<p><b>%s</b> <span class="muted">(%s)</span></p>`, trf("About %s of work queued", queue.FormatEstimate(total)), trf("%d of %d tasks estimated", estimated, len(tasks))))
+		}
+		b.WriteString(`<p class="muted legend"><span><i style="background:#34c759"></i>` + tr("under a day") + `</span><span><i style="background:#ffcc00"></i>` + tr("under a week") + `</span><span><i style="background:#ff3b30"></i>` + tr("older") + `</span><span><i style="background:#fff1f0;border:1px solid #c00"></i>` + tr("overdue") + `</span></p>`)
+		pager := ""
+		if pages > 1 {
+			link := func(p int, label string) string {
+				if p < 1 || p > pages {
+					return fmt.Sprintf(`<button disabled>%s</button>`, label)
+				}
+				return fmt.Sprintf(`<button onclick="location.href='/list?sort=%s&amp;page=%d'">%s</button>`, order, p, label)
+			}
+			pager = fmt.Sprintf(`<div class="row">%s<span class="muted">%s</span>%s</div>`,
+				link(pageNum-1, "‹ "+tr("Prev")), trf("Page %d of %d · tasks %d–%d of %d", pageNum, pages, offset+1, offset+len(rows), len(tasks)), link(pageNum+1, tr("Next")+" ›"))
+		}
+		b.WriteString(pager)
+		b.WriteString(`<table id="queue-table" style="width:100%;border-collapse:collapse;font-size:14px">`)
+		b.WriteString(`<thead><tr class="muted" style="text-align:left"><th style="padding:6px 8px">#</th><th style="padding:6px 8px">` + tr("Created") + `</th><th style="padding:6px 8px">` + tr("Due") + `</th><th style="padding:6px 8px">` + tr("Est.") + `</th><th style="padding:6px 8px">` + tr("Task") + `</th><th style="padding:6px 8px"></th></tr></thead><tbody id="rows">`)
+		for _, t := range rows {
+			prev := []rune(t.Text)
+			if idx := strings.IndexByte(t.Text, '\n'); idx >= 0 {
+				prev = []rune(t.Text[:idx])
+			}
+			if len(prev) > 120 {
+				prev = append(prev[:120], '…')
+			}
+			// Compact rows show only the count, no thumbnails.
+			clip := ""
+			if !compact {
+				clip = s.listThumbsHTML(t.Attachments)
+			}
+			if n := len(t.Attachments); n > 0 {
+				clip += fmt.Sprintf(`<span title="%s">📎%d</span>`, trf("%d attachment(s)", n), n)
+			}
+			class := ageClass(t.CreatedAt, now)
+			due := ""
+			if t.DueDate != nil {
+				due = t.DueDate.Local().Format("02 Jan 2006, 15:04")
+				if t.IsOverdue(now) {
+					class += " overdue"
+					due = `<span style="color:#c00">` + due + ` · ` + tr("overdue") + `</span>`
+				}
+			}
+			if t.IsSnoozed(now) {
+				if due != "" {
+					due += "<br>"
+				}
+				due += "💤 " + trf("until %s", t.SnoozedUntil.Local().Format("02 Jan, 15:04"))
+			}
+			if open := queue.OpenBlockers(t, tasks); len(open) > 0 {
+				nums := make([]string, len(open))
+				for i, id := range open {
+					nums[i] = fmt.Sprintf("#%d", pos[id])
+				}
+				if due != "" {
+					due += "<br>"
+				}
+				due += "⛓ " + trf("waits for %s", strings.Join(nums, ", "))
+			}
+			b.WriteString(fmt.Sprintf(`<tr class="%s" draggable="%t" data-id="%s" style="border-top:1px solid #eee"><td class="pos" style="padding:6px 8px;vertical-align:top">%d</td><td class="muted" style="padding:6px 8px;vertical-align:top;white-space:nowrap" title="%s">%s</td><td class="muted" style="padding:6px 8px;vertical-align:top;white-space:nowrap">%s</td><td class="muted" style="padding:6px 8px;vertical-align:top;white-space:nowrap">%s</td><td style="padding:6px 8px;%s">%s%s%s%s%s%s</td><td style="padding:6px 8px;white-space:nowrap">%s <button onclick="viewRow(this)">%s</button></td></tr>`,
+				class, draggable, esc(t.ID), pos[t.ID], t.CreatedAt.Local().Format("02 Jan 2006, 15:04")+" · "+formatAge(now.Sub(t.CreatedAt)), humanizeSince(t.CreatedAt, now), due, queue.FormatEstimate(t.EstimateMinutes),
+				colorStyle(t, 4), pinMarker(t), priorityMarker(t.Priority), recurrenceMarker(t.Recurrence), checklistMarker(t), esc(string(prev)), renderTagsHTML(t.Tags), clip, tr("View")))
+		}
+		b.WriteString(`</tbody></table>`)
+		b.WriteString(pager)
+		// Read the position from the row so it stays right after dragging.
+		b.WriteString(`<script>
+function viewRow(btn){
+  const pos = parseInt(btn.closest('tr').querySelector('td.pos').textContent, 10);
+  location.href = '/view?index=' + (pos - 1);
+}
+</script>`)
+		if !draggable {
+			b.WriteString(`<p class="muted">` + fmt.Sprintf(tr("Sorted for display only; the queue order is unchanged. %s to drag rows."), `<a href="/list">`+tr("Switch to queue order")+`</a>`) + `</p>`)
+		} else {
+			if pages > 1 {
+				b.WriteString(`<p class="muted">` + tr("Drag rows to reorder them within this page.") + ` <span id="status"></span></p>`)
+			} else {
+				b.WriteString(`<p class="muted">` + tr("Drag rows to reorder the queue.") + ` <span id="status"></span></p>`)
+			}
+			// /reorder wants the whole queue: the rows of this page are
+			// spliced into the full order at the page's offset.
+			all := make([]string, len(tasks))
+			for i, t := range tasks {
+				all[i] = t.ID
+			}
+			allJSON, _ := json.Marshal(all)
+			b.WriteString(fmt.Sprintf(`<script>const allIDs = %s, pageOffset = %d;</script>`, allJSON, offset))
+			b.WriteString(`<script>
+const rows = document.getElementById('rows');
+const status = document.getElementById('status');
+let dragging = null;
+rows.addEventListener('dragstart', e => {
+  dragging = e.target.closest('tr');
+  if (!dragging) return;
+  dragging.classList.add('dragging');
+  e.dataTransfer.effectAllowed = 'move';
+  e.dataTransfer.setData('text/plain', dragging.dataset.id);
+});
+rows.addEventListener('dragover', e => {
+  e.preventDefault();
+  const over = e.target.closest('tr');
+  if (!over || !dragging || over === dragging) return;
+  const rect = over.getBoundingClientRect();
+  rows.insertBefore(dragging, (e.clientY - rect.top) < rect.height / 2 ? over : over.nextSibling);
+});
+rows.addEventListener('dragend', async () => {
+  if (!dragging) return;
+  dragging.classList.remove('dragging');
+  dragging = null;
+  const pageIDs = [...rows.querySelectorAll('tr')].map(tr => tr.dataset.id);
+  const ids = allIDs.slice(0, pageOffset).concat(pageIDs, allIDs.slice(pageOffset + pageIDs.length));
+  status.textContent = T('Saving…');
+  const res = await fetch('/reorder', {method:'POST', headers:{'Content-Type':'application/json'}, body: JSON.stringify({ids})});
+  if (!res.ok) {
+    // The queue changed elsewhere (409) or saving failed: show the real order.
+    alert(await res.text());
+    location.reload();
+    return;
+  }
+  allIDs.splice(pageOffset, pageIDs.length, ...pageIDs);
+  rows.querySelectorAll('td.pos').forEach((td, i) => { td.textContent = pageOffset + i + 1; });
+  status.textContent = T('Saved');
+  setTimeout(() => { status.textContent = ''; }, 1500);
+});
+</script>`)
+		}
+	}
+	page := ui.RenderPage(i18n.T("Queue"), b.String())
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	io.WriteString(w, page)
+}
+
+// listThumbsHTML shows the thumbnails of a task's image attachments, with
+// their dimensions on hover, and the length of its audio attachments. The
+// full images are only loaded on the task view; an image without a thumbnail
+// (it could not be decoded) gets a generic icon instead.
+func (s *Server) listThumbsHTML(as []queue.Attachment) string {
+	var b strings.Builder
+	for _, a := range as {
+		if a.Type == queue.AttachmentAudio && a.MetaLabel() != "" {
+			b.WriteString(`<span class="muted" style="margin-right:6px;vertical-align:middle;white-space:nowrap">🔊 ` + a.MetaLabel() + `</span>`)
+			continue
+		}
+		if a.Type != queue.AttachmentImage || a.Path == "" {
+			continue
+		}
+		if !queue.AttachmentExists(a) {
+			b.WriteString(`<span title="` + tr("attachment file is missing") + `" style="font-size:28px;margin-right:4px;vertical-align:middle">⚠️</span>`)
+			continue
+		}
+		thumb := queue.ThumbPath(a.Path)
+		if _, err := os.Stat(thumb); err != nil {
+			b.WriteString(`<span title="` + tr("image") + `" style="font-size:28px;margin-right:4px;vertical-align:middle">🖼</span>`)
+			continue
+		}
+		b.WriteString(fmt.Sprintf(`<img src="/attachment?name=%s" loading="lazy" alt="" title="%s" style="max-height:48px;max-width:64px;border-radius:4px;border:1px solid #ddd;margin-right:4px;vertical-align:middle">`, url.QueryEscape(s.q.AttachmentName(thumb)), a.MetaLabel()))
+	}
+	return b.String()
+}
+
+// ageClass buckets a task by how long it has been queued: under a day, under
+// a week, or older.
+func ageClass(created, now time.Time) string {
+	switch age := now.Sub(created); {
+	case age < 24*time.Hour:
+		return "age-new"
+	case age < 7*24*time.Hour:
+		return "age-week"
+	}
+	return "age-old"
+}
+
+// humanizeSince describes how long ago t was relative to now in the active
+// language: "just now", "5 minutes ago", "yesterday", "3 days ago" (or
+// "только что", "5 минут назад", "вчера", "3 дня назад"). Times in the
+// future count as "just now".
+func humanizeSince(t, now time.Time) string {
+	// ago counts n units; Russian needs three noun forms, English two.
+	ago := func(n int, unit, one, few, many string) string {
+		if i18n.Lang() == "ru" {
+			return fmt.Sprintf("%d %s назад", n, ruPlural(n, one, few, many))
+		}
+		if n != 1 {
+			unit += "s"
+		}
+		return fmt.Sprintf("%d %s ago", n, unit)
+	}
+	d := now.Sub(t)
+	if d < time.Minute {
+		return i18n.T("just now")
+	}
+	if d < time.Hour {
+		return ago(int(d/time.Minute), "minute", "минуту", "минуты", "минут")
+	}
+	tl, nl := t.Local(), now.Local()
+	day := func(x time.Time) time.Time { return time.Date(x.Year(), x.Month(), x.Day(), 0, 0, 0, 0, time.Local) }
+	// Whole calendar days, so 20:00 yesterday is "yesterday" at 09:00; only
+	// the last few hours before midnight still count in hours.
+	days := int(day(nl).Sub(day(tl)).Hours()+12) / 24
+	switch {
+	case days == 0 || d < 6*time.Hour:
+		return ago(int(d/time.Hour), "hour", "час", "часа", "часов")
+	case days == 1:
+		return i18n.T("yesterday")
+	case days < 7:
+		return ago(days, "day", "день", "дня", "дней")
+	case days < 30:
+		return ago(days/7, "week", "неделю", "недели", "недель")
+	case days < 365:
+		return ago(days/30, "month", "месяц", "месяца", "месяцев")
+	}
+	return ago(days/365, "year", "год", "года", "лет")
+}
+
+// ruPlural picks the Russian noun form for n: one (1, 21), few (2–4, 22–24)
+// or many (5–20, 25–30, …).
+func ruPlural(n int, one, few, many string) string {
+	switch n10, n100 := n%10, n%100; {
+	case n10 == 1 && n100 != 11:
+		return one
+	case n10 >= 2 && n10 <= 4 && (n100 < 12 || n100 > 14):
+		return few
+	}
+	return many
+}
+
+// renderCreatedHTML shows when the task was added, relative, with the exact
+// time on hover.
+func renderCreatedHTML(t queue.Task, now time.Time) string {
+	return fmt.Sprintf(`<p class="muted">%s <span title="%s">%s</span></p>`,
+		tr("Added"), t.CreatedAt.Local().Format("02 Jan 2006, 15:04:05"), humanizeSince(t.CreatedAt, now))
+}
+
+// formatAge describes a queue age for a tooltip, e.g. "3d 4h in queue".
+func formatAge(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return i18n.Tf("%dd %dh in queue", int(d.Hours())/24, int(d.Hours())%24)
+	case d >= time.Hour:
+		return i18n.Tf("%dh %dm in queue", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return i18n.Tf("%dm in queue", max(int(d.Minutes()), 0))
+}
+
+// handleSearch shows a read-only list of tasks matching the query, with their
+// positions in the queue.
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	esc := func(s string) string {
+		return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(s)
+	}
+	all := s.q.GetAll()
+	var b strings.Builder
+	b.WriteString(`<h1>` + tr("Search") + `</h1>`)
+	b.WriteString(fmt.Sprintf(`<form class="row" action="/search" method="get">
+  <input type="search" name="q" value="%s" autofocus style="flex:1;padding:8px 10px;border:1px solid #ccc;border-radius:8px;font-size:14px">
+  <button type="submit">%s</button>
+  <button type="button" onclick="location.href='/'">%s</button>
+</form>`, esc(query), tr("Search"), tr("Manage order")))
+	n := 0
+	for i, t := range all {
+		if !t.Matches(query) {
+			continue
+		}
+		n++
+		frag, err := s.renderTaskBody(t)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		b.WriteString(fmt.Sprintf(`<div class="card"><div class="muted">%s%s</div>%s%s</div>`,
+			trf("#%d in queue", i+1), renderTagsHTML(t.Tags), renderDueHTML(t), frag))
+	}
+	if query != "" && n == 0 {
+		b.WriteString(`<p class="muted">` + tr("No matching tasks.") + `</p>`)
+	}
+	b.WriteString(`<script>` + checklistJS + attachmentLinkJS + `</script>`)
+	page := ui.RenderPage(i18n.T("Search"), b.String())
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	io.WriteString(w, page)
+}
+
 func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 	entries := s.q.History().GetAll()
-	page := ui.RenderPage("History", renderHistoryHTML(entries))
+	page := ui.RenderPage(i18n.T("History"), renderHistoryHTML(entries))
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	io.WriteString(w, page)
 }
@@ -1674,6 +3002,33 @@ func (s *Server) handleHistoryDelete(w http.ResponseWriter, r *http.Request) {
 	io.WriteString(w, `{"ok":true}`)
 }
 
+// handleHistoryRequeue puts a completed task back into the queue as a new
+// task and removes it from history.
+func (s *Server) handleHistoryRequeue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad json", http.StatusBadRequest)
+		return
+	}
+	_, missing, err := s.q.Requeue(req.ID)
+	if err != nil {
+		status := http.StatusNotFound
+		if errors.Is(err, queue.ErrQueueFull) {
+			status = http.StatusTooManyRequests
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	fmt.Fprintf(w, `{"ok":true,"missing":%d}`, missing)
+}
+
 func (s *Server) handleHistoryClear(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -1687,6 +3042,78 @@ func (s *Server) handleHistoryClear(w http.ResponseWriter, r *http.Request) {
 	io.WriteString(w, `{"ok":true}`)
 }
 
+// statsDays is how many days the completion chart covers.
+const statsDays = 30
+
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	st := s.q.History().Stats(time.Now(), statsDays)
+	page := ui.RenderPage(i18n.T("Statistics"), renderStatsHTML(st))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	io.WriteString(w, page)
+}
+
+// renderStatsHTML shows the totals and an inline SVG bar chart of
+// completions per day; there is no charting library on the page.
+func renderStatsHTML(st queue.CompletionStats) string {
+	var b strings.Builder
+	b.WriteString(`<div class="row" style="margin-bottom:16px"><button onclick="location.href='/'">← ` + tr("Back") + `</button><button onclick="location.href='/history'">` + tr("History") + `</button></div>`)
+
+	b.WriteString(`<div style="display:flex;gap:12px;flex-wrap:wrap;margin-bottom:20px">`)
+	for _, c := range []struct {
+		label string
+		n     int
+	}{{"Today", st.Today}, {"This week", st.Week}, {"This month", st.Month}, {"Total in history", st.Total}} {
+		b.WriteString(fmt.Sprintf(`<div style="border:1px solid #ddd;border-radius:8px;padding:10px 16px;min-width:110px"><div class="muted" style="font-size:12px">%s</div><div style="font-size:24px;font-weight:600">%d</div></div>`, tr(c.label), c.n))
+	}
+	b.WriteString(`</div>`)
+
+	maxCount := 0
+	for _, d := range st.Days {
+		maxCount = max(maxCount, d.Count)
+	}
+	if maxCount == 0 {
+		b.WriteString(`<p class="muted">` + trf("No tasks completed in the last %d days.", len(st.Days)) + `</p>`)
+		return b.String()
+	}
+
+	const (
+		barW   = 18
+		gap    = 4
+		chartH = 160
+		labelH = 18
+	)
+	width := len(st.Days) * (barW + gap)
+	b.WriteString(`<h2 style="font-size:15px;margin:0 0 8px;color:#555">` + trf("Completed in the last %d days", len(st.Days)) + `</h2>`)
+	b.WriteString(fmt.Sprintf(`<svg width="%d" height="%d" viewBox="0 0 %d %d" style="max-width:100%%;font:10px sans-serif">`, width, chartH+labelH*2, width, chartH+labelH*2))
+	for i, d := range st.Days {
+		x := i * (barW + gap)
+		h := d.Count * chartH / maxCount
+		y := labelH + chartH - h
+		fill := "#4a90d9"
+		if d.Day.Weekday() == time.Saturday || d.Day.Weekday() == time.Sunday {
+			fill = "#8fb8e6"
+		}
+		b.WriteString(fmt.Sprintf(`<g><title>%s: %d</title>`, d.Day.Format("Mon 02 Jan"), d.Count))
+		if h > 0 {
+			b.WriteString(fmt.Sprintf(`<rect x="%d" y="%d" width="%d" height="%d" rx="2" fill="%s"/>`, x, y, barW, h, fill))
+			b.WriteString(fmt.Sprintf(`<text x="%d" y="%d" text-anchor="middle" fill="#333">%d</text>`, x+barW/2, y-4, d.Count))
+		} else {
+			b.WriteString(fmt.Sprintf(`<rect x="%d" y="%d" width="%d" height="1" fill="#ccc"/>`, x, labelH+chartH-1, barW))
+		}
+		// Label every Monday and the last day so the axis stays readable.
+		if d.Day.Weekday() == time.Monday || i == len(st.Days)-1 {
+			b.WriteString(fmt.Sprintf(`<text x="%d" y="%d" text-anchor="middle" fill="#777">%s</text>`, x+barW/2, labelH*2+chartH-4, d.Day.Format("02.01")))
+		}
+		b.WriteString(`</g>`)
+	}
+	b.WriteString(`</svg>`)
+	return b.String()
+}
+
 func renderHistoryHTML(entries []queue.Task) string {
 	esc := func(s string) string {
 		return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(s)
@@ -1741,9 +3168,9 @@ func renderHistoryHTML(entries []queue.Task) string {
 			var label string
 			switch key {
 			case today:
-				label = "Сегодня"
+				label = i18n.T("Today")
 			case yesterday:
-				label = "Вчера"
+				label = i18n.T("Yesterday")
 			default:
 				if ref.After(weekAgo) {
 					label = ref.Local().Format("Monday, 02 Jan")
@@ -1762,14 +3189,14 @@ func renderHistoryHTML(entries []queue.Task) string {
 
 	var b strings.Builder
 	if len(entries) == 0 {
-		b.WriteString(`<p class="muted">История пуста.</p>`)
-		b.WriteString(`<div class="row"><button onclick="location.href='/'">Назад</button></div>`)
+		b.WriteString(`<p class="muted">` + tr("History is empty.") + `</p>`)
+		b.WriteString(`<div class="row"><button onclick="location.href='/'">` + tr("Back") + `</button></div>`)
 		return b.String()
 	}
 
 	b.WriteString(`<div class="row" style="margin-bottom:16px">`)
-	b.WriteString(`<button onclick="location.href='/'">← Назад</button>`)
-	b.WriteString(`<button id="clear-all" style="color:#c00;border-color:#c00">Очистить всю историю</button>`)
+	b.WriteString(`<button onclick="location.href='/'">← ` + tr("Back") + `</button>`)
+	b.WriteString(`<button id="clear-all" style="color:#c00;border-color:#c00">` + tr("Clear all history") + `</button>`)
 	b.WriteString(`</div>`)
 
 	for _, g := range groups {
@@ -1789,17 +3216,19 @@ func renderHistoryHTML(entries []queue.Task) string {
 			var timeLine string
 			if !e.StartedAt.IsZero() && !e.CompletedAt.IsZero() {
 				dur := fmtDuration(e.CompletedAt.Sub(e.StartedAt))
-				timeLine = fmt.Sprintf(`<span class="ts">Начало: %s · Конец: %s · %s</span>`,
-					fmtTime(e.StartedAt), fmtTime(e.CompletedAt), dur)
+				timeLine = `<span class="ts">` + trf("Started: %s · Finished: %s · %s", fmtTime(e.StartedAt), fmtTime(e.CompletedAt), dur) + `</span>`
 			} else if !e.CompletedAt.IsZero() {
-				timeLine = fmt.Sprintf(`<span class="ts">Завершено: %s</span>`, fmtTime(e.CompletedAt))
+				timeLine = `<span class="ts">` + trf("Completed: %s", fmtTime(e.CompletedAt)) + `</span>`
 			} else {
-				timeLine = fmt.Sprintf(`<span class="ts">Создано: %s</span>`, fmtTime(e.CreatedAt))
+				timeLine = `<span class="ts">` + trf("Created: %s", fmtTime(e.CreatedAt)) + `</span>`
 			}
 
 			b.WriteString(fmt.Sprintf(`<div class="history-item" data-id="%s">`, esc(e.ID)))
 			b.WriteString(fmt.Sprintf(`<div class="history-text">%s</div>`, esc(preview)))
-			b.WriteString(fmt.Sprintf(`<div class="history-meta">%s<button class="del-btn" data-id="%s">×</button></div>`, timeLine, esc(e.ID)))
+			if e.CompletionNote != "" {
+				b.WriteString(fmt.Sprintf(`<div class="history-note">%s</div>`, esc(e.CompletionNote)))
+			}
+			b.WriteString(fmt.Sprintf(`<div class="history-meta">%s<span><button class="requeue-btn" data-id="%s" title="%s">↩ %s</button><button class="del-btn" data-id="%s">×</button></span></div>`, timeLine, esc(e.ID), tr("Return to queue"), tr("Return to queue"), esc(e.ID)))
 			b.WriteString(`</div>`)
 		}
 		b.WriteString(`</div>`)
@@ -1809,10 +3238,13 @@ func renderHistoryHTML(entries []queue.Task) string {
 .history-group{display:flex;flex-direction:column;gap:4px}
 .history-item{background:#fff;border:1px solid #e8e8e8;border-radius:8px;padding:10px 12px;display:flex;flex-direction:column;gap:4px}
 .history-text{font-size:14px;line-height:1.4}
+.history-note{font-size:13px;color:#555;border-left:3px solid #ddd;padding-left:8px;white-space:pre-wrap}
 .history-meta{display:flex;align-items:center;justify-content:space-between;gap:8px}
 .ts{font-size:12px;color:#888}
 .del-btn{background:none;border:none;cursor:pointer;font-size:16px;color:#bbb;padding:0 4px;line-height:1;border-radius:4px}
 .del-btn:hover{color:#c00;background:#fff0f0}
+.requeue-btn{background:none;border:none;cursor:pointer;font-size:12px;color:#888;padding:2px 6px;border-radius:4px}
+.requeue-btn:hover{color:#1a73e8;background:#eef4fe}
 </style>`)
 
 	b.WriteString(`<script>
@@ -1828,19 +3260,37 @@ document.querySelectorAll('.del-btn').forEach(btn => {
       });
       if (!res.ok) throw new Error(await res.text());
       item.remove();
-    } catch (e) { alert('Ошибка: ' + e.message); }
+    } catch (e) { alert(T('Error: ') + e.message); }
+  });
+});
+
+document.querySelectorAll('.requeue-btn').forEach(btn => {
+  btn.addEventListener('click', async () => {
+    const id = btn.dataset.id;
+    const item = document.querySelector('.history-item[data-id="' + id + '"]');
+    try {
+      const res = await fetch('/history/requeue', {
+        method: 'POST',
+        headers: {'Content-Type': 'application/json'},
+        body: JSON.stringify({id})
+      });
+      if (!res.ok) throw new Error(await res.text());
+      const data = await res.json();
+      if (data.missing > 0) alert(T('The task is back in the queue, but some attachments are gone: ') + data.missing);
+      item.remove();
+    } catch (e) { alert(T('Error: ') + e.message); }
   });
 });
 
 const clearBtn = document.getElementById('clear-all');
 if (clearBtn) {
   clearBtn.addEventListener('click', async () => {
-    if (!confirm('Удалить всю историю?')) return;
+    if (!confirm(T('Delete all history?'))) return;
     try {
       const res = await fetch('/history/clear', {method: 'POST'});
       if (!res.ok) throw new Error(await res.text());
       location.reload();
-    } catch (e) { alert('Ошибка: ' + e.message); }
+    } catch (e) { alert(T('Error: ') + e.message); }
   });
 }
 </script>`)
@@ -0,0 +1,448 @@
+package manage
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ameight/systray-queue-app/internal/i18n"
+	"github.com/Ameight/systray-queue-app/internal/queue"
+)
+
+func newTestServer(t *testing.T) (*Server, *queue.TaskQueue, string) {
+	t.Helper()
+	t.Setenv(queue.EnvPassphrase, "")
+	t.Setenv(queue.EnvBackend, "")
+	dir := t.TempDir()
+	q, err := queue.NewTaskQueue(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = q.Close() })
+	s := New(q, dir, nil)
+	raw, err := s.URL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, q, u.Host
+}
+
+// startAction sends the headers and the first half of a POST /task_action
+// body, so the handler is left waiting for the rest.
+func startAction(t *testing.T, addr, body string) (net.Conn, string) {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	half := len(body) / 2
+	fmt.Fprintf(conn, "POST /task_action HTTP/1.1\r\nHost: %s\r\nContent-Type: application/json\r\nContent-Length: %d\r\n\r\n%s", addr, len(body), body[:half])
+	time.Sleep(100 * time.Millisecond) // let the server start reading
+	return conn, body[half:]
+}
+
+func TestShutdownWaitsForInFlightRequest(t *testing.T) {
+	s, q, addr := newTestServer(t)
+	if err := q.Enqueue(queue.Task{ID: "a", Text: "a", CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	conn, rest := startAction(t, addr, `{"id":"a","action":"done"}`)
+
+	shut := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		shut <- s.Shutdown(ctx)
+	}()
+	select {
+	case err := <-shut:
+		t.Fatalf("Shutdown returned %v with a request still open", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	if _, err := conn.Write([]byte(rest)); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("in-flight request got %s", resp.Status)
+	}
+	select {
+	case err := <-shut:
+		if err != nil {
+			t.Fatalf("Shutdown = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Shutdown did not return after the last request finished")
+	}
+	if n := q.Count(); n != 0 {
+		t.Fatalf("queue has %d tasks, want the task completed during shutdown", n)
+	}
+}
+
+func TestShutdownStopsWithinTimeout(t *testing.T) {
+	s, _, addr := newTestServer(t)
+	startAction(t, addr, `{"id":"a","action":"done"}`)
+
+	const timeout = 300 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	start := time.Now()
+	err := s.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown = %v, want context.DeadlineExceeded", err)
+	}
+	if took := time.Since(start); took > timeout+time.Second {
+		t.Fatalf("Shutdown took %v with a %v timeout", took, timeout)
+	}
+	if _, err := net.DialTimeout("tcp", addr, 500*time.Millisecond); err == nil {
+		t.Fatal("server still accepts connections after Shutdown")
+	}
+}
+
+func TestShutdownBeforeStart(t *testing.T) {
+	t.Setenv(queue.EnvPassphrase, "")
+	t.Setenv(queue.EnvBackend, "")
+	dir := t.TempDir()
+	q, err := queue.NewTaskQueue(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+	s := New(q, dir, nil)
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.URL(); !errors.Is(err, http.ErrServerClosed) {
+		t.Fatalf("URL after Shutdown = %v, want http.ErrServerClosed", err)
+	}
+}
+
+// setLang switches the UI language for the length of the test.
+func setLang(t *testing.T, lang string) {
+	prev := i18n.Lang()
+	i18n.SetLang(lang)
+	t.Cleanup(func() { i18n.SetLang(prev) })
+}
+
+func TestHumanizeSince(t *testing.T) {
+	setLang(t, "ru")
+	// Late evening, so the last day's hours are still counted as today.
+	now := time.Date(2026, 6, 10, 23, 30, 0, 0, time.Local)
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{-time.Hour, "только что"},
+		{0, "только что"},
+		{59 * time.Second, "только что"},
+		{60 * time.Second, "1 минуту назад"},
+		{2 * time.Minute, "2 минуты назад"},
+		{5 * time.Minute, "5 минут назад"},
+		{21 * time.Minute, "21 минуту назад"},
+		{59 * time.Minute, "59 минут назад"},
+		{60 * time.Minute, "1 час назад"},
+		{3 * time.Hour, "3 часа назад"},
+		{23 * time.Hour, "23 часа назад"},
+		{24 * time.Hour, "вчера"},
+		{47 * time.Hour, "вчера"},
+		{48 * time.Hour, "2 дня назад"},
+		{6 * 24 * time.Hour, "6 дней назад"},
+		{7 * 24 * time.Hour, "1 неделю назад"},
+		{29 * 24 * time.Hour, "4 недели назад"},
+		{30 * 24 * time.Hour, "1 месяц назад"},
+		{364 * 24 * time.Hour, "12 месяцев назад"},
+		{365 * 24 * time.Hour, "1 год назад"},
+		{5 * 365 * 24 * time.Hour, "5 лет назад"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ago.String(), func(t *testing.T) {
+			if got := humanizeSince(now.Add(-tt.ago), now); got != tt.want {
+				t.Fatalf("humanizeSince(now-%v) = %q, want %q", tt.ago, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHumanizeSinceEnglish(t *testing.T) {
+	setLang(t, "en")
+	now := time.Date(2026, 6, 10, 23, 30, 0, 0, time.Local)
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{30 * time.Second, "just now"},
+		{time.Minute, "1 minute ago"},
+		{5 * time.Minute, "5 minutes ago"},
+		{time.Hour, "1 hour ago"},
+		{3 * time.Hour, "3 hours ago"},
+		{24 * time.Hour, "yesterday"},
+		{3 * 24 * time.Hour, "3 days ago"},
+		{7 * 24 * time.Hour, "1 week ago"},
+		{60 * 24 * time.Hour, "2 months ago"},
+		{2 * 365 * 24 * time.Hour, "2 years ago"},
+	}
+	for _, tt := range tests {
+		if got := humanizeSince(now.Add(-tt.ago), now); got != tt.want {
+			t.Errorf("humanizeSince(now-%v) = %q, want %q", tt.ago, got, tt.want)
+		}
+	}
+}
+
+func TestHumanizeSinceCalendarDays(t *testing.T) {
+	setLang(t, "ru")
+	// 20:00 yesterday is "вчера" the next morning, not "13 часов назад",
+	// but a few hours across midnight still count in hours.
+	morning := time.Date(2026, 6, 10, 9, 0, 0, 0, time.Local)
+	if got := humanizeSince(time.Date(2026, 6, 9, 20, 0, 0, 0, time.Local), morning); got != "вчера" {
+		t.Fatalf("20:00 yesterday at 09:00 = %q, want вчера", got)
+	}
+	night := time.Date(2026, 6, 10, 1, 0, 0, 0, time.Local)
+	if got := humanizeSince(time.Date(2026, 6, 9, 22, 0, 0, 0, time.Local), night); got != "3 часа назад" {
+		t.Fatalf("22:00 yesterday at 01:00 = %q, want 3 часа назад", got)
+	}
+}
+
+func TestRuPlural(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "дней"}, {1, "день"}, {2, "дня"}, {4, "дня"}, {5, "дней"},
+		{11, "дней"}, {12, "дней"}, {14, "дней"}, {21, "день"}, {22, "дня"},
+		{25, "дней"}, {101, "день"}, {111, "дней"}, {112, "дней"}, {122, "дня"},
+	}
+	for _, tt := range tests {
+		if got := ruPlural(tt.n, "день", "дня", "дней"); got != tt.want {
+			t.Errorf("ruPlural(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+// TestPageStringsTranslated checks that every string the pages look up,
+// in Go through tr/trf/i18n.T and in page scripts through T/Tf, has a
+// Russian translation.
+func TestPageStringsTranslated(t *testing.T) {
+	setLang(t, "ru")
+	src, err := os.ReadFile("server.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, m := range regexp.MustCompile(`\b(?:trf?|i18n\.Tf?)\(("(?:[^"\\]|\\.)*")`).FindAllSubmatch(src, -1) {
+		key, err := strconv.Unquote(string(m[1]))
+		if err != nil {
+			t.Fatalf("%s: %v", m[1], err)
+		}
+		keys = append(keys, key)
+	}
+	for _, m := range regexp.MustCompile(`\bTf?\('([^'\\]*)'`).FindAllSubmatch(src, -1) {
+		keys = append(keys, string(m[1]))
+	}
+	if len(keys) < 100 {
+		t.Fatalf("found only %d keys; has the lookup syntax changed?", len(keys))
+	}
+	for _, meta := range hotkeyMeta {
+		keys = append(keys, meta.Label)
+	}
+	for _, o := range queue.SortOrders {
+		keys = append(keys, o.Label)
+	}
+	for _, key := range keys {
+		if i18n.T(key) == key {
+			t.Errorf("no Russian translation for %q", key)
+		}
+	}
+}
+
+func TestReorderRejectsStaleOrder(t *testing.T) {
+	_, q, addr := newTestServer(t)
+	for _, id := range []string{"a", "b", "c"} {
+		if err := q.Enqueue(queue.Task{ID: id, Text: id, CreatedAt: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	post := func(body string) int {
+		t.Helper()
+		resp, err := http.Post("http://"+addr+"/reorder", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	order := func() string {
+		s := ""
+		for _, task := range q.GetAll() {
+			s += task.ID
+		}
+		return s
+	}
+	// A page rendered before b was completed elsewhere.
+	if _, err := q.CompleteByID("b"); err != nil {
+		t.Fatal(err)
+	}
+	for _, body := range []string{`{"ids":["c","b","a"]}`, `{"order":[1,0]}`} {
+		if code := post(body); code != http.StatusConflict {
+			t.Fatalf("POST %s = %d, want 409", body, code)
+		}
+		if got := order(); got != "ac" {
+			t.Fatalf("queue is %s after a stale reorder, want ac", got)
+		}
+	}
+	if code := post(`{"ids":["c","a"]}`); code != http.StatusOK {
+		t.Fatalf("POST current order = %d, want 200", code)
+	}
+	if got := order(); got != "ca" {
+		t.Fatalf("queue is %s, want ca", got)
+	}
+}
+
+func TestNoActionOnTheHeadTask(t *testing.T) {
+	_, q, addr := newTestServer(t)
+	if err := q.Enqueue(queue.Task{ID: "a", Text: "a", CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	// Actions must name their task (/task_action); the old /action acted on
+	// whatever was current.
+	for _, action := range []string{"done", "skip"} {
+		resp, err := http.Post("http://"+addr+"/action", "application/json", strings.NewReader(`{"action":"`+action+`"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if q.Count() != 1 || len(q.History().GetAll()) != 0 {
+		t.Fatalf("POST /action changed the queue: %d queued, %d in history", q.Count(), len(q.History().GetAll()))
+	}
+}
+
+// xssTask carries attribute break-outs in every field the pages print.
+var xssTask = queue.Task{
+	ID:        `id"><script>alert(1)</script>' onmouseover='alert(2)'`,
+	Text:      `text"><script>alert(3)</script>' onmouseover='alert(4)'`,
+	Tags:      []string{`tag"><script>alert(5)</script>`, `tag' onmouseover='alert(6)'`},
+	CreatedAt: time.Now(),
+}
+
+var scriptRe = regexp.MustCompile(`(?s)<script>.*?</script>`)
+
+// checkEscaped fails if a payload of xssTask got out of its attribute or text.
+// The page scripts are left out of the quote checks: IDs in them come from
+// json.Marshal, where a quote cannot end the string.
+func checkEscaped(t *testing.T, where, out string) {
+	t.Helper()
+	if strings.Contains(out, `<script>alert`) {
+		t.Errorf("%s contains a script from the task:\n%s", where, out)
+	}
+	markup := scriptRe.ReplaceAllString(out, "")
+	for _, bad := range []string{`"><script>`, `" onmouseover=`, `' onmouseover=`} {
+		if strings.Contains(markup, bad) {
+			t.Errorf("%s contains %q:\n%s", where, bad, out)
+		}
+	}
+}
+
+func TestPagesEscapeTaskFields(t *testing.T) {
+	setLang(t, "en")
+	checkEscaped(t, "renderManageHTML", renderManageHTML([]queue.Task{xssTask}))
+	checkEscaped(t, "renderTagsHTML", renderTagsHTML(xssTask.Tags))
+	checkEscaped(t, "renderBlockerSelect", renderBlockerSelect([]queue.Task{xssTask}))
+
+	_, q, addr := newTestServer(t)
+	if err := q.Enqueue(xssTask); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/", "/list"} {
+		resp, err := http.Get("http://" + addr + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(b), "text&#34;&gt;&lt;script&gt;") && !strings.Contains(string(b), "text&quot;&gt;&lt;script&gt;") {
+			t.Errorf("GET %s does not show the task", path)
+		}
+		checkEscaped(t, "GET "+path, string(b))
+	}
+}
+
+func TestTaskBodyEscapesAttachmentNames(t *testing.T) {
+	setLang(t, "en")
+	s, _, _ := newTestServer(t)
+	dir := t.TempDir()
+	task := xssTask
+	task.Attachments = nil
+	for _, name := range []string{`a"><script>alert(1).txt`, `b' onmouseover='alert(2)'.txt`, `c"><script>alert(3).bin`} {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		typ := queue.AttachmentFile
+		if strings.HasSuffix(name, ".bin") {
+			typ = "hologram" // a type from a newer version
+		}
+		task.Attachments = append(task.Attachments, queue.Attachment{Path: p, Type: typ})
+	}
+	// A missing file is named in the warning instead.
+	task.Attachments = append(task.Attachments, queue.Attachment{Path: filepath.Join(dir, `gone"><script>alert(4).txt`), Type: queue.AttachmentFile})
+
+	checkEscaped(t, "attachmentsMarkdown", s.attachmentsMarkdown(task))
+	out, err := s.renderTaskBody(task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkEscaped(t, "renderTaskBody", out)
+	if n := strings.Count(out, "#open-attachment/"); n != 3 {
+		t.Errorf("renderTaskBody has %d open links, want 3:\n%s", n, out)
+	}
+}
+
+func TestTaskPagesShareTheirScript(t *testing.T) {
+	_, q, addr := newTestServer(t)
+	if err := q.Enqueue(queue.Task{ID: "a", Text: "a", CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/view", "/view?index=0"} {
+		resp, err := http.Get("http://" + addr + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		page := string(b)
+		if strings.Contains(page, "%!") {
+			t.Errorf("GET %s has a broken format verb", path)
+		}
+		if strings.Count(page, taskPageJS) != 1 {
+			t.Errorf("GET %s does not include taskPageJS once", path)
+		}
+	}
+}
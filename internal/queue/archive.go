@@ -0,0 +1,193 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// TaskArchive holds tasks moved out of the queue by ArchiveOlderThan, kept
+// in archive.json next to queue.json, newest first. Unlike history it is for
+// tasks that were never done; RestoreArchived puts them back. Their
+// attachment files stay where they are.
+type TaskArchive struct {
+	mu       sync.Mutex
+	Entries  []Task `json:"entries"`
+	filePath string
+	box      *cipherBox
+}
+
+func newTaskArchive(baseDir string, box *cipherBox) (*TaskArchive, error) {
+	a := &TaskArchive{filePath: filepath.Join(baseDir, "archive.json"), box: box}
+	if err := a.load(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *TaskArchive) load() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	b, err := os.ReadFile(a.filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			a.Entries = nil
+			return nil
+		}
+		return err
+	}
+	if b, err = a.box.open(b); err != nil {
+		return fmt.Errorf("archive.json: %w", err)
+	}
+	var tmp struct {
+		Entries []Task `json:"entries"`
+	}
+	if err := json.Unmarshal(b, &tmp); err != nil {
+		return fmt.Errorf("archive.json: %w", err)
+	}
+	a.Entries = tmp.Entries
+	return nil
+}
+
+func (a *TaskArchive) saveLocked() error {
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return err
+	}
+	if data, err = a.box.seal(data); err != nil {
+		return err
+	}
+	return atomicWriteFile(a.filePath, data, 0644)
+}
+
+func (a *TaskArchive) GetAll() []Task {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	res := make([]Task, len(a.Entries))
+	copy(res, a.Entries)
+	return res
+}
+
+// Count returns the number of archived tasks.
+func (a *TaskArchive) Count() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.Entries)
+}
+
+// Archive returns the archived tasks, see ArchiveOlderThan.
+func (q *TaskQueue) Archive() *TaskArchive {
+	return q.archive
+}
+
+// ArchiveOlderThan moves every queued task created more than days days
+// before now to the archive, with one write of each file, and returns the
+// tasks moved. Pinned tasks stay. days <= 0 archives nothing. The archive is
+// written first: if the queue then cannot be saved, it is put back as it was
+// and no task is lost.
+func (q *TaskQueue) ArchiveOlderThan(days int, now time.Time) ([]Task, error) {
+	if days <= 0 {
+		return nil, nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	cutoff := now.AddDate(0, 0, -days)
+	var old []string
+	for _, t := range q.Tasks {
+		if !t.Pinned && t.CreatedAt.Before(cutoff) {
+			old = append(old, t.ID)
+		}
+	}
+	if len(old) == 0 {
+		return nil, nil
+	}
+	before, logged := q.Tasks, len(q.pending)
+	moved := q.takeLocked(old)
+	for i := range moved {
+		moved[i].ArchivedAt = now
+		q.recordLocked(EventDelete, moved[i].ID)
+	}
+
+	a := q.archive
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	prev := a.Entries
+	entries := make([]Task, 0, len(moved)+len(prev))
+	for i := len(moved) - 1; i >= 0; i-- {
+		entries = append(entries, moved[i])
+	}
+	a.Entries = append(entries, prev...)
+	if err := a.saveLocked(); err != nil {
+		a.Entries = prev
+		q.Tasks, q.pending = before, q.pending[:logged]
+		return nil, err
+	}
+	q.markActiveLocked()
+	if err := q.saveLocked(); err != nil {
+		a.Entries = prev
+		if rerr := a.saveLocked(); rerr != nil {
+			err = errors.Join(err, rerr)
+		}
+		return nil, err
+	}
+	q.emptiedLocked()
+	return moved, nil
+}
+
+// RestoreArchived puts the archived tasks with the given IDs back in the
+// queue, like EnqueueWithPriority, and removes them from the archive. Their
+// age counts from the restore, so the next ArchiveOlderThan leaves them be.
+// IDs that are not archived are ignored. Returns the number restored.
+func (q *TaskQueue) RestoreArchived(ids []string) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	a := q.archive
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	want := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+	var back, kept []Task
+	for _, t := range a.Entries {
+		if want[t.ID] {
+			back = append(back, t)
+		} else {
+			kept = append(kept, t)
+		}
+	}
+	if len(back) == 0 {
+		return 0, nil
+	}
+	if err := q.checkRoomLocked(len(back)); err != nil {
+		return 0, err
+	}
+	q.Tasks = append([]Task(nil), q.Tasks...)
+	now := time.Now()
+	for _, t := range back {
+		t.ArchivedAt = time.Time{}
+		t.SkippedAt = time.Time{}
+		t.StartedAt = time.Time{}
+		t.CreatedAt = now
+		q.insertByPriorityLocked(t)
+	}
+	q.markActiveLocked()
+	if err := q.saveLocked(); err != nil {
+		return 0, err
+	}
+	prev := a.Entries
+	a.Entries = kept
+	if err := a.saveLocked(); err != nil {
+		// The tasks are queued again; a stale copy in the archive is
+		// better than undoing that.
+		a.Entries = prev
+		return len(back), fmt.Errorf("archive.json: %w", err)
+	}
+	return len(back), nil
+}
@@ -0,0 +1,172 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Each queued task keeps its attachments in a folder of its own,
+// attachmentsDir/<task ID>/, under their original file names. New files are
+// first written directly into attachmentsDir ("staged", see StagedName) and
+// moved into the task's folder when the task is saved. Tasks from before
+// this layout have their files moved the same way when the queue is loaded.
+
+// taskDir is the attachment folder of task id. IDs are normally digits;
+// anything that could escape attachmentsDir is replaced.
+func (q *TaskQueue) taskDir(id string) string {
+	return filepath.Join(q.attachmentsDir, safeFileName(id))
+}
+
+// safeFileName keeps a file name usable on every OS: path separators,
+// characters Windows rejects and control characters become "_". Leading and
+// trailing dots and spaces are dropped; nothing left gives "file".
+func safeFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	name = strings.Trim(name, ". ")
+	if name == "" {
+		return "file"
+	}
+	return name
+}
+
+// StagedName returns a name for writing a new attachment into
+// AttachmentsDir before its task is saved. original, a path or file name,
+// is kept after a unique stamp, which is dropped again when the file moves
+// into the task's folder. The extension is lowercased.
+func StagedName(original string) string {
+	base := filepath.Base(original)
+	ext := filepath.Ext(base)
+	base = strings.TrimSuffix(base, ext) + strings.ToLower(ext)
+	return fmt.Sprintf("%d_%s", time.Now().UnixNano(), safeFileName(base))
+}
+
+// unstagedName strips the stamp StagedName puts in front of a name. Names
+// without one, such as the plain "<stamp>.png" of a pasted image, are kept.
+func unstagedName(name string) string {
+	i := strings.IndexByte(name, '_')
+	if i <= 0 || i == len(name)-1 {
+		return name
+	}
+	for _, c := range name[:i] {
+		if c < '0' || c > '9' {
+			return name
+		}
+	}
+	return name[i+1:]
+}
+
+// uniquePath returns dir/name, or "name (2).ext" and so on if that file, or
+// the thumbnail it would get, already exists.
+func uniquePath(dir, name string) string {
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	taken := func(p string) bool {
+		_, err := os.Stat(p)
+		_, terr := os.Stat(ThumbPath(p))
+		return !errors.Is(err, os.ErrNotExist) || !errors.Is(terr, os.ErrNotExist)
+	}
+	p := filepath.Join(dir, name)
+	for n := 2; taken(p); n++ {
+		p = filepath.Join(dir, fmt.Sprintf("%s (%d)%s", stem, n, ext))
+	}
+	return p
+}
+
+// placeAttachmentLocked moves a file lying directly in attachmentsDir into
+// the folder of task id, together with its thumbnail, and returns the new
+// path. Files anywhere else, including ones already in a task folder, are
+// left where they are. Caller holds q.mu.
+func (q *TaskQueue) placeAttachmentLocked(id, path string) (string, error) {
+	if path == "" || filepath.Dir(filepath.Clean(path)) != filepath.Clean(q.attachmentsDir) {
+		return path, nil
+	}
+	dir := q.taskDir(id)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	dst := uniquePath(dir, unstagedName(filepath.Base(path)))
+	if err := os.Rename(path, dst); err != nil {
+		return "", err
+	}
+	if err := os.Rename(ThumbPath(path), ThumbPath(dst)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("[queue] move thumbnail of %s: %v", filepath.Base(path), err)
+	}
+	return dst, nil
+}
+
+// migrateAttachmentsLocked moves the files of queued tasks and history
+// entries that still lie directly in attachmentsDir into per-task folders.
+// Reports whether a queued task changed and needs saving; history is saved
+// here. A file that cannot be moved keeps its old path. Caller holds q.mu.
+func (q *TaskQueue) migrateAttachmentsLocked() bool {
+	move := func(ts []Task) (changed bool) {
+		for i := range ts {
+			for j, a := range ts[i].Attachments {
+				if a.Path == "" || !AttachmentExists(a) {
+					continue
+				}
+				p, err := q.placeAttachmentLocked(ts[i].ID, a.Path)
+				if err != nil {
+					log.Printf("[queue] move attachment %s of task %s: %v", filepath.Base(a.Path), ts[i].ID, err)
+					continue
+				}
+				if p != a.Path {
+					ts[i].Attachments[j].Path = p
+					changed = true
+				}
+			}
+		}
+		return changed
+	}
+	if h := q.history; h != nil {
+		h.mu.Lock()
+		if move(h.Entries) {
+			if err := h.saveLocked(); err != nil {
+				log.Printf("[queue] history: %v", err)
+			}
+		}
+		h.mu.Unlock()
+	}
+	if move(q.Tasks) {
+		log.Printf("[queue] moved attachments into per-task folders")
+		return true
+	}
+	return false
+}
+
+// AttachmentName is how an attachment file is referred to in the manage
+// UI: its path relative to AttachmentsDir with forward slashes, e.g.
+// "1712345678/photo.png". Files outside AttachmentsDir give their base name.
+func (q *TaskQueue) AttachmentName(path string) string {
+	rel, err := filepath.Rel(q.attachmentsDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.Base(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// AttachmentPath resolves a name from AttachmentName back to a path inside
+// AttachmentsDir. ok is false for anything but a file name or a task folder
+// and a file name.
+func (q *TaskQueue) AttachmentPath(name string) (path string, ok bool) {
+	parts := strings.Split(name, "/")
+	if len(parts) > 2 {
+		return "", false
+	}
+	for _, p := range parts {
+		if p == "" || p == "." || p == ".." || strings.ContainsAny(p, `\:`) {
+			return "", false
+		}
+	}
+	return filepath.Join(append([]string{q.attachmentsDir}, parts...)...), true
+}
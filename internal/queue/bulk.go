@@ -0,0 +1,139 @@
+package queue
+
+import (
+	"log"
+	"time"
+)
+
+// Bulk changes apply to several tasks at once and save the queue once. They
+// cannot be undone: the undo entry keeps a single task, so they discard it
+// like any other change, and the attachments of the tasks they remove are
+// deleted right away.
+
+// CompleteMany moves the queued tasks with the given IDs to history, in
+// queue order, and respawns the recurring ones. IDs that are not queued are
+// ignored. Returns the number of tasks completed.
+func (q *TaskQueue) CompleteMany(ids []string) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	done := q.takeLocked(ids)
+	if len(done) == 0 {
+		return 0, nil
+	}
+	for i := range done {
+		done[i].CompletedAt = now
+		done[i].Pinned = false
+		if done[i].StartedAt.IsZero() {
+			done[i].StartedAt = done[i].CreatedAt
+		}
+		q.recordLocked(EventComplete, done[i].ID)
+	}
+	var spawned []Task
+	for _, t := range done {
+		spawned = append(spawned, q.respawnLocked(t))
+	}
+	q.markActiveLocked()
+	if err := q.saveLocked(); err != nil {
+		for _, t := range spawned {
+			q.removeAttachments(t)
+		}
+		return 0, err
+	}
+	if q.history != nil {
+		if err := q.history.Add(done...); err != nil {
+			log.Printf("[queue] history: %v", err)
+		}
+	}
+	for _, t := range done {
+		q.removeAttachments(t)
+		if q.onComplete != nil {
+			q.onComplete(t)
+		}
+	}
+	q.emptiedLocked()
+	return len(done), nil
+}
+
+// DeleteMany removes the queued tasks with the given IDs without recording
+// them in history. IDs that are not queued are ignored. Returns the number
+// of tasks deleted.
+func (q *TaskQueue) DeleteMany(ids []string) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	gone := q.takeLocked(ids)
+	if len(gone) == 0 {
+		return 0, nil
+	}
+	for _, t := range gone {
+		q.recordLocked(EventDelete, t.ID)
+	}
+	q.markActiveLocked()
+	if err := q.saveLocked(); err != nil {
+		return 0, err
+	}
+	for _, t := range gone {
+		q.removeAttachments(t)
+	}
+	q.emptiedLocked()
+	return len(gone), nil
+}
+
+// SkipMany moves the queued tasks with the given IDs to the end of the
+// queue, keeping their relative order, and defers them like Skip. A pinned
+// task stays where it is, as with Skip. Returns the number of tasks moved.
+func (q *TaskQueue) SkipMany(ids []string) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	want := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+	now := time.Now()
+	var kept, moved []Task
+	for _, t := range q.Tasks {
+		if want[t.ID] && !t.Pinned {
+			t.SkippedAt = now
+			moved = append(moved, t)
+		} else {
+			kept = append(kept, t)
+		}
+	}
+	if len(moved) == 0 {
+		return 0, nil
+	}
+	q.Tasks = append(kept, moved...)
+	for _, t := range moved {
+		q.recordLocked(EventSkip, t.ID)
+	}
+	q.markActiveLocked()
+	if err := q.saveLocked(); err != nil {
+		return 0, err
+	}
+	return len(moved), nil
+}
+
+// takeLocked removes the tasks with the given IDs from the queue and returns
+// them in queue order. Caller holds q.mu.
+func (q *TaskQueue) takeLocked(ids []string) []Task {
+	want := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+	var taken []Task
+	kept := make([]Task, 0, len(q.Tasks))
+	for _, t := range q.Tasks {
+		if want[t.ID] {
+			taken = append(taken, t)
+		} else {
+			kept = append(kept, t)
+		}
+	}
+	if len(taken) > 0 {
+		q.Tasks = kept
+	}
+	return taken
+}
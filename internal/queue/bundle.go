@@ -0,0 +1,315 @@
+package queue
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Layout of an export bundle: queue.json with attachment paths relative to
+// the bundle root, and every file of attachmentsDir under attachments/,
+// task folders included.
+const (
+	bundleQueue       = "queue.json"
+	bundleAttachments = "attachments/"
+)
+
+// ExportBundle writes the queue and the whole attachments directory as a zip
+// to w. The bundle is portable and not encrypted: when a passphrase is set,
+// queue.json and attachments are written decrypted.
+func (q *TaskQueue) ExportBundle(w io.Writer) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.writeBundleLocked(w)
+}
+
+// writeBundleLocked does the work of ExportBundle. Caller holds q.mu.
+func (q *TaskQueue) writeBundleLocked(w io.Writer) error {
+	tasks := make([]Task, len(q.Tasks))
+	for i, t := range q.Tasks {
+		t.Attachments = append([]Attachment(nil), t.Attachments...)
+		for j, a := range t.Attachments {
+			if a.Path != "" {
+				t.Attachments[j].Path = bundleAttachments + q.AttachmentName(a.Path)
+			}
+		}
+		tasks[i] = t
+	}
+	data, err := json.MarshalIndent(queueFile{Version: SchemaVersion, Tasks: tasks}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	fw, err := zw.Create(bundleQueue)
+	if err != nil {
+		return err
+	}
+	if _, err := fw.Write(data); err != nil {
+		return err
+	}
+
+	written := map[string]bool{}
+	add := func(src string) error {
+		name := q.AttachmentName(src)
+		if written[name] {
+			return nil
+		}
+		written[name] = true
+		return q.addBundleFile(zw, src, name)
+	}
+	entries, err := os.ReadDir(q.attachmentsDir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		p := filepath.Join(q.attachmentsDir, e.Name())
+		if e.Type().IsRegular() {
+			if err := add(p); err != nil {
+				return err
+			}
+			continue
+		}
+		if !e.IsDir() {
+			continue
+		}
+		files, err := os.ReadDir(p)
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			if f.Type().IsRegular() {
+				if err := add(filepath.Join(p, f.Name())); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	// Attachments that live outside attachmentsDir still travel with the bundle.
+	for _, t := range q.Tasks {
+		for _, a := range t.Attachments {
+			if a.Path == "" {
+				continue
+			}
+			if err := add(a.Path); err != nil {
+				return err
+			}
+		}
+	}
+	return zw.Close()
+}
+
+func (q *TaskQueue) addBundleFile(zw *zip.Writer, src, name string) error {
+	b, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if b, err = q.box.open(b); err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(src), err)
+	}
+	fw, err := zw.Create(bundleAttachments + name)
+	if err != nil {
+		return err
+	}
+	_, err = fw.Write(b)
+	return err
+}
+
+// ImportBundle restores a zip written by ExportBundle, or a backup written
+// by Clear: attachments are unpacked into attachmentsDir, their paths made
+// absolute again, and the bundle's tasks appended in their original order.
+// Tasks whose ID is already queued are skipped. Returns the number of tasks
+// added.
+func (q *TaskQueue) ImportBundle(zipPath string) (int, error) {
+	data, err := os.ReadFile(zipPath)
+	if err != nil {
+		return 0, err
+	}
+	// Backups are encrypted when a passphrase is set.
+	if data, err = q.box.open(data); err != nil {
+		return 0, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return 0, err
+	}
+
+	var tasks []Task
+	hasQueue := false
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		switch {
+		case f.Name == bundleQueue:
+			b, err := readZipFile(f)
+			if err != nil {
+				return 0, err
+			}
+			if tasks, _, err = parseTasks(bundleQueue, b, nil); err != nil {
+				return 0, err
+			}
+			hasQueue = true
+		case strings.HasPrefix(f.Name, bundleAttachments):
+			name := strings.TrimPrefix(f.Name, bundleAttachments)
+			// Reject anything but a file name, or a task folder and a file
+			// name (zip slip).
+			if _, ok := q.AttachmentPath(name); !ok {
+				return 0, fmt.Errorf("bad entry in bundle: %s", f.Name)
+			}
+			files[name] = f
+		}
+	}
+	if !hasQueue {
+		return 0, fmt.Errorf("not a queue bundle: missing %s", bundleQueue)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	existing := make(map[string]bool, len(q.Tasks))
+	for _, t := range q.Tasks {
+		existing[t.ID] = true
+	}
+	fresh := 0
+	for _, t := range tasks {
+		if !existing[t.ID] {
+			fresh++
+		}
+	}
+	if fresh == 0 {
+		return 0, nil
+	}
+	// Before anything is unpacked, so a full queue costs no file I/O.
+	if err := q.checkRoomLocked(fresh); err != nil {
+		return 0, err
+	}
+	var added []Task
+	var unpacked []string // staged, not yet moved into a task's folder
+	// fail removes what this import wrote: files still staged and the
+	// placed attachments of the tasks prepared so far.
+	fail := func(err error) (int, error) {
+		for _, p := range unpacked {
+			_ = os.Remove(p)
+			_ = os.Remove(ThumbPath(p))
+		}
+		for _, t := range added {
+			q.removeAttachments(t)
+		}
+		return 0, err
+	}
+	for _, t := range tasks {
+		if existing[t.ID] {
+			continue
+		}
+		for i, a := range t.Attachments {
+			if a.Path == "" {
+				continue
+			}
+			f := files[strings.TrimPrefix(a.Path, bundleAttachments)]
+			if f == nil {
+				return fail(fmt.Errorf("bundle is missing %s", a.Path))
+			}
+			dst, err := q.unpackBundleFile(f)
+			if err != nil {
+				return fail(err)
+			}
+			unpacked = append(unpacked, dst)
+			t.Attachments[i].Path = dst
+		}
+		// The paths of the files moved so far are already rewritten.
+		err := q.prepareAttachmentsLocked(t.ID, t.Attachments)
+		added = append(added, t)
+		unpacked = unpacked[:0]
+		if err != nil {
+			return fail(err)
+		}
+	}
+	if len(q.Tasks) == 0 && added[0].StartedAt.IsZero() {
+		added[0].StartedAt = time.Now()
+	}
+	n := len(q.Tasks)
+	q.Tasks = append(q.Tasks[:n:n], added...)
+	for i, t := range added {
+		q.recordTaskLocked(EventEnqueue, t, n+i)
+	}
+	if err := q.saveLocked(); err != nil {
+		return fail(err)
+	}
+	return len(added), nil
+}
+
+// unpackBundleFile stages f in attachmentsDir under its own name, or a
+// StagedName of it if that is taken; prepareAttachmentsLocked then moves it
+// into its task's folder. Caller holds q.mu.
+func (q *TaskQueue) unpackBundleFile(f *zip.File) (string, error) {
+	b, err := readZipFile(f)
+	if err != nil {
+		return "", err
+	}
+	name := path.Base(f.Name)
+	dst := filepath.Join(q.attachmentsDir, name)
+	if _, err := os.Stat(dst); err == nil {
+		dst = filepath.Join(q.attachmentsDir, StagedName(name))
+	}
+	if err := atomicWriteFile(dst, b, 0644); err != nil {
+		return "", err
+	}
+	return dst, nil
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// Clear empties the queue, first writing it with its attachments to a
+// timestamped bundle under backups/ that Import restores. The backup is
+// encrypted when a passphrase is set. The removed tasks' attachments are
+// deleted and nothing goes to history. Returns the backup path, or "" when
+// the queue was already empty.
+func (q *TaskQueue) Clear() (string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.Tasks) == 0 {
+		return "", nil
+	}
+	dir := filepath.Join(q.baseDir, "backups")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := q.writeBundleLocked(&buf); err != nil {
+		return "", fmt.Errorf("backup: %w", err)
+	}
+	data, err := q.box.seal(buf.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("backup: %w", err)
+	}
+	backup := filepath.Join(dir, "queue-"+time.Now().Format("20060102-150405")+".zip")
+	if err := atomicWriteFile(backup, data, 0o600); err != nil {
+		return "", fmt.Errorf("backup: %w", err)
+	}
+
+	old := q.Tasks
+	q.Tasks = nil
+	for _, t := range old {
+		q.recordLocked(EventDelete, t.ID)
+	}
+	if err := q.saveLocked(); err != nil {
+		return "", err
+	}
+	for _, t := range old {
+		q.removeAttachments(t)
+	}
+	return backup, nil
+}
@@ -0,0 +1,104 @@
+package queue
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// writeTestBundle exports a queue of two tasks with one attachment each and
+// returns the path of the zip.
+func writeTestBundle(t *testing.T) string {
+	t.Helper()
+	src := newTestQueue(t)
+	for _, id := range []string{"a", "b"} {
+		staged := filepath.Join(src.AttachmentsDir(), StagedName(id+".txt"))
+		if err := os.WriteFile(staged, []byte("notes for "+id), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		task := Task{ID: id, Text: id, CreatedAt: time.Now(), Attachments: []Attachment{{Path: staged, Type: AttachmentFile}}}
+		if err := src.Enqueue(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := src.ExportBundle(&buf); err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(t.TempDir(), "bundle.zip")
+	if err := os.WriteFile(p, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// listFiles returns the files under dir, relative to it.
+func listFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	var files []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestImportBundleLeavesNoFilesOnFailedSave(t *testing.T) {
+	noRetryDelay(t)
+	bundle := writeTestBundle(t)
+	q := newTestQueue(t)
+	before := listFiles(t, q.baseDir)
+	q.store = &failingStore{Store: q.store, fails: len(saveRetryDelays) + 1}
+
+	if _, err := q.ImportBundle(bundle); !errors.Is(err, errDiskGone) {
+		t.Fatalf("ImportBundle = %v, want errDiskGone", err)
+	}
+	if q.Count() != 0 {
+		t.Fatalf("queue has %d tasks after a failed import", q.Count())
+	}
+	if after := listFiles(t, q.baseDir); !reflect.DeepEqual(after, before) {
+		t.Fatalf("data folder after a failed import: %v, want %v", after, before)
+	}
+}
+
+func TestImportBundle(t *testing.T) {
+	bundle := writeTestBundle(t)
+	q := newTestQueue(t)
+	n, err := q.ImportBundle(bundle)
+	if err != nil || n != 2 {
+		t.Fatalf("ImportBundle = %d, %v; want 2 tasks", n, err)
+	}
+	for _, task := range q.GetAll() {
+		b, err := q.ReadAttachment(task.Attachments[0].Path)
+		if err != nil || string(b) != "notes for "+task.ID {
+			t.Fatalf("attachment of %s: %q, %v", task.ID, b, err)
+		}
+	}
+}
+
+func TestImportBundleIntoFullQueueUnpacksNothing(t *testing.T) {
+	bundle := writeTestBundle(t)
+	q := newTestQueue(t)
+	enqueueTexts(t, q, "x")
+	q.SetMaxLen(2)
+	before := listFiles(t, q.baseDir)
+
+	if _, err := q.ImportBundle(bundle); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("ImportBundle = %v, want ErrQueueFull", err)
+	}
+	if after := listFiles(t, q.baseDir); !reflect.DeepEqual(after, before) {
+		t.Fatalf("data folder after a refused import: %v, want %v", after, before)
+	}
+}
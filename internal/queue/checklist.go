@@ -0,0 +1,90 @@
+package queue
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ChecklistItem is one step of a task's checklist.
+type ChecklistItem struct {
+	Text string `json:"text"`
+	Done bool   `json:"done,omitempty"`
+}
+
+// ParseChecklist splits text into checklist items, one per non-empty line.
+// Markdown list markers are dropped, and "[x]" marks an item as done, so
+// "- [ ] a" and "- [x] b" both work as well as a bare "a".
+func ParseChecklist(text string) []ChecklistItem {
+	var items []ChecklistItem
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		line = strings.TrimSpace(strings.TrimLeft(line, "-*"))
+		done := false
+		switch {
+		case strings.HasPrefix(line, "[ ]"):
+			line = line[3:]
+		case strings.HasPrefix(line, "[x]"), strings.HasPrefix(line, "[X]"):
+			line, done = line[3:], true
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			items = append(items, ChecklistItem{Text: line, Done: done})
+		}
+	}
+	return items
+}
+
+// ChecklistProgress returns how many checklist items are done out of how
+// many there are.
+func (t Task) ChecklistProgress() (done, total int) {
+	for _, it := range t.Checklist {
+		if it.Done {
+			done++
+		}
+	}
+	return done, len(t.Checklist)
+}
+
+// ChecklistDone reports whether the task has a checklist with every item
+// checked.
+func (t Task) ChecklistDone() bool {
+	done, total := t.ChecklistProgress()
+	return total > 0 && done == total
+}
+
+// freshChecklist copies items with none of them checked, for a new task made
+// from an old one.
+func freshChecklist(items []ChecklistItem) []ChecklistItem {
+	if len(items) == 0 {
+		return nil
+	}
+	out := make([]ChecklistItem, len(items))
+	for i, it := range items {
+		out[i] = ChecklistItem{Text: it.Text}
+	}
+	return out
+}
+
+// ToggleChecklistItem checks or unchecks item index of the task with id and
+// returns the updated task.
+func (q *TaskQueue) ToggleChecklistItem(id string, index int) (Task, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i := range q.Tasks {
+		if q.Tasks[i].ID != id {
+			continue
+		}
+		if index < 0 || index >= len(q.Tasks[i].Checklist) {
+			return Task{}, fmt.Errorf("checklist item %d out of range", index)
+		}
+		// Copy so tasks handed out earlier keep their own slice.
+		items := append([]ChecklistItem(nil), q.Tasks[i].Checklist...)
+		items[index].Done = !items[index].Done
+		q.Tasks[i].Checklist = items
+		q.recordTaskLocked(EventUpdate, q.Tasks[i], i)
+		if err := q.saveLocked(); err != nil {
+			return Task{}, err
+		}
+		return q.Tasks[i], nil
+	}
+	return Task{}, fmt.Errorf("task not found: %s", id)
+}
@@ -0,0 +1,34 @@
+package queue
+
+// Palette is the fixed set of task colors. Tasks store the name; only the
+// hex values here ever reach HTML, so a hand-edited queue.json cannot
+// inject CSS.
+var Palette = []struct {
+	Name string
+	Hex  string
+}{
+	{"red", "#ff3b30"},
+	{"orange", "#ff9500"},
+	{"yellow", "#ffcc00"},
+	{"green", "#34c759"},
+	{"blue", "#007aff"},
+	{"purple", "#af52de"},
+	{"gray", "#8e8e93"},
+}
+
+// ColorHex returns the hex value of a palette color. ok is false for "" (no
+// color) and for names not in Palette.
+func ColorHex(name string) (hex string, ok bool) {
+	for _, c := range Palette {
+		if c.Name == name {
+			return c.Hex, true
+		}
+	}
+	return "", false
+}
+
+// ValidColor reports whether name is empty (no color) or in Palette.
+func ValidColor(name string) bool {
+	_, ok := ColorHex(name)
+	return ok || name == ""
+}
@@ -0,0 +1,140 @@
+package queue
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// EnvPassphrase enables encryption at rest of queue.json, history.json,
+// templates.json, archive.json and attachments. When unset, files are stored in plaintext.
+const EnvPassphrase = "QUEUE_PASSPHRASE"
+
+// ErrEncrypted is returned when a file is encrypted but no passphrase is set.
+var ErrEncrypted = errors.New("file is encrypted; set " + EnvPassphrase)
+
+// ErrDecrypt is returned when an encrypted file cannot be decrypted,
+// usually because the passphrase is wrong.
+var ErrDecrypt = errors.New("cannot decrypt file (wrong passphrase?)")
+
+// encMagic prefixes every encrypted file, followed by the GCM nonce and the
+// sealed data. Files without it are read as plaintext, so existing data keeps
+// working after a passphrase is set and is encrypted on the next write.
+var encMagic = []byte("QENC1\n")
+
+// cipherBox seals and opens file contents with AES-256-GCM. A nil box is
+// valid and leaves data untouched.
+type cipherBox struct {
+	aead cipher.AEAD
+}
+
+// cipherFromEnv returns a box keyed from EnvPassphrase, or nil when it is unset.
+// The scrypt salt is kept in baseDir/queue.salt and created on first use;
+// losing it makes the encrypted data unreadable.
+func cipherFromEnv(baseDir string) (*cipherBox, error) {
+	pass := os.Getenv(EnvPassphrase)
+	if pass == "" {
+		return nil, nil
+	}
+	salt, err := loadOrCreateSalt(filepath.Join(baseDir, "queue.salt"))
+	if err != nil {
+		return nil, err
+	}
+	key, err := scrypt.Key([]byte(pass), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &cipherBox{aead: aead}, nil
+}
+
+func loadOrCreateSalt(path string) ([]byte, error) {
+	salt, err := os.ReadFile(path)
+	if err == nil {
+		if len(salt) < 16 {
+			return nil, fmt.Errorf("%s: salt too short", filepath.Base(path))
+		}
+		return salt, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	salt = make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if err := atomicWriteFile(path, salt, 0o600); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+func isEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, encMagic)
+}
+
+func (b *cipherBox) seal(data []byte) ([]byte, error) {
+	if b == nil {
+		return data, nil
+	}
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := append([]byte(nil), encMagic...)
+	out = append(out, nonce...)
+	return b.aead.Seal(out, nonce, data, nil), nil
+}
+
+func (b *cipherBox) open(data []byte) ([]byte, error) {
+	if !isEncrypted(data) {
+		return data, nil
+	}
+	if b == nil {
+		return nil, ErrEncrypted
+	}
+	data = data[len(encMagic):]
+	ns := b.aead.NonceSize()
+	if len(data) < ns {
+		return nil, ErrDecrypt
+	}
+	plain, err := b.aead.Open(nil, data[:ns], data[ns:], nil)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plain, nil
+}
+
+// sealFile encrypts a plaintext file in place. Already encrypted files and a
+// nil box are left alone.
+func (b *cipherBox) sealFile(path string) error {
+	if b == nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if isEncrypted(data) {
+		return nil
+	}
+	sealed, err := b.seal(data)
+	if err != nil {
+		return err
+	}
+	return atomicWriteFile(path, sealed, 0o644)
+}
@@ -0,0 +1,40 @@
+package queue
+
+import (
+	"sort"
+	"strings"
+)
+
+// DuplicateGroups groups tasks whose text is the same once trimmed and
+// lowercased, and returns the IDs of every group with more than one task.
+// Each group starts with the earliest-created task, the one to keep by
+// default, and the groups are in the order their text first appears in
+// tasks.
+func DuplicateGroups(tasks []Task) [][]string {
+	byText := map[string][]Task{}
+	var order []string
+	for _, t := range tasks {
+		key := strings.ToLower(strings.TrimSpace(t.Text))
+		if key == "" {
+			continue
+		}
+		if _, seen := byText[key]; !seen {
+			order = append(order, key)
+		}
+		byText[key] = append(byText[key], t)
+	}
+	var groups [][]string
+	for _, key := range order {
+		ts := byText[key]
+		if len(ts) < 2 {
+			continue
+		}
+		sort.SliceStable(ts, func(i, j int) bool { return ts[i].CreatedAt.Before(ts[j].CreatedAt) })
+		ids := make([]string, len(ts))
+		for i, t := range ts {
+			ids[i] = t.ID
+		}
+		groups = append(groups, ids)
+	}
+	return groups
+}
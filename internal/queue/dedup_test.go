@@ -0,0 +1,44 @@
+package queue
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDuplicateGroups(t *testing.T) {
+	task := func(id, text string, created time.Duration) Task {
+		return Task{ID: id, Text: text, CreatedAt: testNow.Add(created)}
+	}
+	withFile := func(t Task, path string) Task {
+		t.Attachments = []Attachment{{Path: path, Type: AttachmentFile}}
+		return t
+	}
+	tests := []struct {
+		name  string
+		tasks []Task
+		want  [][]string
+	}{
+		{"none", nil, nil},
+		{"no duplicates", []Task{task("a", "one", 0), task("b", "two", 0)}, nil},
+		{"case", []Task{task("a", "Buy milk", 0), task("b", "buy MILK", time.Hour)}, [][]string{{"a", "b"}}},
+		{"surrounding whitespace", []Task{task("a", "  call mom\n", 0), task("b", "\tcall mom", time.Hour)}, [][]string{{"a", "b"}}},
+		{"inner whitespace differs", []Task{task("a", "call mom", 0), task("b", "call  mom", time.Hour)}, nil},
+		{"empty text skipped", []Task{task("a", "", 0), task("b", "  ", time.Hour)}, nil},
+		{"different attachments", []Task{withFile(task("a", "scan", 0), "/x/a.pdf"), withFile(task("b", "scan", time.Hour), "/x/b.pdf"), task("c", "scan", 2*time.Hour)},
+			[][]string{{"a", "b", "c"}}},
+		{"single-member groups dropped", []Task{task("a", "one", 0), task("b", "two", 0), task("c", "One", time.Hour), task("d", "three", 0)},
+			[][]string{{"a", "c"}}},
+		{"earliest first", []Task{task("a", "x", 2*time.Hour), task("b", "x", 0), task("c", "x", time.Hour)}, [][]string{{"b", "c", "a"}}},
+		{"same time keeps queue order", []Task{task("b", "x", 0), task("a", "x", 0), task("c", "x", 0)}, [][]string{{"b", "a", "c"}}},
+		{"groups in order of first appearance", []Task{task("a", "late", 0), task("b", "early", -time.Hour), task("c", "early", 0), task("d", "late", time.Hour)},
+			[][]string{{"a", "d"}, {"b", "c"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DuplicateGroups(tt.tasks); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("DuplicateGroups = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package queue
+
+import "fmt"
+
+// OpenBlockers returns the IDs in t.BlockedBy that still name a task in
+// tasks, i.e. what t is waiting for. Completed or deleted blockers no longer
+// count, so t becomes eligible as soon as the last one leaves the queue.
+func OpenBlockers(t Task, tasks []Task) []string {
+	if len(t.BlockedBy) == 0 {
+		return nil
+	}
+	var open []string
+	for _, id := range t.BlockedBy {
+		for _, other := range tasks {
+			if other.ID == id && other.ID != t.ID {
+				open = append(open, id)
+				break
+			}
+		}
+	}
+	return open
+}
+
+// blockedLocked reports whether t waits on a task still in the queue. Caller
+// holds q.mu.
+func (q *TaskQueue) blockedLocked(t Task) bool {
+	return len(OpenBlockers(t, q.Tasks)) > 0
+}
+
+// checkDepsLocked rejects a task that would depend on itself, directly or
+// through the queued tasks it is blocked by. IDs that are not in the queue
+// are allowed: they simply do not block. Caller holds q.mu.
+func (q *TaskQueue) checkDepsLocked(t Task) error {
+	if len(t.BlockedBy) == 0 {
+		return nil
+	}
+	deps := make(map[string][]string, len(q.Tasks)+1)
+	for _, other := range q.Tasks {
+		deps[other.ID] = other.BlockedBy
+	}
+	deps[t.ID] = t.BlockedBy
+	seen := make(map[string]bool)
+	stack := append([]string(nil), t.BlockedBy...)
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == t.ID {
+			return fmt.Errorf("%w: task %s would wait on itself", ErrDependencyCycle, t.ID)
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		stack = append(stack, deps[id]...)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package queue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestActiveIndex(t *testing.T) {
+	later := time.Now().Add(time.Hour)
+	skippedAt := time.Now().Add(-time.Minute)
+	tests := []struct {
+		name     string
+		dueFirst bool
+		tasks    []Task
+		want     string // ID of the current task, "" for none
+	}{
+		{"empty", true, nil, ""},
+		{"queue order ignores due", false, []Task{
+			{ID: "a"}, {ID: "b", DueDate: at(-time.Hour)},
+		}, "a"},
+		{"earliest due", true, []Task{
+			{ID: "a", DueDate: at(2 * time.Hour)}, {ID: "b", DueDate: at(time.Hour)}, {ID: "c", DueDate: at(3 * time.Hour)},
+		}, "b"},
+		{"dated before undated head", true, []Task{
+			{ID: "a"}, {ID: "b"}, {ID: "c", DueDate: at(48 * time.Hour)},
+		}, "c"},
+		{"overdue first", true, []Task{
+			{ID: "a", DueDate: at(time.Hour)}, {ID: "b", DueDate: at(-time.Hour)},
+		}, "b"},
+		{"undated in queue order", true, []Task{
+			{ID: "a"}, {ID: "b"},
+		}, "a"},
+		{"same due keeps queue order", true, []Task{
+			{ID: "a", DueDate: at(time.Hour)}, {ID: "b", DueDate: at(time.Hour)},
+		}, "a"},
+		{"snoozed earliest passed over", true, []Task{
+			{ID: "a", DueDate: at(-time.Hour), SnoozedUntil: &later}, {ID: "b", DueDate: at(time.Hour)}, {ID: "c"},
+		}, "b"},
+		{"blocked earliest passed over", true, []Task{
+			{ID: "a"}, {ID: "b", DueDate: at(time.Hour), BlockedBy: []string{"a"}},
+		}, "a"},
+		{"pinned beats earlier due", true, []Task{
+			{ID: "a", DueDate: at(-time.Hour)}, {ID: "b", Pinned: true},
+		}, "b"},
+		{"skipped earliest waits", true, []Task{
+			{ID: "a", DueDate: at(-time.Hour), SkippedAt: skippedAt}, {ID: "b", DueDate: at(time.Hour)}, {ID: "c"},
+		}, "b"},
+		{"only skipped left", true, []Task{
+			{ID: "a", DueDate: at(time.Hour), SkippedAt: skippedAt}, {ID: "b", SkippedAt: skippedAt.Add(-time.Second)},
+		}, "b"},
+		{"all snoozed", true, []Task{
+			{ID: "a", SnoozedUntil: &later},
+		}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &TaskQueue{Tasks: tt.tasks, dueFirst: tt.dueFirst}
+			got := ""
+			if i := q.activeIndexLocked(); i >= 0 {
+				got = q.Tasks[i].ID
+			}
+			if got != tt.want {
+				t.Fatalf("current = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSkipDueFirst(t *testing.T) {
+	q := newTestQueue(t)
+	q.SetDueFirst(true)
+	for _, tk := range []Task{
+		{ID: "a", Text: "a"},
+		{ID: "b", Text: "b", DueDate: at(time.Hour)},
+		{ID: "c", Text: "c", DueDate: at(2 * time.Hour)},
+	} {
+		if err := q.Enqueue(tk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	current := func() string {
+		t.Helper()
+		cur, ok := q.Peek()
+		if !ok {
+			t.Fatal("no current task")
+		}
+		return cur.ID
+	}
+
+	// Skipping goes round every task, dated ones by due date first.
+	for _, want := range []string{"b", "c", "a", "b", "c"} {
+		if got := current(); got != want {
+			t.Fatalf("current = %s, want %s", got, want)
+		}
+		if err := q.Skip(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := q.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if got := current(); got != "c" {
+		t.Fatalf("after undo current = %s, want c", got)
+	}
+}
+
+func TestSkipDueFirstOnlyTask(t *testing.T) {
+	q := newTestQueue(t)
+	q.SetDueFirst(true)
+	if err := q.Enqueue(Task{ID: "a", Text: "a", DueDate: at(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	version := q.store.Version()
+	if err := q.Skip(); err != nil {
+		t.Fatal(err)
+	}
+	if q.store.Version() != version {
+		t.Fatal("a Skip that changes nothing wrote the queue")
+	}
+	if err := q.Undo(); err != ErrNothingToUndo {
+		t.Fatalf("Undo = %v, want ErrNothingToUndo", err)
+	}
+}
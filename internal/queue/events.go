@@ -0,0 +1,240 @@
+package queue
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Event operations recorded in events.jsonl.
+const (
+	EventEnqueue  = "enqueue"  // Task inserted at Index
+	EventComplete = "complete" // TaskID moved to history
+	EventSkip     = "skip"     // TaskID moved to the end
+	EventDelete   = "delete"   // TaskID removed
+	EventUpdate   = "update"   // Task replaced in place (text, attachments, snooze)
+	EventReorder  = "reorder"  // the queue put in the order of IDs
+)
+
+// Event is one line of events.jsonl. Task is set for enqueue and update;
+// when a passphrase is set it is stored encrypted in Sealed instead.
+type Event struct {
+	TS     time.Time `json:"ts"`
+	Op     string    `json:"op"`
+	TaskID string    `json:"task_id,omitempty"`
+	Index  int       `json:"index,omitempty"`
+	IDs    []string  `json:"ids,omitempty"`
+	Task   *Task     `json:"task,omitempty"`
+	Sealed []byte    `json:"sealed,omitempty"`
+}
+
+func (q *TaskQueue) eventsPath() string {
+	return filepath.Join(q.baseDir, "events.jsonl")
+}
+
+// recordLocked queues an event for the change just made to q.Tasks. It is
+// written by the next successful saveLocked, so a change that is never
+// saved is never logged. Caller holds q.mu.
+func (q *TaskQueue) recordLocked(op, id string) {
+	q.pending = append(q.pending, Event{TS: time.Now(), Op: op, TaskID: id})
+}
+
+// recordTaskLocked is recordLocked for events that carry the task itself.
+func (q *TaskQueue) recordTaskLocked(op string, t Task, index int) {
+	q.pending = append(q.pending, Event{TS: time.Now(), Op: op, TaskID: t.ID, Index: index, Task: &t})
+}
+
+// recordOrderLocked records the current order of the whole queue.
+func (q *TaskQueue) recordOrderLocked() {
+	ids := make([]string, len(q.Tasks))
+	for i, t := range q.Tasks {
+		ids[i] = t.ID
+	}
+	q.pending = append(q.pending, Event{TS: time.Now(), Op: EventReorder, IDs: ids})
+}
+
+// writeEventsLocked appends the pending events to events.jsonl. The log is
+// best-effort: a failure is logged and never fails the change itself, which
+// is already saved. Caller holds q.mu.
+func (q *TaskQueue) writeEventsLocked() {
+	if len(q.pending) == 0 {
+		return
+	}
+	events := q.pending
+	q.pending = nil
+	var buf []byte
+	for _, e := range events {
+		if e.Task != nil && q.box != nil {
+			plain, err := json.Marshal(e.Task)
+			if err == nil {
+				e.Sealed, err = q.box.seal(plain)
+			}
+			if err != nil {
+				log.Printf("[queue] events: %v", err)
+				continue
+			}
+			e.Task = nil
+		}
+		line, err := json.Marshal(e)
+		if err != nil {
+			log.Printf("[queue] events: %v", err)
+			continue
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+	f, err := os.OpenFile(q.eventsPath(), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		log.Printf("[queue] events: %v", err)
+		return
+	}
+	if _, err := f.Write(buf); err != nil {
+		log.Printf("[queue] events: %v", err)
+	}
+	_ = f.Close()
+}
+
+// ReplayEvents rebuilds the queue in baseDir from its events.jsonl alone,
+// for when queue.json and its backup are lost. It does not open the queue,
+// so it works even when that fails. The result is only as complete as the
+// log: changes made before it existed, or by editing queue.json by hand, are
+// not in it. Lines that cannot be read (a write cut short by a crash) are
+// skipped. Nothing is written; see RestoreFromEvents.
+func ReplayEvents(baseDir string) ([]Task, error) {
+	box, err := cipherFromEnv(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	return replayEvents(filepath.Join(baseDir, "events.jsonl"), box)
+}
+
+func replayEvents(path string, box *cipherBox) ([]Task, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no event log at %s", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var tasks []Task
+	indexOf := func(id string) int {
+		for i, t := range tasks {
+			if t.ID == id {
+				return i
+			}
+		}
+		return -1
+	}
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for n := 1; sc.Scan(); n++ {
+		var e Event
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			log.Printf("[queue] events line %d: %v", n, err)
+			continue
+		}
+		if e.Sealed != nil {
+			plain, err := box.open(e.Sealed)
+			if err != nil {
+				return nil, fmt.Errorf("events line %d: %w", n, err)
+			}
+			var t Task
+			if err := json.Unmarshal(plain, &t); err != nil {
+				log.Printf("[queue] events line %d: %v", n, err)
+				continue
+			}
+			e.Task = &t
+		}
+		switch e.Op {
+		case EventEnqueue:
+			if e.Task == nil || indexOf(e.Task.ID) >= 0 {
+				continue
+			}
+			i := min(max(e.Index, 0), len(tasks))
+			tasks = append(tasks[:i], append([]Task{*e.Task}, tasks[i:]...)...)
+		case EventUpdate:
+			if e.Task == nil {
+				continue
+			}
+			if i := indexOf(e.Task.ID); i >= 0 {
+				tasks[i] = *e.Task
+			}
+		case EventComplete, EventDelete:
+			if i := indexOf(e.TaskID); i >= 0 {
+				tasks = append(tasks[:i], tasks[i+1:]...)
+			}
+		case EventSkip:
+			if i := indexOf(e.TaskID); i >= 0 {
+				t := tasks[i]
+				// As Skip does: under SetDueFirst a skipped task waits
+				// behind the others.
+				t.SkippedAt = e.TS
+				tasks = append(append(tasks[:i:i], tasks[i+1:]...), t)
+			}
+		case EventReorder:
+			// Tasks the event does not name keep their relative order
+			// after the named ones.
+			pos := make(map[string]int, len(e.IDs))
+			for i, id := range e.IDs {
+				pos[id] = i
+			}
+			var named, rest []Task
+			for _, t := range tasks {
+				if _, ok := pos[t.ID]; ok {
+					named = append(named, t)
+				} else {
+					rest = append(rest, t)
+				}
+			}
+			ordered := make([]Task, 0, len(tasks))
+			for _, id := range e.IDs {
+				for _, t := range named {
+					if t.ID == id {
+						ordered = append(ordered, t)
+						break
+					}
+				}
+			}
+			tasks = append(ordered, rest...)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// RestoreFromEvents replaces the stored queue in baseDir with the one
+// rebuilt by ReplayEvents. A running app picks the change up like any other
+// external edit. Returns the number of tasks restored.
+func RestoreFromEvents(baseDir string) (int, error) {
+	box, err := cipherFromEnv(baseDir)
+	if err != nil {
+		return 0, err
+	}
+	tasks, err := replayEvents(filepath.Join(baseDir, "events.jsonl"), box)
+	if err != nil {
+		return 0, err
+	}
+	l, err := lockFile(filepath.Join(baseDir, "queue.json.lock"))
+	if err != nil {
+		return 0, err
+	}
+	defer l.Unlock()
+	store, err := openStore(baseDir, box)
+	if err != nil {
+		return 0, err
+	}
+	defer store.Close()
+	// Written straight to the store, so the restore is not logged again.
+	if err := store.Save(tasks); err != nil {
+		return 0, err
+	}
+	return len(tasks), nil
+}
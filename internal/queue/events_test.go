@@ -0,0 +1,52 @@
+package queue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReplayEventsKeepsSkipsUnderDueFirst(t *testing.T) {
+	q := newTestQueue(t)
+	q.SetDueFirst(true)
+	for _, tk := range []Task{
+		{ID: "a", Text: "a"},
+		{ID: "b", Text: "b", DueDate: at(time.Hour)},
+		{ID: "c", Text: "c", DueDate: at(2 * time.Hour)},
+	} {
+		if err := q.Enqueue(tk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for range 2 {
+		if err := q.Skip(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.SkipByID("a"); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err := ReplayEvents(q.baseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	live := q.GetAll()
+	if len(tasks) != len(live) {
+		t.Fatalf("replayed %d tasks, want %d", len(tasks), len(live))
+	}
+	for i := range live {
+		if tasks[i].ID != live[i].ID {
+			t.Fatalf("replayed task %d is %s, want %s", i, tasks[i].ID, live[i].ID)
+		}
+		if d := tasks[i].SkippedAt.Sub(live[i].SkippedAt).Abs(); d > time.Second || tasks[i].SkippedAt.IsZero() != live[i].SkippedAt.IsZero() {
+			t.Fatalf("task %s skipped at %v after replay, %v live", live[i].ID, tasks[i].SkippedAt, live[i].SkippedAt)
+		}
+	}
+
+	// The replayed queue serves the same task next.
+	want, _ := q.Peek()
+	replayed := &TaskQueue{Tasks: tasks, dueFirst: true}
+	if i := replayed.activeIndexLocked(); i < 0 || tasks[i].ID != want.ID {
+		t.Fatalf("replayed queue serves index %d, want %s", i, want.ID)
+	}
+}
@@ -0,0 +1,97 @@
+package queue
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseImport reads tasks from a plaintext list (one task per non-empty line)
+// or, when csvFormat is set, from CSV rows of text[,tags[,priority]]. A header
+// row starting with "text" is ignored. Rows that cannot be used are counted
+// in skipped instead of failing the whole import.
+func ParseImport(r io.Reader, csvFormat bool) (tasks []Task, skipped int, err error) {
+	now := time.Now()
+	newTask := func(text string) Task {
+		return Task{
+			ID:        strconv.FormatInt(now.UnixNano()+int64(len(tasks)), 10),
+			Text:      text,
+			CreatedAt: now,
+		}
+	}
+
+	if !csvFormat {
+		sc := bufio.NewScanner(r)
+		sc.Buffer(make([]byte, 64*1024), 1<<20)
+		for sc.Scan() {
+			text := strings.TrimSpace(strings.TrimPrefix(sc.Text(), "\ufeff"))
+			if text == "" {
+				continue
+			}
+			tasks = append(tasks, newTask(text))
+		}
+		return tasks, skipped, sc.Err()
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	cr.TrimLeadingSpace = true
+	for row := 0; ; row++ {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var perr *csv.ParseError
+		if errors.As(err, &perr) {
+			skipped++
+			continue
+		}
+		if err != nil {
+			return tasks, skipped, err
+		}
+		text := strings.TrimSpace(strings.TrimPrefix(rec[0], "\ufeff"))
+		if row == 0 && strings.EqualFold(text, "text") {
+			continue
+		}
+		if text == "" {
+			if len(rec) > 1 {
+				skipped++
+			}
+			continue
+		}
+		t := newTask(text)
+		if len(rec) > 1 {
+			t.Tags = ParseTags(rec[1])
+		}
+		if len(rec) > 2 {
+			p, ok := parsePriority(rec[2])
+			if !ok {
+				skipped++
+				continue
+			}
+			t.Priority = p
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, skipped, nil
+}
+
+// parsePriority accepts a level number or its name (normal, high, urgent).
+// An empty value means normal.
+func parsePriority(s string) (int, bool) {
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case "", "normal":
+		return PriorityNormal, true
+	case "high":
+		return PriorityHigh, true
+	case "urgent":
+		return PriorityUrgent, true
+	}
+	p, err := strconv.Atoi(s)
+	return p, err == nil && p >= 0
+}
@@ -0,0 +1,9 @@
+package queue
+
+import "github.com/Ameight/systray-queue-app/internal/util"
+
+// lockFile takes the advisory inter-process lock on queue.json.lock, so the
+// tray app and CLI invocations never write the queue at the same time.
+func lockFile(path string) (*util.FileLock, error) {
+	return util.LockFile(path)
+}
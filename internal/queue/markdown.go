@@ -0,0 +1,55 @@
+package queue
+
+import (
+	"strings"
+)
+
+// MarkdownChecklist formats tasks as a Markdown task list, one
+// "- [ ] text" item per task in the given order, with the due date and tags
+// appended and checklist steps as nested items. The text is folded onto one
+// line and escaped, so it shows as written instead of as formatting.
+func MarkdownChecklist(tasks []Task) string {
+	var b strings.Builder
+	for _, t := range tasks {
+		b.WriteString("- [ ] " + markdownLine(t.Text))
+		if t.DueDate != nil {
+			b.WriteString(" (due " + t.DueDate.Local().Format("2006-01-02 15:04") + ")")
+		}
+		for _, tag := range t.Tags {
+			b.WriteString(" #" + markdownEscaper.Replace(tag))
+		}
+		b.WriteString("\n")
+		for _, it := range t.Checklist {
+			box := "[ ]"
+			if it.Done {
+				box = "[x]"
+			}
+			b.WriteString("  - " + box + " " + markdownLine(it.Text) + "\n")
+		}
+	}
+	return b.String()
+}
+
+// markdownEscaper backslash-escapes the characters that start inline
+// formatting: emphasis, code, links, images, HTML and strikethrough.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+	"<", `\<`, ">", `\>`, "~", `\~`, "|", `\|`,
+)
+
+// markdownLine folds s onto one line and escapes it for use as the text of
+// a list item, including the markers that would start a heading, a quote or
+// a nested list at the beginning of the item.
+func markdownLine(s string) string {
+	s = markdownEscaper.Replace(strings.Join(strings.Fields(s), " "))
+	switch {
+	case strings.HasPrefix(s, "#"), strings.HasPrefix(s, "-"), strings.HasPrefix(s, "+"):
+		return `\` + s
+	}
+	// "1. text" and "1) text" would start an ordered list.
+	digits := len(s) - len(strings.TrimLeft(s, "0123456789"))
+	if digits > 0 && digits < len(s) && (s[digits] == '.' || s[digits] == ')') {
+		return s[:digits] + `\` + s[digits:]
+	}
+	return s
+}
@@ -0,0 +1,65 @@
+package queue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMarkdownLine(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"plain", "buy milk", "buy milk"},
+		{"heading", "# not a heading", `\# not a heading`},
+		{"hashtag", "#work stuff", `\#work stuff`},
+		{"dash", "- not nested", `\- not nested`},
+		{"plus", "+ not nested", `\+ not nested`},
+		{"ordered dot", "1. first", `1\. first`},
+		{"ordered paren", "12) twelfth", `12\) twelfth`},
+		{"only digits", "2026", "2026"},
+		{"digits then text", "3 apples", "3 apples"},
+		{"quote", "> quoted", `\> quoted`},
+		{"embedded box", "check [ ] this", `check \[ \] this`},
+		{"done box", "[x] done", `\[x\] done`},
+		{"pipe", "a | b", `a \| b`},
+		{"inline formatting", "*a* _b_ `c` ~~d~~", "\\*a\\* \\_b\\_ \\`c\\` \\~\\~d\\~\\~"},
+		{"html", "<b>x</b>", `\<b\>x\</b\>`},
+		{"link", "[site](https://example.com)", `\[site\](https://example.com)`},
+		{"backslash", `C:\temp`, `C:\\temp`},
+		{"newlines", "line one\n\n  line two\r\n", "line one line two"},
+		{"marker after a newline", "intro\n- item", `intro - item`},
+		{"marker after leading space", "  # heading", `\# heading`},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := markdownLine(tt.in); got != tt.want {
+				t.Fatalf("markdownLine(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownChecklist(t *testing.T) {
+	due := time.Date(2026, 10, 20, 18, 30, 0, 0, time.Local)
+	tests := []struct {
+		name  string
+		tasks []Task
+		want  string
+	}{
+		{"no tasks", nil, ""},
+		{"in order", []Task{{Text: "b"}, {Text: "a"}}, "- [ ] b\n- [ ] a\n"},
+		{"due and tags", []Task{{Text: "report", DueDate: &due, Tags: []string{"work", "q_4"}}}, "- [ ] report (due 2026-10-20 18:30) #work #q\\_4\n"},
+		{"notes left out", []Task{{Text: "call", Notes: "- [ ] not a step\n# nor a heading"}}, "- [ ] call\n"},
+		{"multi-line text", []Task{{Text: "1. title\ndetails"}}, "- [ ] 1\\. title details\n"},
+		{"steps", []Task{{Text: "move", Checklist: []ChecklistItem{{Text: "pack", Done: true}, {Text: "- load\ntruck"}}}},
+			"- [ ] move\n  - [x] pack\n  - [ ] \\- load truck\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MarkdownChecklist(tt.tasks); got != tt.want {
+				t.Fatalf("MarkdownChecklist =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package queue
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestOpenablePathDecryptsIntoPrivateCache(t *testing.T) {
+	if runtime.GOOS != "linux" && !strings.Contains(runtime.GOOS, "bsd") {
+		t.Skip("the user cache directory can only be redirected on Linux and BSD")
+	}
+	cache := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cache)
+	t.Setenv(EnvPassphrase, "correct horse")
+	t.Setenv(EnvBackend, "")
+	q, err := NewTaskQueue(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+
+	src := filepath.Join(q.AttachmentsDir(), "note.txt")
+	if err := os.WriteFile(src, []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue(Task{ID: "1", Text: "with a file", Attachments: []Attachment{{Path: src, Type: AttachmentFile}}}); err != nil {
+		t.Fatal(err)
+	}
+	a := q.GetAll()[0].Attachments[0]
+
+	path, err := q.OpenablePath(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path == a.Path {
+		t.Fatal("OpenablePath returned the encrypted file")
+	}
+	if !strings.HasPrefix(path, cache) {
+		t.Fatalf("decrypted copy at %s, want it under %s", path, cache)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "secret" {
+		t.Fatalf("decrypted copy holds %q", b)
+	}
+	fi, err := os.Stat(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0o700 {
+		t.Fatalf("copy folder mode %v, want 0700", fi.Mode().Perm())
+	}
+
+	if err := q.RemoveOpenedCopies(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("decrypted copy still there: %v", err)
+	}
+}
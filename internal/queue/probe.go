@@ -0,0 +1,330 @@
+package queue
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// AttachmentMeta is what probing an attachment file found out about it. For
+// images Width and Height are set, for audio and video DurationSeconds.
+type AttachmentMeta struct {
+	Width           int     `json:"width,omitempty"`
+	Height          int     `json:"height,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds,omitempty"`
+}
+
+// MetaLabel describes the attachment for display: "1920×1080" for images,
+// "0:42" or "1:02:05" for audio and video. It is "" when nothing is known.
+func (a Attachment) MetaLabel() string {
+	m := a.Meta
+	switch {
+	case m == nil:
+		return ""
+	case m.Width > 0 && m.Height > 0:
+		return fmt.Sprintf("%d×%d", m.Width, m.Height)
+	case m.DurationSeconds > 0:
+		d := time.Duration(math.Round(m.DurationSeconds)) * time.Second
+		hrs, mins, secs := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+		if hrs > 0 {
+			return fmt.Sprintf("%d:%02d:%02d", hrs, mins, secs)
+		}
+		return fmt.Sprintf("%d:%02d", mins, secs)
+	}
+	return ""
+}
+
+// errUnknownFormat is returned by the probes for files they cannot read.
+var errUnknownFormat = errors.New("unrecognized format")
+
+// probeAttachment reads the dimensions of an image or the length of an audio
+// or video file. It returns nil when the format is not one it understands or
+// the file is damaged: metadata is a nicety and never fails adding a task.
+// The file must not be encrypted yet.
+func probeAttachment(path string, typ AttachmentType) *AttachmentMeta {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	switch typ {
+	case AttachmentImage:
+		cfg, _, err := image.DecodeConfig(f)
+		if err != nil || cfg.Width <= 0 || cfg.Height <= 0 {
+			return nil
+		}
+		return &AttachmentMeta{Width: cfg.Width, Height: cfg.Height}
+	case AttachmentAudio, AttachmentVideo:
+		fi, err := f.Stat()
+		if err != nil {
+			return nil
+		}
+		var secs float64
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".wav":
+			secs, err = wavDuration(f)
+		case ".mp3":
+			secs, err = mp3Duration(f, fi.Size())
+		case ".ogg":
+			secs, err = oggDuration(f, fi.Size())
+		case ".m4a", ".mp4", ".mov":
+			secs, err = mp4Duration(f, fi.Size())
+		default:
+			return nil
+		}
+		if err != nil || secs <= 0 || math.IsInf(secs, 0) || math.IsNaN(secs) {
+			return nil
+		}
+		return &AttachmentMeta{DurationSeconds: math.Round(secs*10) / 10}
+	}
+	return nil
+}
+
+// wavDuration reads the length of a RIFF/WAVE file from its fmt and data
+// chunks.
+func wavDuration(r io.ReadSeeker) (float64, error) {
+	var hdr [12]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return 0, err
+	}
+	if string(hdr[0:4]) != "RIFF" || string(hdr[8:12]) != "WAVE" {
+		return 0, errUnknownFormat
+	}
+	var byteRate uint32
+	for {
+		var ch [8]byte
+		if _, err := io.ReadFull(r, ch[:]); err != nil {
+			return 0, err
+		}
+		size := int64(binary.LittleEndian.Uint32(ch[4:8]))
+		switch string(ch[0:4]) {
+		case "fmt ":
+			var fmtc [12]byte
+			if size < int64(len(fmtc)) {
+				return 0, errUnknownFormat
+			}
+			if _, err := io.ReadFull(r, fmtc[:]); err != nil {
+				return 0, err
+			}
+			byteRate = binary.LittleEndian.Uint32(fmtc[8:12])
+			size -= int64(len(fmtc))
+		case "data":
+			if byteRate == 0 {
+				return 0, errUnknownFormat
+			}
+			return float64(size) / float64(byteRate), nil
+		}
+		// Chunks are padded to an even size.
+		if _, err := r.Seek(size+size%2, io.SeekCurrent); err != nil {
+			return 0, err
+		}
+	}
+}
+
+var (
+	// mp3Bitrates are the Layer III bitrates in kbit/s by bitrate index, for
+	// MPEG-1 and for MPEG-2/2.5.
+	mp3Bitrates = [2][16]int{
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+	}
+	// mp3SampleRates are in Hz by sample rate index, for MPEG-1, 2 and 2.5.
+	mp3SampleRates = [3][3]int{
+		{44100, 48000, 32000},
+		{22050, 24000, 16000},
+		{11025, 12000, 8000},
+	}
+)
+
+// mp3Duration reads the length of an MP3 file from the frame count in its
+// Xing/Info header, or estimates it from the first frame's bitrate when
+// there is none (constant bitrate files). Only Layer III is supported.
+func mp3Duration(r io.ReadSeeker, size int64) (float64, error) {
+	var start int64
+	var id3 [10]byte
+	if _, err := io.ReadFull(r, id3[:]); err != nil {
+		return 0, err
+	}
+	if string(id3[0:3]) == "ID3" {
+		// The tag size is "syncsafe": 7 bits per byte.
+		n := int64(id3[6]&0x7f)<<21 | int64(id3[7]&0x7f)<<14 | int64(id3[8]&0x7f)<<7 | int64(id3[9]&0x7f)
+		start = 10 + n
+		if id3[5]&0x10 != 0 {
+			start += 10 // footer
+		}
+	}
+	if _, err := r.Seek(start, io.SeekStart); err != nil {
+		return 0, err
+	}
+	buf := make([]byte, 16<<10)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return 0, err
+	}
+	buf = buf[:n]
+
+	for i := 0; i+4 <= len(buf); i++ {
+		b := buf[i:]
+		if b[0] != 0xff || b[1]&0xe0 != 0xe0 {
+			continue
+		}
+		version := (b[1] >> 3) & 3 // 3 MPEG-1, 2 MPEG-2, 0 MPEG-2.5
+		layer := (b[1] >> 1) & 3   // 1 Layer III
+		brIndex, srIndex := b[2]>>4, (b[2]>>2)&3
+		if version == 1 || layer != 1 || brIndex == 0 || brIndex == 15 || srIndex == 3 {
+			continue
+		}
+		mpeg1 := version == 3
+		row, srRow, spf := 1, 1, 576.0
+		switch version {
+		case 3:
+			row, srRow, spf = 0, 0, 1152
+		case 0:
+			srRow = 2
+		}
+		rate := float64(mp3SampleRates[srRow][srIndex])
+		bitrate := float64(mp3Bitrates[row][brIndex]) * 1000
+
+		// The Xing/Info header follows the side information, whose size
+		// depends on the version and on mono versus stereo.
+		mono := b[3]>>6 == 3
+		side := 32
+		switch {
+		case mpeg1 && mono:
+			side = 17
+		case !mpeg1 && mono:
+			side = 9
+		case !mpeg1:
+			side = 17
+		}
+		if x := 4 + side; x+12 <= len(b) {
+			if tag := string(b[x : x+4]); tag == "Xing" || tag == "Info" {
+				if flags := binary.BigEndian.Uint32(b[x+4 : x+8]); flags&1 != 0 {
+					frames := binary.BigEndian.Uint32(b[x+8 : x+12])
+					return float64(frames) * spf / rate, nil
+				}
+			}
+		}
+		return float64(size-start-int64(i)) * 8 / bitrate, nil
+	}
+	return 0, errUnknownFormat
+}
+
+// oggDuration reads the length of an Ogg Vorbis or Opus file from the
+// granule position of its last page and the sample rate in its first.
+func oggDuration(r io.ReadSeeker, size int64) (float64, error) {
+	first := make([]byte, 27+255+19)
+	n, err := io.ReadFull(r, first)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return 0, err
+	}
+	first = first[:n]
+	if len(first) < 27 || string(first[0:4]) != "OggS" || 27+int(first[26]) > len(first) {
+		return 0, errUnknownFormat
+	}
+	body := first[27+int(first[26]):]
+	var rate float64
+	var preSkip int64
+	switch {
+	case len(body) >= 16 && string(body[0:7]) == "\x01vorbis":
+		rate = float64(binary.LittleEndian.Uint32(body[12:16]))
+	case len(body) >= 12 && string(body[0:8]) == "OpusHead":
+		// Opus granule positions always count 48 kHz samples.
+		rate = 48000
+		preSkip = int64(binary.LittleEndian.Uint16(body[10:12]))
+	default:
+		return 0, errUnknownFormat
+	}
+	if rate == 0 {
+		return 0, errUnknownFormat
+	}
+
+	tail := min(size, 64<<10)
+	if _, err := r.Seek(size-tail, io.SeekStart); err != nil {
+		return 0, err
+	}
+	buf := make([]byte, tail)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return 0, err
+	}
+	i := bytes.LastIndex(buf, []byte("OggS"))
+	if i < 0 || i+14 > len(buf) {
+		return 0, errUnknownFormat
+	}
+	granule := int64(binary.LittleEndian.Uint64(buf[i+6 : i+14]))
+	return float64(granule-preSkip) / rate, nil
+}
+
+// mp4Duration reads the length of an MP4, M4A or QuickTime file from the
+// movie header (moov/mvhd), wherever in the file the moov box is.
+func mp4Duration(r io.ReadSeeker, size int64) (float64, error) {
+	moov, moovSize, err := mp4FindBox(r, 0, size, "moov")
+	if err != nil {
+		return 0, err
+	}
+	mvhd, _, err := mp4FindBox(r, moov, moov+moovSize, "mvhd")
+	if err != nil {
+		return 0, err
+	}
+	if _, err := r.Seek(mvhd, io.SeekStart); err != nil {
+		return 0, err
+	}
+	var hdr [32]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return 0, err
+	}
+	var scale, dur uint64
+	if hdr[0] == 1 {
+		// version, flags, 64-bit creation and modification times
+		scale = uint64(binary.BigEndian.Uint32(hdr[20:24]))
+		dur = binary.BigEndian.Uint64(hdr[24:32])
+	} else {
+		scale = uint64(binary.BigEndian.Uint32(hdr[12:16]))
+		dur = uint64(binary.BigEndian.Uint32(hdr[16:20]))
+	}
+	if scale == 0 {
+		return 0, errUnknownFormat
+	}
+	return float64(dur) / float64(scale), nil
+}
+
+// mp4FindBox looks for a box of the given type among the boxes between
+// offsets from and to, and returns the offset and size of its payload.
+func mp4FindBox(r io.ReadSeeker, from, to int64, typ string) (payload, size int64, err error) {
+	for off := from; off+8 <= to; {
+		if _, err := r.Seek(off, io.SeekStart); err != nil {
+			return 0, 0, err
+		}
+		var hdr [16]byte
+		if _, err := io.ReadFull(r, hdr[:8]); err != nil {
+			return 0, 0, err
+		}
+		boxSize, head := int64(binary.BigEndian.Uint32(hdr[0:4])), int64(8)
+		switch boxSize {
+		case 0: // the box runs to the end
+			boxSize = to - off
+		case 1: // 64-bit size follows the type
+			if _, err := io.ReadFull(r, hdr[8:16]); err != nil {
+				return 0, 0, err
+			}
+			boxSize, head = int64(binary.BigEndian.Uint64(hdr[8:16])), 16
+		}
+		if boxSize < head || off+boxSize > to {
+			return 0, 0, errUnknownFormat
+		}
+		if string(hdr[4:8]) == typ {
+			return off + head, boxSize - head, nil
+		}
+		off += boxSize
+	}
+	return 0, 0, errUnknownFormat
+}
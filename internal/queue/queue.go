@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -17,26 +20,249 @@ const (
 	AttachmentNone  AttachmentType = "none"
 	AttachmentImage AttachmentType = "image"
 	AttachmentAudio AttachmentType = "audio"
+	AttachmentVideo AttachmentType = "video"
+	// AttachmentFile is any other type allowed in the settings (see
+	// SetExtraAttachmentExts). It is stored like the rest but not previewed;
+	// the manage UI offers it as a download.
+	AttachmentFile AttachmentType = "file"
+)
+
+// Known reports whether t is one of the types this build handles. A
+// queue.json written by a newer version may carry others; such attachments
+// are kept as they are and shown as plain files.
+func (t AttachmentType) Known() bool {
+	switch t {
+	case AttachmentImage, AttachmentAudio, AttachmentVideo, AttachmentFile:
+		return true
+	}
+	return false
+}
+
+// Attachment is a file stored alongside a task, usually inside attachmentsDir.
+type Attachment struct {
+	Path string          `json:"path"`
+	Type AttachmentType  `json:"type"`
+	Meta *AttachmentMeta `json:"meta,omitempty"` // set when the file is added, see probeAttachment
+}
+
+// builtinExts are the extensions that can be previewed, by type.
+var builtinExts = map[AttachmentType][]string{
+	AttachmentImage: {".png", ".jpg", ".jpeg", ".webp", ".gif"},
+	AttachmentAudio: {".m4a", ".mp3", ".wav", ".ogg"},
+	AttachmentVideo: {".mp4", ".mov", ".webm"},
+}
+
+// blockedExts can never be allowed as attachments: the manage UI hands
+// attachments to the OS to open, and these would run as programs.
+var blockedExts = map[string]bool{
+	".app": true, ".bat": true, ".cmd": true, ".com": true, ".cpl": true,
+	".desktop": true, ".exe": true, ".hta": true, ".jar": true, ".js": true,
+	".jse": true, ".lnk": true, ".msi": true, ".ps1": true, ".scr": true,
+	".sh": true, ".vbe": true, ".vbs": true, ".wsf": true,
+}
+
+var (
+	extraMu   sync.RWMutex
+	extraExts []string
+)
+
+// ParseAttachmentExts normalizes extensions from the settings: "pdf",
+// ".PDF" and "*.pdf" all give ".pdf". Built-in extensions and duplicates are
+// dropped. Entries that are not an extension or name a program are left out
+// and reported in err, so the rest can still be used.
+func ParseAttachmentExts(list []string) (exts []string, err error) {
+	var bad []string
+	seen := map[string]bool{}
+	for _, raw := range list {
+		ext := strings.ToLower(strings.TrimSpace(raw))
+		if ext == "" {
+			continue
+		}
+		ext = "." + strings.TrimLeft(ext, "*.")
+		if ext == "." || strings.ContainsAny(ext[1:], `./\:*?"<>| `) || blockedExts[ext] {
+			bad = append(bad, raw)
+			continue
+		}
+		if _, builtin := builtinTypeForExt(ext); builtin || seen[ext] {
+			continue
+		}
+		seen[ext] = true
+		exts = append(exts, ext)
+	}
+	if len(bad) > 0 {
+		err = fmt.Errorf("not allowed as attachment types: %s", strings.Join(bad, ", "))
+	}
+	return exts, err
+}
+
+// SetExtraAttachmentExts sets the extensions accepted as AttachmentFile on
+// top of the built-in ones, as normalized by ParseAttachmentExts.
+func SetExtraAttachmentExts(exts []string) {
+	extraMu.Lock()
+	extraExts = append([]string(nil), exts...)
+	extraMu.Unlock()
+}
+
+// AttachmentExts returns the extensions accepted for type t, with dots.
+func AttachmentExts(t AttachmentType) []string {
+	if t == AttachmentFile {
+		extraMu.RLock()
+		defer extraMu.RUnlock()
+		return append([]string(nil), extraExts...)
+	}
+	return append([]string(nil), builtinExts[t]...)
+}
+
+func builtinTypeForExt(ext string) (AttachmentType, bool) {
+	for t, exts := range builtinExts {
+		for _, e := range exts {
+			if e == ext {
+				return t, true
+			}
+		}
+	}
+	return AttachmentNone, false
+}
+
+// AttachmentTypeForExt maps a lowercase file extension (with dot) to its
+// type. Extensions allowed in the settings give AttachmentFile.
+func AttachmentTypeForExt(ext string) (AttachmentType, bool) {
+	if t, ok := builtinTypeForExt(ext); ok {
+		return t, true
+	}
+	extraMu.RLock()
+	defer extraMu.RUnlock()
+	for _, e := range extraExts {
+		if e == ext {
+			return AttachmentFile, true
+		}
+	}
+	return AttachmentNone, false
+}
+
+// AttachmentExtForMIME maps a media type, without parameters, to the file
+// extension AttachmentTypeForExt accepts for it.
+func AttachmentExtForMIME(mediaType string) (string, bool) {
+	switch mediaType {
+	case "image/png":
+		return ".png", true
+	case "image/jpeg":
+		return ".jpg", true
+	case "image/gif":
+		return ".gif", true
+	case "image/webp":
+		return ".webp", true
+	case "audio/mpeg", "audio/mp3":
+		return ".mp3", true
+	case "audio/mp4", "audio/x-m4a", "audio/m4a":
+		return ".m4a", true
+	case "audio/wav", "audio/x-wav", "audio/wave":
+		return ".wav", true
+	case "audio/ogg":
+		return ".ogg", true
+	case "video/mp4":
+		return ".mp4", true
+	case "video/quicktime":
+		return ".mov", true
+	case "video/webm":
+		return ".webm", true
+	}
+	return "", false
+}
+
+// Priority levels. Higher values are more urgent; unknown values are allowed
+// and simply sort above Urgent.
+const (
+	PriorityNormal = 0
+	PriorityHigh   = 1
+	PriorityUrgent = 2
 )
 
 type Task struct {
-	ID             string         `json:"id"`
-	Text           string         `json:"text"`
-	CreatedAt      time.Time      `json:"created_at"`
-	StartedAt      time.Time      `json:"started_at,omitempty"`
-	CompletedAt    time.Time      `json:"completed_at,omitempty"`
-	AttachmentPath string         `json:"attachment_path,omitempty"`
-	AttachmentType AttachmentType `json:"attachment_type,omitempty"`
+	ID              string          `json:"id"`
+	Text            string          `json:"text"`
+	Notes           string          `json:"notes,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	StartedAt       time.Time       `json:"started_at,omitempty"`
+	CompletedAt     time.Time       `json:"completed_at,omitempty"`
+	DueDate         *time.Time      `json:"due_date,omitempty"`
+	Priority        int             `json:"priority,omitempty"`
+	Attachments     []Attachment    `json:"attachments,omitempty"`
+	Tags            []string        `json:"tags,omitempty"`
+	Recurrence      string          `json:"recurrence,omitempty"`
+	SnoozedUntil    *time.Time      `json:"snoozed_until,omitempty"`
+	EstimateMinutes int             `json:"estimate_minutes,omitempty"` // 0 means no estimate
+	CompletionNote  string          `json:"completion_note,omitempty"`  // outcome, set when completed
+	BlockedBy       []string        `json:"blocked_by,omitempty"`       // IDs of tasks that must be done first
+	Color           string          `json:"color,omitempty"`            // a Palette name, "" for none
+	ClientID        string          `json:"client_id,omitempty"`        // caller's idempotency key, see EnqueueOnce
+	Pinned          bool            `json:"pinned,omitempty"`           // kept current ahead of the others, see Pin
+	Checklist       []ChecklistItem `json:"checklist,omitempty"`        // steps of the task, see ToggleChecklistItem
+	Reminders       []Reminder      `json:"reminders,omitempty"`        // see FireReminders
+	ArchivedAt      time.Time       `json:"archived_at,omitempty"`      // set while in the archive, see ArchiveOlderThan
+	SkippedAt       time.Time       `json:"skipped_at,omitempty"`       // last Skip, which defers the task under SetDueFirst
+}
+
+// IsSnoozed reports whether the task is still snoozed at now. A snoozed task
+// is passed over by Peek, Complete and Skip; see Snooze.
+func (t Task) IsSnoozed(now time.Time) bool {
+	return t.SnoozedUntil != nil && now.Before(*t.SnoozedUntil)
+}
+
+// Recurrence values. A completed recurring task is re-enqueued as a fresh copy.
+const (
+	RecurNone   = ""
+	RecurDaily  = "daily"
+	RecurWeekly = "weekly"
+)
+
+// recurInterval returns the date step for a recurrence value.
+func recurInterval(r string) (days int, ok bool) {
+	switch r {
+	case RecurDaily:
+		return 1, true
+	case RecurWeekly:
+		return 7, true
+	}
+	return 0, false
+}
+
+// UnmarshalJSON accepts the legacy single attachment_path/attachment_type
+// fields and migrates them into Attachments.
+func (t *Task) UnmarshalJSON(b []byte) error {
+	type plain Task
+	var tmp struct {
+		plain
+		AttachmentPath string         `json:"attachment_path"`
+		AttachmentType AttachmentType `json:"attachment_type"`
+	}
+	if err := json.Unmarshal(b, &tmp); err != nil {
+		return err
+	}
+	*t = Task(tmp.plain)
+	if tmp.AttachmentPath != "" && len(t.Attachments) == 0 {
+		t.Attachments = []Attachment{{Path: tmp.AttachmentPath, Type: tmp.AttachmentType}}
+	}
+	return nil
 }
 
 type TaskHistory struct {
 	mu       sync.Mutex
 	Entries  []Task `json:"entries"`
 	filePath string
+	box      *cipherBox
 }
 
 func NewTaskHistory(baseDir string) (*TaskHistory, error) {
-	h := &TaskHistory{filePath: filepath.Join(baseDir, "history.json")}
+	box, err := cipherFromEnv(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	return newTaskHistory(baseDir, box)
+}
+
+func newTaskHistory(baseDir string, box *cipherBox) (*TaskHistory, error) {
+	h := &TaskHistory{filePath: filepath.Join(baseDir, "history.json"), box: box}
 	if err := h.load(); err != nil {
 		return nil, err
 	}
@@ -54,6 +280,9 @@ func (h *TaskHistory) load() error {
 		}
 		return err
 	}
+	if b, err = h.box.open(b); err != nil {
+		return fmt.Errorf("history.json: %w", err)
+	}
 	var tmp struct {
 		Entries []Task `json:"entries"`
 	}
@@ -69,13 +298,28 @@ func (h *TaskHistory) saveLocked() error {
 	if err != nil {
 		return err
 	}
+	if data, err = h.box.seal(data); err != nil {
+		return err
+	}
 	return atomicWriteFile(h.filePath, data, 0644)
 }
 
-func (h *TaskHistory) Add(t Task) error {
+// MaxHistoryEntries caps history.json; the oldest entries are dropped first.
+const MaxHistoryEntries = 500
+
+// Add records completed tasks, with one write for all of them. ts are in
+// the order they were completed, so the last one ends up first.
+func (h *TaskHistory) Add(ts ...Task) error {
 	h.mu.Lock()
 	defer h.mu.Unlock()
-	h.Entries = append([]Task{t}, h.Entries...)
+	entries := make([]Task, 0, len(ts)+len(h.Entries))
+	for i := len(ts) - 1; i >= 0; i-- {
+		entries = append(entries, ts[i])
+	}
+	h.Entries = append(entries, h.Entries...)
+	if len(h.Entries) > MaxHistoryEntries {
+		h.Entries = h.Entries[:MaxHistoryEntries]
+	}
 	return h.saveLocked()
 }
 
@@ -87,172 +331,1304 @@ func (h *TaskHistory) GetAll() []Task {
 	return res
 }
 
-func (h *TaskHistory) DeleteByID(id string) error {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-	for i, e := range h.Entries {
-		if e.ID == id {
-			h.Entries = append(h.Entries[:i], h.Entries[i+1:]...)
-			return h.saveLocked()
+// LastCompletedAt returns when the most recent task in history was
+// completed. ok is false when history is empty.
+func (h *TaskHistory) LastCompletedAt() (at time.Time, ok bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, e := range h.Entries {
+		if e.CompletedAt.After(at) {
+			at = e.CompletedAt
+		}
+	}
+	return at, !at.IsZero()
+}
+
+func (h *TaskHistory) DeleteByID(id string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, e := range h.Entries {
+		if e.ID == id {
+			h.Entries = append(h.Entries[:i], h.Entries[i+1:]...)
+			return h.saveLocked()
+		}
+	}
+	return fmt.Errorf("history entry not found: %s", id)
+}
+
+func (h *TaskHistory) Clear() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.Entries = nil
+	return h.saveLocked()
+}
+
+type TaskQueue struct {
+	mu             sync.Mutex
+	Tasks          []Task `json:"tasks"`
+	baseDir        string
+	attachmentsDir string
+	history        *TaskHistory
+	templates      *Templates
+	archive        *TaskArchive
+	store          Store
+	holdsFileLock  bool       // set while Exclusive holds the inter-process lock
+	storeVersion   string     // store.Version() as of our last read or write
+	saved          []Task     // Tasks as of our last read or write, see writeLocked
+	undo           undoEntry  // last undoable change, see Undo
+	box            *cipherBox // nil unless EnvPassphrase is set
+	maxLen         int        // 0 means unlimited, see SetMaxLen
+	dueFirst       bool       // see SetDueFirst
+	attachReport   AttachmentReport
+	pending        []Event // changes not yet in events.jsonl, see recordLocked
+	onComplete     func(Task)
+	onSaveError    func(error)
+	onEmpty        func()
+	saveFailing    bool // the last write failed even after retrying
+}
+
+type undoKind int
+
+const (
+	undoNone undoKind = iota
+	undoComplete
+	undoSkip
+	undoDelete
+)
+
+// undoEntry records the task removed (or rotated) by the last undoable change
+// and where it was. Attachments of a removed task stay on disk until the
+// entry is dropped.
+type undoEntry struct {
+	kind    undoKind
+	task    Task
+	index   int
+	spawned string // ID of the recurring copy created by the completion
+}
+
+// ErrNothingToUndo is returned by Undo when there is no change to revert.
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// ErrDependencyCycle is returned (wrapped) when a task's BlockedBy would make
+// it wait, directly or through other tasks, on itself.
+var ErrDependencyCycle = errors.New("dependency cycle")
+
+// ErrQueueFull is returned (wrapped) when adding tasks would exceed the
+// limit set with SetMaxLen.
+var ErrQueueFull = errors.New("queue is full")
+
+// ErrPinned is returned by Skip when the current task is pinned.
+var ErrPinned = errors.New("the current task is pinned")
+
+func NewTaskQueue(baseDir string) (*TaskQueue, error) {
+	q := &TaskQueue{
+		baseDir:        baseDir,
+		attachmentsDir: filepath.Join(baseDir, "attachments"),
+	}
+	_, err := os.Stat(q.attachmentsDir)
+	recreated := errors.Is(err, os.ErrNotExist)
+	if err := os.MkdirAll(q.attachmentsDir, 0o755); err != nil {
+		return nil, err
+	}
+	box, err := cipherFromEnv(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	q.box = box
+	history, err := newTaskHistory(baseDir, box)
+	if err != nil {
+		return nil, err
+	}
+	q.history = history
+	if q.templates, err = newTemplates(baseDir, box); err != nil {
+		return nil, err
+	}
+	if q.archive, err = newTaskArchive(baseDir, box); err != nil {
+		return nil, err
+	}
+	if q.store, err = openStore(baseDir, box); err != nil {
+		return nil, err
+	}
+	if err := q.loadLocked(); err != nil {
+		_ = q.store.Close()
+		return nil, err
+	}
+	q.mu.Lock()
+	q.attachReport = q.checkAttachmentsLocked(recreated)
+	q.mu.Unlock()
+	return q, nil
+}
+
+// Close releases the store. The queue must not be used afterwards.
+func (q *TaskQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.store.Close()
+}
+
+// SetMaxLen caps the number of queued tasks; n <= 0 removes the cap. Tasks
+// already over the limit stay, but nothing new is added until the queue
+// shrinks below it. A recurring task respawning on completion does not count
+// as an addition.
+func (q *TaskQueue) SetMaxLen(n int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.maxLen = max(n, 0)
+}
+
+// checkRoomLocked reports ErrQueueFull if n more tasks do not fit. Caller
+// holds q.mu.
+func (q *TaskQueue) checkRoomLocked(n int) error {
+	if q.maxLen > 0 && len(q.Tasks)+n > q.maxLen {
+		return fmt.Errorf("%w: the limit is %d tasks", ErrQueueFull, q.maxLen)
+	}
+	return nil
+}
+
+// SetOnComplete sets a callback invoked with every task completed by
+// Complete, CompleteWithNote or CompleteByID, once it is saved. It runs with
+// the queue locked, so it must not block or call back into the queue.
+func (q *TaskQueue) SetOnComplete(fn func(Task)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.onComplete = fn
+}
+
+// SetOnEmpty sets a callback invoked when completing or deleting takes the
+// last task out of the queue, once it is saved. It fires once for each time
+// the queue becomes empty, not while it stays empty; emptying the queue with
+// Clear does not count. It runs with the queue locked, so it must not block
+// or call back into the queue.
+func (q *TaskQueue) SetOnEmpty(fn func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.onEmpty = fn
+}
+
+// emptiedLocked calls onEmpty if the queue is empty. Callers have just
+// removed a task and saved, so the queue had a task before. Caller holds
+// q.mu.
+func (q *TaskQueue) emptiedLocked() {
+	if len(q.Tasks) == 0 && q.onEmpty != nil {
+		q.onEmpty()
+	}
+}
+
+// SetOnSaveError sets a callback invoked when writing the queue fails even
+// after retrying. It is called once per run of failures, not for every
+// failed save, and again only after a save has succeeded. It runs with the
+// queue locked, so it must not block or call back into the queue.
+func (q *TaskQueue) SetOnSaveError(fn func(error)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.onSaveError = fn
+}
+
+func (q *TaskQueue) History() *TaskHistory {
+	return q.history
+}
+
+// Templates returns the task templates, see Template.
+func (q *TaskQueue) Templates() *Templates {
+	return q.templates
+}
+
+func (q *TaskQueue) AttachmentsDir() string {
+	return q.attachmentsDir
+}
+
+// ErrCorrupt is returned (wrapped) when the stored queue exists but cannot be parsed.
+var ErrCorrupt = errors.New("queue file is corrupt")
+
+// lockPath is shared by all backends so that processes using different
+// ones still exclude each other.
+func (q *TaskQueue) lockPath() string {
+	return filepath.Join(q.baseDir, "queue.json.lock")
+}
+
+// Exclusive runs fn while holding the inter-process queue lock, after
+// reloading queue.json so fn sees changes made by other processes.
+// Queue methods called from fn do not try to take the lock again.
+func (q *TaskQueue) Exclusive(fn func() error) error {
+	l, err := lockFile(q.lockPath())
+	if err != nil {
+		return err
+	}
+	defer l.Unlock()
+
+	q.mu.Lock()
+	q.holdsFileLock = true
+	err = q.reloadLocked()
+	q.mu.Unlock()
+	defer func() {
+		q.mu.Lock()
+		q.holdsFileLock = false
+		q.mu.Unlock()
+	}()
+	if err != nil {
+		return err
+	}
+	return fn()
+}
+
+// loadLocked reads the stored queue under the inter-process lock, so a
+// concurrent writer is never seen half-way. An upgrade on load, and moving
+// old attachments into per-task folders, saves under the same lock.
+func (q *TaskQueue) loadLocked() error {
+	l, err := lockFile(q.lockPath())
+	if err != nil {
+		return err
+	}
+	defer l.Unlock()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.holdsFileLock = true
+	defer func() { q.holdsFileLock = false }()
+	if err := q.reloadLocked(); err != nil {
+		return err
+	}
+	if q.migrateAttachmentsLocked() {
+		return q.saveLocked()
+	}
+	return nil
+}
+
+// ReloadIfChanged re-reads the stored queue when it was modified by another
+// process (the CLI, a text editor) since our last read or write. Reports
+// whether the queue was reloaded; our own saves never trigger a reload.
+func (q *TaskQueue) ReloadIfChanged() (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.store.Version() == q.storeVersion {
+		return false, nil
+	}
+	if err := q.reloadLocked(); err != nil {
+		return false, err
+	}
+	q.dropUndoLocked()
+	return true, nil
+}
+
+// reloadLocked replaces Tasks with the stored queue. Caller holds q.mu.
+func (q *TaskQueue) reloadLocked() error {
+	// Take the version before reading: if the data changes in between, the
+	// next ReloadIfChanged sees a newer version and simply reads it again.
+	q.storeVersion = q.store.Version()
+	tasks, upgraded, err := q.store.Load()
+	if err != nil {
+		return err
+	}
+	q.Tasks = tasks
+	q.pending = nil
+	// The current task is already active — set StartedAt if missing.
+	q.markActiveLocked()
+	q.saved = append([]Task(nil), q.Tasks...)
+	if upgraded {
+		return q.saveLocked()
+	}
+	return nil
+}
+
+func (q *TaskQueue) saveLocked() error {
+	if err := q.writeLocked(); err != nil {
+		return err
+	}
+	// Any saved change invalidates the previous undo entry; undoable
+	// operations record their own entry after saving.
+	q.dropUndoLocked()
+	return nil
+}
+
+// writeLocked saves the queue like saveLocked but keeps the undo entry, for
+// bookkeeping that is not a change of the user's. When the store cannot be
+// written, Tasks and the pending events go back to what was last saved, so
+// every change either reaches the disk or is undone: memory never holds a
+// change that a later save would write half of. Callers put back anything
+// else they changed. Caller holds q.mu.
+func (q *TaskQueue) writeLocked() error {
+	if !q.holdsFileLock {
+		l, err := lockFile(q.lockPath())
+		if err != nil {
+			return err
+		}
+		defer l.Unlock()
+	}
+	if q.dueFirst {
+		// Any change, such as a new task or an edited due date, may have
+		// made another task current.
+		q.markActiveLocked()
+	}
+	if err := q.storeSaveLocked(); err != nil {
+		q.Tasks = append([]Task(nil), q.saved...)
+		q.pending = nil
+		return err
+	}
+	q.saved = append([]Task(nil), q.Tasks...)
+	q.storeVersion = q.store.Version()
+	q.writeEventsLocked()
+	return nil
+}
+
+// saveRetryDelays are the waits between attempts to write the store, so a
+// file briefly locked by a virus scanner or a sync client does not fail the
+// change. The queue and the file lock are held meanwhile, which stalls the
+// tray and both HTTP servers, so they add up to only 350ms.
+var saveRetryDelays = []time.Duration{
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	200 * time.Millisecond,
+}
+
+// retrySave calls save until it succeeds, waiting saveRetryDelays between
+// attempts, and returns the last error if every attempt fails.
+func retrySave(save func() error) error {
+	err := save()
+	for _, d := range saveRetryDelays {
+		if err == nil {
+			return nil
+		}
+		log.Printf("[queue] save: %v; retrying in %v", err, d)
+		time.Sleep(d)
+		err = save()
+	}
+	return err
+}
+
+// storeSaveLocked writes the tasks to the store with retrySave. Caller
+// holds q.mu.
+func (q *TaskQueue) storeSaveLocked() error {
+	err := retrySave(func() error { return q.store.Save(q.Tasks) })
+	if err != nil {
+		if !q.saveFailing && q.onSaveError != nil {
+			q.onSaveError(err)
+		}
+		q.saveFailing = true
+		return err
+	}
+	if q.saveFailing {
+		log.Printf("[queue] save: succeeded again")
+	}
+	q.saveFailing = false
+	return nil
+}
+
+// Flush writes the queue to the store one last time before the process
+// exits. Every change is saved when it is made, or undone when that fails,
+// so this is only a safety net; if another process changed the store since our
+// last read or write, its version is newer and is left alone.
+func (q *TaskQueue) Flush() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	l, err := lockFile(q.lockPath())
+	if err != nil {
+		return err
+	}
+	defer l.Unlock()
+	if q.store.Version() != q.storeVersion {
+		return nil
+	}
+	if err := q.storeSaveLocked(); err != nil {
+		return err
+	}
+	q.storeVersion = q.store.Version()
+	q.writeEventsLocked()
+	return nil
+}
+
+// dropUndoLocked forgets the undo entry, deleting the attachments of a task
+// that can no longer be restored. Caller holds q.mu.
+func (q *TaskQueue) dropUndoLocked() {
+	if q.undo.kind == undoComplete || q.undo.kind == undoDelete {
+		q.removeAttachments(q.undo.task)
+	}
+	q.undo = undoEntry{}
+}
+
+// DiscardUndo forgets the undo entry and deletes attachments it was keeping.
+// Call it before the process exits.
+func (q *TaskQueue) DiscardUndo() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.dropUndoLocked()
+}
+
+// UndoAction names the change Undo would revert: "complete", "skip",
+// "delete", or "" when there is nothing to undo.
+func (q *TaskQueue) UndoAction() string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	switch q.undo.kind {
+	case undoComplete:
+		return "complete"
+	case undoSkip:
+		return "skip"
+	case undoDelete:
+		return "delete"
+	}
+	return ""
+}
+
+// Undo reverts the last Complete, CompleteByID, Skip or DeleteByID. Only one
+// level is kept, and any other change to the queue discards it. A completed
+// task is also removed from history.
+func (q *TaskQueue) Undo() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	u := q.undo
+	switch u.kind {
+	case undoSkip:
+		n := len(q.Tasks)
+		if n == 0 || q.Tasks[n-1].ID != u.task.ID {
+			return ErrNothingToUndo
+		}
+		last := q.Tasks[n-1]
+		last.SkippedAt = u.task.SkippedAt
+		i := min(u.index, n-1)
+		copy(q.Tasks[i+1:], q.Tasks[i:n-1])
+		q.Tasks[i] = last
+		q.recordOrderLocked()
+	case undoComplete, undoDelete:
+		if u.spawned != "" {
+			q.removeTaskLocked(u.spawned)
+			q.recordLocked(EventDelete, u.spawned)
+		}
+		i := min(u.index, len(q.Tasks))
+		q.Tasks = append(q.Tasks[:i], append([]Task{u.task}, q.Tasks[i:]...)...)
+		q.recordTaskLocked(EventEnqueue, u.task, i)
+	default:
+		return ErrNothingToUndo
+	}
+	// Clear before saving so saveLocked does not delete the restored attachments.
+	q.undo = undoEntry{}
+	if err := q.saveLocked(); err != nil {
+		return err
+	}
+	if u.kind == undoComplete && q.history != nil {
+		if err := q.history.DeleteByID(u.task.ID); err != nil {
+			log.Printf("[queue] history: %v", err)
+		}
+	}
+	return nil
+}
+
+// prepareAttachmentsLocked moves newly added attachments of task id into
+// its folder (updating as), records their dimensions or length, writes
+// thumbnails for images and encrypts the files in place when a passphrase is
+// set. An image that cannot be decoded simply gets no thumbnail, and a file
+// that cannot be probed no metadata. Caller holds q.mu.
+func (q *TaskQueue) prepareAttachmentsLocked(id string, as []Attachment) error {
+	for i := range as {
+		if as[i].Path == "" {
+			continue
+		}
+		p, err := q.placeAttachmentLocked(id, as[i].Path)
+		if err != nil {
+			return fmt.Errorf("store attachment: %w", err)
+		}
+		as[i].Path = p
+		as[i].Meta = probeAttachment(p, as[i].Type)
+		a := as[i]
+		if a.Type == AttachmentImage {
+			if err := q.writeThumbLocked(a.Path); err != nil {
+				log.Printf("[queue] thumbnail for %s: %v", filepath.Base(a.Path), err)
+			}
+		}
+		if err := q.box.sealFile(a.Path); err != nil {
+			return fmt.Errorf("encrypt attachment: %w", err)
+		}
+	}
+	return nil
+}
+
+// ReadAttachment returns the contents of an attachment file, decrypting it
+// if it was stored encrypted.
+func (q *TaskQueue) ReadAttachment(path string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return q.box.open(b)
+}
+
+// ErrAttachmentMissing is returned (wrapped) when an attachment file is no
+// longer where the task says it is.
+var ErrAttachmentMissing = errors.New("attachment file was moved or deleted")
+
+// OpenablePath returns a path other applications can open the attachment
+// from. That is the file itself unless it is stored encrypted, in which case
+// a decrypted copy is written to openedDir; RemoveOpenedCopies deletes it.
+func (q *TaskQueue) OpenablePath(a Attachment) (string, error) {
+	if a.Path == "" {
+		return "", fmt.Errorf("%w: no file", ErrAttachmentMissing)
+	}
+	data, err := os.ReadFile(a.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s", ErrAttachmentMissing, a.Path)
+	}
+	if err != nil {
+		return "", err
+	}
+	if !isEncrypted(data) {
+		return a.Path, nil
+	}
+	plain, err := q.box.open(data)
+	if err != nil {
+		return "", err
+	}
+	dir, err := openedDir()
+	if err != nil {
+		return "", fmt.Errorf("decrypted copy: %w", err)
+	}
+	dst := filepath.Join(dir, filepath.Base(a.Path))
+	if err := atomicWriteFile(dst, plain, 0o600); err != nil {
+		return "", err
+	}
+	return dst, nil
+}
+
+// openedDirPath is the folder for OpenablePath's decrypted copies, in the
+// user's cache directory: not the shared temp directory, which other users
+// can list, and not the data folder, which may be synced somewhere.
+func openedDirPath() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "systray-queue-app", "open"), nil
+}
+
+// openedDir returns openedDirPath, created if needed and readable by the user
+// only.
+func openedDir() (string, error) {
+	dir, err := openedDirPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	// MkdirAll leaves the mode of a folder that already existed alone.
+	if err := os.Chmod(dir, 0o700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// RemoveOpenedCopies deletes the decrypted copies made by OpenablePath, as
+// well as those older versions left in the system temp directory. The app
+// calls it at start and exit, so plaintext does not stay on disk; the CLI
+// does not, as it would pull files from under a viewer the app opened.
+func (q *TaskQueue) RemoveOpenedCopies() error {
+	_ = os.RemoveAll(filepath.Join(os.TempDir(), "systray-queue-open"))
+	dir, err := openedDirPath()
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+func (q *TaskQueue) Enqueue(t Task) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err := q.checkRoomLocked(1); err != nil {
+		return err
+	}
+	if err := q.checkDepsLocked(t); err != nil {
+		return err
+	}
+	if err := q.prepareAttachmentsLocked(t.ID, t.Attachments); err != nil {
+		return err
+	}
+	if len(q.Tasks) == 0 {
+		t.StartedAt = time.Now()
+	}
+	q.Tasks = append(q.Tasks, t)
+	q.recordTaskLocked(EventEnqueue, t, len(q.Tasks)-1)
+	return q.saveLocked()
+}
+
+// EnqueueWithPriority inserts t after every task with the same or higher
+// priority, so a queue ordered by priority (descending) and then by creation
+// time stays ordered. A task that lands at the head becomes the active one.
+func (q *TaskQueue) EnqueueWithPriority(t Task) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.enqueueWithPriorityLocked(t)
+}
+
+// EnqueueOnce is EnqueueWithPriority for callers that retry on failure, such
+// as scripts using the HTTP API. When t has a ClientID and a task with the
+// same ClientID was created less than window ago, whether still queued or
+// already completed, nothing is added and that task is returned with added
+// false. A zero window turns the check off.
+func (q *TaskQueue) EnqueueOnce(t Task, window time.Duration) (existing Task, added bool, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if t.ClientID != "" && window > 0 {
+		since := time.Now().Add(-window)
+		match := func(c Task) bool { return c.ClientID == t.ClientID && c.CreatedAt.After(since) }
+		for _, c := range q.Tasks {
+			if match(c) {
+				return c, false, nil
+			}
+		}
+		if h := q.history; h != nil {
+			h.mu.Lock()
+			for _, c := range h.Entries {
+				if match(c) {
+					h.mu.Unlock()
+					return c, false, nil
+				}
+			}
+			h.mu.Unlock()
+		}
+	}
+	if err := q.enqueueWithPriorityLocked(t); err != nil {
+		return Task{}, false, err
+	}
+	return t, true, nil
+}
+
+func (q *TaskQueue) enqueueWithPriorityLocked(t Task) error {
+	if err := q.checkRoomLocked(1); err != nil {
+		return err
+	}
+	if err := q.checkDepsLocked(t); err != nil {
+		return err
+	}
+	if err := q.prepareAttachmentsLocked(t.ID, t.Attachments); err != nil {
+		return err
+	}
+	q.insertByPriorityLocked(t)
+	return q.saveLocked()
+}
+
+// EnqueueAll inserts the tasks like EnqueueWithPriority, in order, and saves once.
+func (q *TaskQueue) EnqueueAll(ts []Task) error {
+	if len(ts) == 0 {
+		return nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err := q.checkRoomLocked(len(ts)); err != nil {
+		return err
+	}
+	for _, t := range ts {
+		if err := q.checkDepsLocked(t); err != nil {
+			return err
+		}
+		if err := q.prepareAttachmentsLocked(t.ID, t.Attachments); err != nil {
+			return err
+		}
+		q.insertByPriorityLocked(t)
+	}
+	return q.saveLocked()
+}
+
+// insertByPriorityLocked does the insertion for EnqueueWithPriority. Caller
+// holds q.mu.
+func (q *TaskQueue) insertByPriorityLocked(t Task) {
+	pos := len(q.Tasks)
+	for i, existing := range q.Tasks {
+		if existing.Priority < t.Priority {
+			pos = i
+			break
+		}
+	}
+	q.Tasks = append(q.Tasks, Task{})
+	copy(q.Tasks[pos+1:], q.Tasks[pos:])
+	q.Tasks[pos] = t
+	if pos == 0 && !q.blockedLocked(t) {
+		q.Tasks[0].StartedAt = time.Now()
+	}
+	q.recordTaskLocked(EventEnqueue, q.Tasks[pos], pos)
+}
+
+// respawnLocked enqueues the next occurrence of a completed recurring task:
+// a fresh copy with a new ID, its own copies of the attachments and the due
+// date moved forward past now. A task without a due date is snoozed until
+// one interval after it was completed instead, so it does not come straight
+// back as current. Returns the new task, or a zero Task for one-off tasks.
+// Caller holds q.mu.
+func (q *TaskQueue) respawnLocked(done Task) Task {
+	days, ok := recurInterval(done.Recurrence)
+	if !ok {
+		return Task{}
+	}
+	now := time.Now()
+	next := Task{
+		ID:              strconv.FormatInt(now.UnixNano(), 10),
+		Text:            done.Text,
+		Notes:           done.Notes,
+		CreatedAt:       now,
+		Priority:        done.Priority,
+		Tags:            append([]string(nil), done.Tags...),
+		Recurrence:      done.Recurrence,
+		EstimateMinutes: done.EstimateMinutes,
+		Color:           done.Color,
+		Checklist:       freshChecklist(done.Checklist),
+	}
+	if done.DueDate != nil {
+		due := done.DueDate.AddDate(0, 0, days)
+		for !due.After(now) {
+			due = due.AddDate(0, 0, days)
+		}
+		next.DueDate = &due
+	} else {
+		from := done.CompletedAt
+		if from.IsZero() {
+			from = now
+		}
+		until := from.AddDate(0, 0, days)
+		next.SnoozedUntil = &until
+	}
+	for _, a := range done.Attachments {
+		p, err := q.copyAttachmentLocked(a.Path, next.ID)
+		if err != nil {
+			log.Printf("[queue] recurring task %s: copy attachment: %v", done.ID, err)
+			continue
+		}
+		next.Attachments = append(next.Attachments, Attachment{Path: p, Type: a.Type, Meta: a.Meta})
+	}
+	q.insertByPriorityLocked(next)
+	return next
+}
+
+// Duplicate appends a copy of the task with id to the end of the queue. The
+// copy gets a new ID and creation time and its own copies of the attachment
+// files, so deleting one task never removes the other's files. Its checklist
+// starts with nothing checked.
+func (q *TaskQueue) Duplicate(id string) (Task, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var src Task
+	found := false
+	for _, t := range q.Tasks {
+		if t.ID == id {
+			src, found = t, true
+			break
+		}
+	}
+	if !found {
+		return Task{}, fmt.Errorf("task not found: %s", id)
+	}
+	if err := q.checkRoomLocked(1); err != nil {
+		return Task{}, err
+	}
+	now := time.Now()
+	dup := Task{
+		ID:              strconv.FormatInt(now.UnixNano(), 10),
+		Text:            src.Text,
+		Notes:           src.Notes,
+		CreatedAt:       now,
+		Priority:        src.Priority,
+		Tags:            append([]string(nil), src.Tags...),
+		Recurrence:      src.Recurrence,
+		EstimateMinutes: src.EstimateMinutes,
+		BlockedBy:       append([]string(nil), src.BlockedBy...),
+		Color:           src.Color,
+		Checklist:       freshChecklist(src.Checklist),
+	}
+	if src.DueDate != nil {
+		due := *src.DueDate
+		dup.DueDate = &due
+	}
+	for _, a := range src.Attachments {
+		p, err := q.copyAttachmentLocked(a.Path, dup.ID)
+		if err != nil {
+			q.removeAttachments(dup)
+			return Task{}, err
+		}
+		dup.Attachments = append(dup.Attachments, Attachment{Path: p, Type: a.Type, Meta: a.Meta})
+	}
+	if len(q.Tasks) == 0 {
+		dup.StartedAt = now
+	}
+	q.Tasks = append(q.Tasks, dup)
+	q.recordTaskLocked(EventEnqueue, dup, len(q.Tasks)-1)
+	if err := q.saveLocked(); err != nil {
+		q.removeAttachments(dup)
+		return Task{}, err
+	}
+	return dup, nil
+}
+
+// Requeue enqueues a fresh copy of the history entry with id, like
+// EnqueueWithPriority, and removes the entry from history. The copy gets a
+// new ID and creation time, an unchecked checklist and its own copies of the
+// attachment files; due date, dependencies and completion note are left
+// behind. Files that were deleted since completion cannot be brought back;
+// they are dropped from the copy and counted in missing.
+func (q *TaskQueue) Requeue(id string) (t Task, missing int, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.history == nil {
+		return Task{}, 0, fmt.Errorf("history entry not found: %s", id)
+	}
+	var src Task
+	found := false
+	for _, e := range q.history.GetAll() {
+		if e.ID == id {
+			src, found = e, true
+			break
+		}
+	}
+	if !found {
+		return Task{}, 0, fmt.Errorf("history entry not found: %s", id)
+	}
+	if err := q.checkRoomLocked(1); err != nil {
+		return Task{}, 0, err
+	}
+	now := time.Now()
+	t = Task{
+		ID:              strconv.FormatInt(now.UnixNano(), 10),
+		Text:            src.Text,
+		Notes:           src.Notes,
+		CreatedAt:       now,
+		Priority:        src.Priority,
+		Tags:            append([]string(nil), src.Tags...),
+		Recurrence:      src.Recurrence,
+		EstimateMinutes: src.EstimateMinutes,
+		Color:           src.Color,
+		Checklist:       freshChecklist(src.Checklist),
+	}
+	for _, a := range src.Attachments {
+		p, err := q.copyAttachmentLocked(a.Path, t.ID)
+		if errors.Is(err, os.ErrNotExist) {
+			log.Printf("[queue] requeue %s: attachment %s is gone", id, a.Path)
+			missing++
+			continue
+		}
+		if err != nil {
+			q.removeAttachments(t)
+			return Task{}, 0, err
+		}
+		t.Attachments = append(t.Attachments, Attachment{Path: p, Type: a.Type, Meta: a.Meta})
+	}
+	q.insertByPriorityLocked(t)
+	if err := q.saveLocked(); err != nil {
+		q.removeAttachments(t)
+		return Task{}, 0, err
+	}
+	if err := q.history.DeleteByID(id); err != nil {
+		log.Printf("[queue] history: %v", err)
+	}
+	for _, queued := range q.Tasks {
+		if queued.ID == t.ID {
+			t = queued
+		}
+	}
+	return t, missing, nil
+}
+
+// copyAttachmentLocked copies an attachment file into the folder of task id
+// under the same name, together with its thumbnail if it has one. Encrypted
+// files are copied as-is.
+func (q *TaskQueue) copyAttachmentLocked(src, id string) (string, error) {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return "", err
+	}
+	dir := q.taskDir(id)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	dst := uniquePath(dir, unstagedName(filepath.Base(src)))
+	if err := atomicWriteFile(dst, data, 0644); err != nil {
+		return "", err
+	}
+	if thumb, err := os.ReadFile(ThumbPath(src)); err == nil {
+		_ = atomicWriteFile(ThumbPath(dst), thumb, 0644)
+	}
+	return dst, nil
+}
+
+// removeTaskLocked drops the task with id and deletes its attachments.
+// Caller holds q.mu.
+func (q *TaskQueue) removeTaskLocked(id string) {
+	for i, t := range q.Tasks {
+		if t.ID == id {
+			q.Tasks = append(q.Tasks[:i], q.Tasks[i+1:]...)
+			q.removeAttachments(t)
+			return
+		}
+	}
+}
+
+func (q *TaskQueue) GetAll() []Task {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	res := make([]Task, len(q.Tasks))
+	copy(res, q.Tasks)
+	return res
+}
+
+// FilterByTag returns the tasks carrying tag, in queue order.
+func (q *TaskQueue) FilterByTag(tag string) []Task {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var res []Task
+	for _, t := range q.Tasks {
+		if t.HasTag(tag) {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// Search returns the tasks whose text, notes or tags contain query, ignoring case
+// (Unicode-aware, so Cyrillic works too), in queue order. An empty query
+// matches nothing.
+func (q *TaskQueue) Search(query string) []Task {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var res []Task
+	for _, t := range q.Tasks {
+		if t.Matches(query) {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// Tags returns every distinct tag in the queue, sorted case-insensitively.
+func (q *TaskQueue) Tags() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var tags []string
+	for _, t := range q.Tasks {
+		for _, tag := range t.Tags {
+			if !hasTag(tags, tag) {
+				tags = append(tags, tag)
+			}
 		}
 	}
-	return fmt.Errorf("history entry not found: %s", id)
+	sort.Slice(tags, func(i, j int) bool { return strings.ToLower(tags[i]) < strings.ToLower(tags[j]) })
+	return tags
 }
 
-func (h *TaskHistory) Clear() error {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-	h.Entries = nil
-	return h.saveLocked()
+// Count returns the number of tasks in the queue.
+func (q *TaskQueue) Count() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.Tasks)
 }
 
-type TaskQueue struct {
-	mu             sync.Mutex
-	Tasks          []Task `json:"tasks"`
-	filePath       string
-	attachmentsDir string
-	history        *TaskHistory
+// SetDueFirst makes the current task the one with the earliest due date
+// instead of the first in line; tasks without a due date follow in queue
+// order. Skip still moves a task on: a skipped task waits behind every task
+// not skipped after it (see CompareDueFirst). The queue itself is not
+// reordered, so turning it off brings back the queue order at once. A
+// pinned task stays current either way.
+func (q *TaskQueue) SetDueFirst(on bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.dueFirst = on
+	q.markActiveLocked()
 }
 
-func NewTaskQueue(baseDir string) (*TaskQueue, error) {
-	q := &TaskQueue{
-		filePath:       filepath.Join(baseDir, "queue.json"),
-		attachmentsDir: filepath.Join(baseDir, "attachments"),
-	}
-	if err := os.MkdirAll(q.attachmentsDir, 0o755); err != nil {
-		return nil, err
-	}
-	history, err := NewTaskHistory(baseDir)
-	if err != nil {
-		return nil, err
-	}
-	q.history = history
-	if err := q.loadLocked(); err != nil {
-		return nil, err
-	}
-	return q, nil
+// DueFirst reports whether SetDueFirst is on.
+func (q *TaskQueue) DueFirst() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.dueFirst
 }
 
-func (q *TaskQueue) History() *TaskHistory {
-	return q.history
+// activeIndexLocked returns the index of the current task: the pinned one,
+// else the first one that is neither snoozed nor blocked (with SetDueFirst,
+// the earliest due of those), or -1 if there is none. A snoozed or blocked
+// pinned task is passed over like any other. Caller holds q.mu.
+func (q *TaskQueue) activeIndexLocked() int {
+	now := time.Now()
+	first := -1
+	for i, t := range q.Tasks {
+		if t.IsSnoozed(now) || q.blockedLocked(t) {
+			continue
+		}
+		if t.Pinned {
+			return i
+		}
+		if first < 0 || q.dueFirst && CompareDueFirst(t, q.Tasks[first]) < 0 {
+			first = i
+		}
+	}
+	return first
 }
 
-func (q *TaskQueue) AttachmentsDir() string {
-	return q.attachmentsDir
+// markActiveLocked sets StartedAt on the current task if it has none yet.
+// Caller holds q.mu.
+func (q *TaskQueue) markActiveLocked() {
+	if i := q.activeIndexLocked(); i >= 0 && q.Tasks[i].StartedAt.IsZero() {
+		q.Tasks[i].StartedAt = time.Now()
+	}
 }
 
-func (q *TaskQueue) loadLocked() error {
+// Upcoming returns up to n tasks that follow the current one, in the order
+// activeIndexLocked would serve them if nothing else changed: pinned tasks
+// first, then the rest in queue order (with SetDueFirst, earliest due
+// first). Snoozed and blocked tasks are left out.
+func (q *TaskQueue) Upcoming(n int) []Task {
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	b, err := os.ReadFile(q.filePath)
-	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			q.Tasks = nil
-			return nil
+	now := time.Now()
+	cur := q.activeIndexLocked()
+	var pinned, rest []Task
+	for i, t := range q.Tasks {
+		if i == cur || t.IsSnoozed(now) || q.blockedLocked(t) {
+			continue
+		}
+		if t.Pinned {
+			pinned = append(pinned, t)
+		} else {
+			rest = append(rest, t)
 		}
-		return err
-	}
-	var tmp struct {
-		Tasks []Task `json:"tasks"`
-	}
-	if err := json.Unmarshal(b, &tmp); err != nil {
-		return err
 	}
-	q.Tasks = tmp.Tasks
-	// First task in queue is already active — set StartedAt if missing.
-	if len(q.Tasks) > 0 && q.Tasks[0].StartedAt.IsZero() {
-		q.Tasks[0].StartedAt = time.Now()
+	if q.dueFirst {
+		sort.SliceStable(rest, func(i, j int) bool { return CompareDueFirst(rest[i], rest[j]) < 0 })
 	}
-	return nil
-}
-
-func (q *TaskQueue) saveLocked() error {
-	data, err := json.MarshalIndent(q, "", "  ")
-	if err != nil {
-		return err
+	next := append(pinned, rest...)
+	if len(next) > n {
+		next = next[:n]
 	}
-	return atomicWriteFile(q.filePath, data, 0644)
+	return next
 }
 
-func (q *TaskQueue) Enqueue(t Task) error {
+// Peek returns the current task: the pinned one if any, else the first one
+// that is neither snoozed nor blocked by another queued task.
+func (q *TaskQueue) Peek() (Task, bool) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	if len(q.Tasks) == 0 {
-		t.StartedAt = time.Now()
+	i := q.activeIndexLocked()
+	if i < 0 {
+		return Task{}, false
 	}
-	q.Tasks = append(q.Tasks, t)
-	return q.saveLocked()
+	return q.Tasks[i], true
 }
 
-func (q *TaskQueue) GetAll() []Task {
+// Skip moves the current task to the end of the queue and records when, so
+// under SetDueFirst it also waits behind the others. A pinned task stays
+// current and Skip returns ErrPinned. Skip does nothing when no other task
+// would become current.
+func (q *TaskQueue) Skip() error {
 	q.mu.Lock()
 	defer q.mu.Unlock()
-
-	res := make([]Task, len(q.Tasks))
-	copy(res, q.Tasks)
-	return res
+	return q.skipLocked(q.activeIndexLocked())
 }
 
-func (q *TaskQueue) Peek() (Task, bool) {
+// SkipByID is Skip for the task with the given ID, so a page skips the task
+// it shows even when another one has become current since. A task that is
+// no longer queued is left alone.
+func (q *TaskQueue) SkipByID(id string) error {
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	if len(q.Tasks) == 0 {
-		return Task{}, false
+	for i, t := range q.Tasks {
+		if t.ID == id {
+			return q.skipLocked(i)
+		}
 	}
-	return q.Tasks[0], true
+	return nil
 }
 
-func (q *TaskQueue) Skip() error {
-	q.mu.Lock()
-	defer q.mu.Unlock()
-	if len(q.Tasks) <= 1 {
+// skipLocked moves the task at index i to the end of the queue, see Skip.
+// Caller holds q.mu.
+func (q *TaskQueue) skipLocked(i int) error {
+	if i >= 0 && q.Tasks[i].Pinned {
+		return ErrPinned
+	}
+	if i < 0 || i == len(q.Tasks)-1 && !q.dueFirst {
 		return nil
 	}
-	first := q.Tasks[0]
-	q.Tasks = append(q.Tasks[1:], first)
-	// New first task — mark when it became active.
-	if q.Tasks[0].StartedAt.IsZero() {
-		q.Tasks[0].StartedAt = time.Now()
+	cur := q.Tasks[i]
+	before := q.Tasks
+	skipped := cur
+	skipped.SkippedAt = time.Now()
+	q.Tasks = append(append(q.Tasks[:i:i], q.Tasks[i+1:]...), skipped)
+	if j := q.activeIndexLocked(); j < 0 || q.Tasks[j].ID == cur.ID {
+		q.Tasks = before
+		return nil
 	}
-	return q.saveLocked()
+	q.recordLocked(EventSkip, cur.ID)
+	// New current task — mark when it became active.
+	q.markActiveLocked()
+	if err := q.saveLocked(); err != nil {
+		return err
+	}
+	q.undo = undoEntry{kind: undoSkip, task: cur, index: i}
+	return nil
 }
 
+// Complete moves the current task to history. Returns a zero Task when
+// there is no current task.
 func (q *TaskQueue) Complete() (Task, error) {
+	return q.CompleteWithNote("")
+}
+
+// CompleteWithNote completes the current task like Complete and stores note,
+// trimmed, as its CompletionNote in history. An empty note stores nothing.
+func (q *TaskQueue) CompleteWithNote(note string) (Task, error) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	if len(q.Tasks) == 0 {
+	i := q.activeIndexLocked()
+	if i < 0 {
 		return Task{}, nil
 	}
 
-	task := q.Tasks[0]
+	orig := q.Tasks[i]
+	task := orig
 	task.CompletedAt = time.Now()
+	task.CompletionNote = strings.TrimSpace(note)
+	task.Pinned = false
 	if task.StartedAt.IsZero() {
 		task.StartedAt = task.CreatedAt
 	}
-	q.Tasks = q.Tasks[1:]
+	q.Tasks = append(q.Tasks[:i], q.Tasks[i+1:]...)
+	q.recordLocked(EventComplete, task.ID)
+	spawned := q.respawnLocked(task)
 
 	// The next task becomes active — mark when it started.
-	if len(q.Tasks) > 0 && q.Tasks[0].StartedAt.IsZero() {
-		q.Tasks[0].StartedAt = time.Now()
-	}
+	q.markActiveLocked()
 
 	if err := q.saveLocked(); err != nil {
+		q.removeAttachments(spawned)
 		return Task{}, err
 	}
 
 	if q.history != nil {
-		_ = q.history.Add(task)
+		if err := q.history.Add(task); err != nil {
+			log.Printf("[queue] history: %v", err)
+		}
+	}
+	q.undo = undoEntry{kind: undoComplete, task: orig, index: i, spawned: spawned.ID}
+	if q.onComplete != nil {
+		q.onComplete(task)
 	}
+	q.emptiedLocked()
 
-	if task.AttachmentPath != "" && q.attachmentsDir != "" {
-		inside, err := isPathInsideDir(task.AttachmentPath, q.attachmentsDir)
-		if err == nil && inside {
-			_ = os.Remove(task.AttachmentPath)
+	return task, nil
+}
+
+// Snooze hides the task from Peek, Complete and Skip until the given time;
+// it keeps its place in the queue and becomes current again once the time
+// has passed, if nothing is ahead of it. A time that is not in the future
+// wakes the task up right away.
+func (q *TaskQueue) Snooze(id string, until time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i := range q.Tasks {
+		if q.Tasks[i].ID != id {
+			continue
+		}
+		if until.After(time.Now()) {
+			q.Tasks[i].SnoozedUntil = &until
+			// It is no longer being worked on.
+			q.Tasks[i].StartedAt = time.Time{}
+		} else {
+			q.Tasks[i].SnoozedUntil = nil
 		}
+		q.recordTaskLocked(EventUpdate, q.Tasks[i], i)
+		q.markActiveLocked()
+		return q.saveLocked()
 	}
+	return fmt.Errorf("task not found: %s", id)
+}
 
-	return task, nil
+// ParseEstimate reads a time estimate as whole minutes ("90") or a duration
+// such as "1h30m" or "45m", and returns it in minutes. An empty string means
+// no estimate (0).
+func ParseEstimate(s string) (int, error) {
+	s = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), " ", ""))
+	if s == "" {
+		return 0, nil
+	}
+	if m, err := strconv.Atoi(s); err == nil {
+		if m < 0 {
+			return 0, fmt.Errorf("negative estimate: %s", s)
+		}
+		return m, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("bad estimate %q, expected minutes or e.g. 1h30m", s)
+	}
+	return int(d.Round(time.Minute) / time.Minute), nil
+}
+
+// FormatEstimate renders minutes as "45m", "2h" or "3h 20m", and a missing
+// estimate (0) as "—".
+func FormatEstimate(minutes int) string {
+	switch h, m := minutes/60, minutes%60; {
+	case minutes <= 0:
+		return "—"
+	case h == 0:
+		return fmt.Sprintf("%dm", m)
+	case m == 0:
+		return fmt.Sprintf("%dh", h)
+	default:
+		return fmt.Sprintf("%dh %dm", h, m)
+	}
+}
+
+// ParseTags splits a comma-separated list into trimmed, de-duplicated tags.
+func ParseTags(s string) []string {
+	var tags []string
+	for _, part := range strings.Split(s, ",") {
+		tag := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(part), "#"))
+		if tag == "" || hasTag(tags, tag) {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// HasTag reports whether the task carries tag (case-insensitive).
+func (t Task) HasTag(tag string) bool {
+	return hasTag(t.Tags, tag)
+}
+
+// Matches reports whether the task text, notes or one of its tags contains
+// query, ignoring case. An empty query never matches.
+func (t Task) Matches(query string) bool {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return false
+	}
+	if strings.Contains(strings.ToLower(t.Text), query) || strings.Contains(strings.ToLower(t.Notes), query) {
+		return true
+	}
+	for _, tag := range t.Tags {
+		if strings.Contains(strings.ToLower(tag), query) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOverdue reports whether the task has a due date that is already in the past.
+func (t Task) IsOverdue(now time.Time) bool {
+	return t.DueDate != nil && now.After(*t.DueDate)
+}
+
+// At returns the task at position index (0 is the head) without changing
+// the queue. ok is false when index is out of range.
+func (q *TaskQueue) At(index int) (Task, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if index < 0 || index >= len(q.Tasks) {
+		return Task{}, false
+	}
+	return q.Tasks[index], true
 }
 
 func (q *TaskQueue) GetByID(id string) (Task, bool) {
@@ -272,41 +1648,157 @@ func (q *TaskQueue) UpdateText(id, text string) error {
 	for i := range q.Tasks {
 		if q.Tasks[i].ID == id {
 			q.Tasks[i].Text = text
+			q.recordTaskLocked(EventUpdate, q.Tasks[i], i)
 			return q.saveLocked()
 		}
 	}
 	return fmt.Errorf("task not found: %s", id)
 }
 
-func (q *TaskQueue) UpdateTask(id, text, attachmentPath string, attachmentType AttachmentType) error {
+// UpdateTask sets the task text and appends any new attachments.
+func (q *TaskQueue) UpdateTask(id, text string, added ...Attachment) error {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 	for i := range q.Tasks {
 		if q.Tasks[i].ID == id {
-			q.Tasks[i].Text = text
-			if attachmentPath != "" {
-				q.Tasks[i].AttachmentPath = attachmentPath
-				q.Tasks[i].AttachmentType = attachmentType
+			if err := q.prepareAttachmentsLocked(id, added); err != nil {
+				return err
 			}
+			q.Tasks[i].Text = text
+			q.Tasks[i].Attachments = append(q.Tasks[i].Attachments, added...)
+			q.recordTaskLocked(EventUpdate, q.Tasks[i], i)
 			return q.saveLocked()
 		}
 	}
 	return fmt.Errorf("task not found: %s", id)
 }
 
-func (q *TaskQueue) DeleteByID(id string) error {
+// ClearAttachments detaches all attachments from the task and deletes the files.
+func (q *TaskQueue) ClearAttachments(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i := range q.Tasks {
+		if q.Tasks[i].ID == id {
+			old := q.Tasks[i]
+			q.Tasks[i].Attachments = nil
+			q.recordTaskLocked(EventUpdate, q.Tasks[i], i)
+			if err := q.saveLocked(); err != nil {
+				return err
+			}
+			q.removeAttachments(old)
+			return nil
+		}
+	}
+	return fmt.Errorf("task not found: %s", id)
+}
+
+// DeleteByID removes the task without recording it in history. Its
+// attachments are deleted once the change can no longer be undone.
+// Returns the removed task.
+func (q *TaskQueue) DeleteByID(id string) (Task, error) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 	for i, t := range q.Tasks {
 		if t.ID == id {
 			q.Tasks = append(q.Tasks[:i], q.Tasks[i+1:]...)
-			if i == 0 && len(q.Tasks) > 0 && q.Tasks[0].StartedAt.IsZero() {
-				q.Tasks[0].StartedAt = time.Now()
+			q.recordLocked(EventDelete, t.ID)
+			q.markActiveLocked()
+			if err := q.saveLocked(); err != nil {
+				return Task{}, err
+			}
+			q.undo = undoEntry{kind: undoDelete, task: t, index: i}
+			q.emptiedLocked()
+			return t, nil
+		}
+	}
+	return Task{}, fmt.Errorf("task not found: %s", id)
+}
+
+// gcGracePeriod protects recently written files that may not be attached to
+// a task yet (an upload in the web editor, a voice note being recorded).
+const gcGracePeriod = 10 * time.Minute
+
+// GCAttachments deletes files in attachmentsDir and the task folders in it
+// that no queued task, history entry, archived task or pending undo refers
+// to, and then the task folders left empty. Returns the number of files
+// removed.
+func (q *TaskQueue) GCAttachments() (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	keep := map[string]bool{}
+	mark := func(ts []Task) {
+		for _, t := range ts {
+			for _, a := range t.Attachments {
+				if a.Path != "" {
+					keep[filepath.Clean(a.Path)] = true
+					keep[filepath.Clean(ThumbPath(a.Path))] = true
+				}
+			}
+		}
+	}
+	mark(q.Tasks)
+	mark([]Task{q.undo.task})
+	if q.history != nil {
+		mark(q.history.GetAll())
+	}
+	mark(q.archive.GetAll())
+
+	cutoff := time.Now().Add(-gcGracePeriod)
+	removed := 0
+	var sweep func(dir string, top bool) error
+	sweep = func(dir string, top bool) error {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			p := filepath.Join(dir, e.Name())
+			if e.IsDir() && top {
+				if err := sweep(p, false); err != nil {
+					return err
+				}
+				_ = os.Remove(p) // only succeeds once the folder is empty
+				continue
+			}
+			if !e.Type().IsRegular() || keep[p] {
+				continue
+			}
+			if fi, err := e.Info(); err != nil || fi.ModTime().After(cutoff) {
+				continue
+			}
+			if err := os.Remove(p); err != nil {
+				return err
+			}
+			removed++
+		}
+		return nil
+	}
+	err := sweep(q.attachmentsDir, true)
+	return removed, err
+}
+
+// removeAttachments deletes the task's attachment files that live in
+// attachmentsDir, and its folder when that is left empty.
+func (q *TaskQueue) removeAttachments(t Task) {
+	if q.attachmentsDir == "" {
+		return
+	}
+	for _, a := range t.Attachments {
+		if a.Path == "" {
+			continue
+		}
+		inside, err := isPathInsideDir(a.Path, q.attachmentsDir)
+		if err == nil && inside {
+			_ = os.Remove(a.Path)
+			_ = os.Remove(ThumbPath(a.Path))
+			// The task's folder goes once it is empty. Only then: IDs that
+			// map to the same folder name must not lose each other's files.
+			if dir := filepath.Dir(a.Path); filepath.Clean(dir) != filepath.Clean(q.attachmentsDir) {
+				_ = os.Remove(dir)
 			}
-			return q.saveLocked()
 		}
 	}
-	return fmt.Errorf("task not found: %s", id)
 }
 
 func (q *TaskQueue) CompleteByID(id string) (Task, error) {
@@ -314,35 +1806,144 @@ func (q *TaskQueue) CompleteByID(id string) (Task, error) {
 	defer q.mu.Unlock()
 	for i, t := range q.Tasks {
 		if t.ID == id {
+			orig := t
 			t.CompletedAt = time.Now()
+			t.Pinned = false
 			if t.StartedAt.IsZero() {
 				t.StartedAt = t.CreatedAt
 			}
 			q.Tasks = append(q.Tasks[:i], q.Tasks[i+1:]...)
-			if i == 0 && len(q.Tasks) > 0 && q.Tasks[0].StartedAt.IsZero() {
-				q.Tasks[0].StartedAt = time.Now()
-			}
+			q.recordLocked(EventComplete, t.ID)
+			spawned := q.respawnLocked(t)
+			q.markActiveLocked()
 			if err := q.saveLocked(); err != nil {
+				q.removeAttachments(spawned)
 				return Task{}, err
 			}
 			if q.history != nil {
-				_ = q.history.Add(t)
-			}
-			if t.AttachmentPath != "" && q.attachmentsDir != "" {
-				inside, err := isPathInsideDir(t.AttachmentPath, q.attachmentsDir)
-				if err == nil && inside {
-					_ = os.Remove(t.AttachmentPath)
+				if err := q.history.Add(t); err != nil {
+					log.Printf("[queue] history: %v", err)
 				}
 			}
+			q.undo = undoEntry{kind: undoComplete, task: orig, index: i, spawned: spawned.ID}
+			if q.onComplete != nil {
+				q.onComplete(t)
+			}
+			q.emptiedLocked()
 			return t, nil
 		}
 	}
 	return Task{}, fmt.Errorf("task not found: %s", id)
 }
 
-func (q *TaskQueue) ReorderByIndices(order []int) error {
+// Promote moves the task to the head of the queue, keeping the relative order
+// of the others, and wakes it up if it was snoozed. Promoting the current
+// head is a no-op.
+func (q *TaskQueue) Promote(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, t := range q.Tasks {
+		if t.ID != id {
+			continue
+		}
+		if i == 0 && !t.IsSnoozed(time.Now()) {
+			return nil
+		}
+		t.SnoozedUntil = nil
+		copy(q.Tasks[1:i+1], q.Tasks[:i])
+		q.Tasks[0] = t
+		q.recordTaskLocked(EventUpdate, t, 0)
+		q.recordOrderLocked()
+		q.markActiveLocked()
+		return q.saveLocked()
+	}
+	return fmt.Errorf("task not found: %s", id)
+}
+
+// Pin makes the task current and keeps it so: Skip leaves it in place and
+// Promote and reordering no longer change which task is current. Any other
+// pinned task is unpinned, so at most one is pinned at a time.
+func (q *TaskQueue) Pin(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	found := false
+	for _, t := range q.Tasks {
+		if t.ID == id {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("task not found: %s", id)
+	}
+	for i := range q.Tasks {
+		if pin := q.Tasks[i].ID == id; q.Tasks[i].Pinned != pin {
+			q.Tasks[i].Pinned = pin
+			q.recordTaskLocked(EventUpdate, q.Tasks[i], i)
+		}
+	}
+	q.markActiveLocked()
+	return q.saveLocked()
+}
+
+// Unpin clears the pin, if any task has one; the queue order then decides
+// the current task again.
+func (q *TaskQueue) Unpin() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	changed := false
+	for i := range q.Tasks {
+		if q.Tasks[i].Pinned {
+			q.Tasks[i].Pinned = false
+			q.recordTaskLocked(EventUpdate, q.Tasks[i], i)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	q.markActiveLocked()
+	return q.saveLocked()
+}
+
+// Pinned returns the pinned task, if there is one.
+func (q *TaskQueue) Pinned() (Task, bool) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
+	for _, t := range q.Tasks {
+		if t.Pinned {
+			return t, true
+		}
+	}
+	return Task{}, false
+}
+
+// ErrStaleOrder is returned (wrapped) by ReorderByIDs when the IDs are not
+// exactly the queued tasks, e.g. because the queue changed since the caller
+// read it.
+var ErrStaleOrder = errors.New("order does not match the queue")
+
+// ReorderByIDs puts the tasks in the order of ids, which must name every
+// queued task exactly once.
+func (q *TaskQueue) ReorderByIDs(ids []string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(ids) != len(q.Tasks) {
+		return fmt.Errorf("%w: got %d tasks, queue has %d", ErrStaleOrder, len(ids), len(q.Tasks))
+	}
+	pos := make(map[string]int, len(q.Tasks))
+	for i, t := range q.Tasks {
+		pos[t.ID] = i
+	}
+	order := make([]int, len(ids))
+	for i, id := range ids {
+		idx, ok := pos[id]
+		if !ok {
+			return fmt.Errorf("%w: unknown or repeated task %s", ErrStaleOrder, id)
+		}
+		delete(pos, id)
+		order[i] = idx
+	}
 	return q.reorderByIndicesLocked(order)
 }
 
@@ -367,6 +1968,8 @@ func (q *TaskQueue) reorderByIndicesLocked(order []int) error {
 	}
 
 	q.Tasks = newTasks
+	q.recordOrderLocked()
+	q.markActiveLocked()
 	return q.saveLocked()
 }
 
@@ -431,4 +2034,4 @@ func isPathInsideDir(filePath, dir string) (bool, error) {
 		return false, nil
 	}
 	return true, nil
-}
\ No newline at end of file
+}
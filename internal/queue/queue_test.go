@@ -0,0 +1,188 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestConcurrentAccess hammers the queue from several goroutines, as the
+// tray, the HTTP servers and the reload poll do. Run it with -race.
+func TestConcurrentAccess(t *testing.T) {
+	q := newTestQueue(t)
+	const (
+		writers = 3
+		perEach = 10
+	)
+	var wg sync.WaitGroup
+	for w := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perEach {
+				id := fmt.Sprintf("%d-%d", w, i)
+				if err := q.Enqueue(Task{ID: id, Text: id, CreatedAt: time.Now()}); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	completed := make(chan int, writers)
+	for range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n := 0
+			for range perEach {
+				task, err := q.Complete()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if task.ID != "" {
+					n++
+				}
+			}
+			completed <- n
+		}()
+	}
+	stop := make(chan struct{})
+	var readers sync.WaitGroup
+	for range 2 {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if n := q.Count(); n < 0 || n > writers*perEach {
+					t.Errorf("Count = %d", n)
+				}
+				q.Peek()
+				_ = q.GetAll()
+			}
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	readers.Wait()
+	close(completed)
+
+	done := 0
+	for n := range completed {
+		done += n
+	}
+	if got := q.Count() + done; got != writers*perEach {
+		t.Fatalf("queued %d + completed %d = %d, want %d", q.Count(), done, got, writers*perEach)
+	}
+	if got := len(q.History().GetAll()); got != done {
+		t.Fatalf("history has %d tasks, want %d", got, done)
+	}
+}
+
+func TestSkipByID(t *testing.T) {
+	q := newTestQueue(t)
+	for _, id := range []string{"a", "b", "c"} {
+		if err := q.Enqueue(Task{ID: id, Text: id, CreatedAt: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	order := func() string {
+		s := ""
+		for _, task := range q.GetAll() {
+			s += task.ID
+		}
+		return s
+	}
+	// b is skipped although a is current, as when a page still shows b.
+	if err := q.SkipByID("b"); err != nil {
+		t.Fatal(err)
+	}
+	if got := order(); got != "acb" {
+		t.Fatalf("after skipping b: %s, want acb", got)
+	}
+	if err := q.SkipByID("gone"); err != nil || order() != "acb" {
+		t.Fatalf("skipping a task no longer queued: %v, order %s", err, order())
+	}
+	if err := q.Pin("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.SkipByID("a"); !errors.Is(err, ErrPinned) {
+		t.Fatalf("skipping the pinned task = %v, want ErrPinned", err)
+	}
+}
+
+func TestRecurringTaskWithoutDueDateWaitsOneInterval(t *testing.T) {
+	q := newTestQueue(t)
+	if err := q.Enqueue(Task{ID: "a", Text: "water plants", Recurrence: RecurDaily, CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	done, err := q.CompleteByID("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := q.GetAll()
+	if len(all) != 1 {
+		t.Fatalf("queue has %d tasks, want the next occurrence", len(all))
+	}
+	next := all[0]
+	want := done.CompletedAt.AddDate(0, 0, 1)
+	if next.SnoozedUntil == nil || !next.SnoozedUntil.Equal(want) {
+		t.Fatalf("next occurrence snoozed until %v, want %v", next.SnoozedUntil, want)
+	}
+	if next.DueDate != nil {
+		t.Fatalf("next occurrence got due date %v", next.DueDate)
+	}
+	if cur, ok := q.Peek(); ok {
+		t.Fatalf("%q is current right after completing it", cur.Text)
+	}
+}
+
+func TestUpcomingFollowsServingOrder(t *testing.T) {
+	later := time.Now().Add(time.Hour)
+	ids := func(tasks []Task) string {
+		s := ""
+		for _, t := range tasks {
+			s += t.ID
+		}
+		return s
+	}
+	for _, dueFirst := range []bool{false, true} {
+		t.Run(fmt.Sprintf("dueFirst=%t", dueFirst), func(t *testing.T) {
+			q := &TaskQueue{dueFirst: dueFirst, Tasks: []Task{
+				{ID: "a", SnoozedUntil: &later},
+				{ID: "b", DueDate: at(3 * time.Hour)},
+				{ID: "c", BlockedBy: []string{"b"}},
+				{ID: "d"},
+				{ID: "e", DueDate: at(time.Hour)},
+				{ID: "f", Pinned: true},
+				{ID: "g", DueDate: at(2 * time.Hour)},
+			}}
+			want := map[bool]string{false: "bdeg", true: "egbd"}[dueFirst]
+			if got := ids(q.Upcoming(10)); got != want {
+				t.Fatalf("Upcoming = %s, want %s", got, want)
+			}
+			if got := ids(q.Upcoming(2)); got != want[:2] {
+				t.Fatalf("Upcoming(2) = %s, want %s", got, want[:2])
+			}
+
+			// Without snoozed or blocked tasks, completing the current one
+			// each time serves them in the order Upcoming listed.
+			q.Tasks = slices.DeleteFunc(q.Tasks, func(t Task) bool { return t.ID == "a" || t.ID == "c" })
+			for _, id := range want {
+				cur := q.activeIndexLocked()
+				q.Tasks = slices.Delete(q.Tasks, cur, cur+1)
+				if i := q.activeIndexLocked(); i < 0 || q.Tasks[i].ID != string(id) {
+					t.Fatalf("served %d, want %c", i, id)
+				}
+			}
+		})
+	}
+}
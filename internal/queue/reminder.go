@@ -0,0 +1,93 @@
+package queue
+
+import "time"
+
+// Reminder is a moment to be notified about a task. Offsets such as "in 2
+// hours" or "30 minutes before due" are resolved to an absolute time when
+// the task is added. Fired is set once the notification has been shown, so
+// a restart does not show it again.
+type Reminder struct {
+	At    time.Time `json:"at"`
+	Fired bool      `json:"fired,omitempty"`
+}
+
+// ReminderOption is one of the reminders offered when adding a task.
+type ReminderOption struct {
+	Label  string // English; callers translate it for display
+	Before bool   // Offset is before the due date rather than after now
+	Offset time.Duration
+}
+
+// ReminderOptions are the reminders the add dialogs offer.
+var ReminderOptions = []ReminderOption{
+	{"In 30 minutes", false, 30 * time.Minute},
+	{"In 1 hour", false, time.Hour},
+	{"In 2 hours", false, 2 * time.Hour},
+	{"30 minutes before due", true, 30 * time.Minute},
+	{"1 hour before due", true, time.Hour},
+	{"1 day before due", true, 24 * time.Hour},
+}
+
+// At resolves the option for a task added at now with the given due date.
+// ok is false when the option needs a due date and there is none, or when
+// the time it gives is already past.
+func (o ReminderOption) At(now time.Time, due *time.Time) (at time.Time, ok bool) {
+	if !o.Before {
+		return now.Add(o.Offset), true
+	}
+	if due == nil {
+		return time.Time{}, false
+	}
+	at = due.Add(-o.Offset)
+	return at, at.After(now)
+}
+
+// NextReminder returns the earliest reminder of t that has not fired yet.
+func (t Task) NextReminder() (time.Time, bool) {
+	var next time.Time
+	for _, r := range t.Reminders {
+		if !r.Fired && (next.IsZero() || r.At.Before(next)) {
+			next = r.At
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// FireReminders marks every reminder that is due by now and has not fired as
+// fired, saves, and returns the tasks that had one, once each. Snoozed and
+// blocked tasks are included: a reminder is about the time, not the order.
+// Firing is not a change of the user's, so it keeps the undo entry.
+func (q *TaskQueue) FireReminders(now time.Time) ([]Task, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var fired []Task
+	for i := range q.Tasks {
+		due := false
+		for _, r := range q.Tasks[i].Reminders {
+			if !r.Fired && !r.At.After(now) {
+				due = true
+				break
+			}
+		}
+		if !due {
+			continue
+		}
+		// Copy so tasks handed out earlier keep their own slice.
+		rs := append([]Reminder(nil), q.Tasks[i].Reminders...)
+		for j := range rs {
+			if !rs[j].At.After(now) {
+				rs[j].Fired = true
+			}
+		}
+		q.Tasks[i].Reminders = rs
+		q.recordTaskLocked(EventUpdate, q.Tasks[i], i)
+		fired = append(fired, q.Tasks[i])
+	}
+	if len(fired) == 0 {
+		return nil, nil
+	}
+	if err := q.writeLocked(); err != nil {
+		return nil, err
+	}
+	return fired, nil
+}
@@ -0,0 +1,143 @@
+package queue
+
+import (
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxBackups is the largest number of rotated copies SetBackupCount keeps.
+const MaxBackups = 50
+
+// Backup is a rotated copy of queue.json, see SetBackupCount.
+type Backup struct {
+	Path    string
+	SavedAt time.Time // when this version of the queue was saved
+	Tasks   int       // -1 when the copy cannot be read
+}
+
+// SetBackupCount makes every save keep the previous n versions of queue.json
+// as queue.json.1 (the newest) to queue.json.n. 0, the default, keeps none
+// and deletes the copies left from before. Only the json backend rotates
+// copies; with sqlite this does nothing.
+func (q *TaskQueue) SetBackupCount(n int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if s, ok := q.store.(*jsonStore); ok {
+		s.setKeep(min(max(n, 0), MaxBackups))
+	}
+}
+
+// Backups lists the rotated copies of queue.json, newest first.
+func (q *TaskQueue) Backups() []Backup {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	s, ok := q.store.(*jsonStore)
+	if !ok {
+		return nil
+	}
+	var out []Backup
+	for _, p := range s.rotated() {
+		b := Backup{Path: p, Tasks: -1}
+		if fi, err := os.Stat(p); err == nil {
+			b.SavedAt = fi.ModTime()
+		}
+		if tasks, _, err := readTasksFile(p, q.box); err == nil {
+			b.Tasks = len(tasks)
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+// RestoreBackup replaces the queue with the tasks in a copy listed by
+// Backups. The queue as it was becomes the newest copy, so a restore can
+// itself be reverted. Attachment files deleted since the copy was made are
+// not brought back. Returns the number of tasks restored.
+func (q *TaskQueue) RestoreBackup(path string) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	tasks, _, err := readTasksFile(path, q.box)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, t := range q.Tasks {
+		q.recordLocked(EventDelete, t.ID)
+	}
+	q.Tasks = tasks
+	for i, t := range tasks {
+		q.recordTaskLocked(EventEnqueue, t, i)
+	}
+	q.markActiveLocked()
+	if err := q.saveLocked(); err != nil {
+		return 0, err
+	}
+	return len(tasks), nil
+}
+
+func (s *jsonStore) rotatedPath(i int) string {
+	return s.path + "." + strconv.Itoa(i)
+}
+
+// rotated returns the paths of the existing rotated copies, newest first.
+func (s *jsonStore) rotated() []string {
+	entries, _ := os.ReadDir(filepath.Dir(s.path))
+	prefix := filepath.Base(s.path) + "."
+	var ns []int
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Name(), prefix) || !e.Type().IsRegular() {
+			continue
+		}
+		if n, err := strconv.Atoi(strings.TrimPrefix(e.Name(), prefix)); err == nil && n >= 1 {
+			ns = append(ns, n)
+		}
+	}
+	sort.Ints(ns)
+	paths := make([]string, len(ns))
+	for i, n := range ns {
+		paths[i] = s.rotatedPath(n)
+	}
+	return paths
+}
+
+// setKeep sets how many copies rotate keeps and deletes the ones beyond it.
+func (s *jsonStore) setKeep(n int) {
+	s.keep = n
+	for _, p := range s.rotated() {
+		if i, _ := strconv.Atoi(strings.TrimPrefix(p, s.path+".")); i > n {
+			if err := os.Remove(p); err != nil {
+				log.Printf("[queue] backups: %v", err)
+			}
+		}
+	}
+}
+
+// rotate shifts the copies up by one with renames, dropping the oldest, and
+// writes prev, the version just replaced, as the newest with its original
+// modification time. A copy that cannot be written is only logged: it must
+// not fail the save that has already succeeded.
+func (s *jsonStore) rotate(prev []byte, savedAt time.Time) {
+	if s.keep <= 0 {
+		return
+	}
+	if err := os.Remove(s.rotatedPath(s.keep)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("[queue] backups: %v", err)
+	}
+	for i := s.keep - 1; i >= 1; i-- {
+		if err := os.Rename(s.rotatedPath(i), s.rotatedPath(i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("[queue] backups: %v", err)
+		}
+	}
+	p := s.rotatedPath(1)
+	if err := atomicWriteFile(p, prev, 0644); err != nil {
+		log.Printf("[queue] backups: write %s: %v", filepath.Base(p), err)
+		return
+	}
+	_ = os.Chtimes(p, savedAt, savedAt)
+}
@@ -0,0 +1,156 @@
+package queue
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTestQueue opens a plain JSON queue in a fresh directory.
+func newTestQueue(t *testing.T) *TaskQueue {
+	t.Helper()
+	t.Setenv(EnvPassphrase, "")
+	t.Setenv(EnvBackend, "")
+	q, err := NewTaskQueue(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = q.Close() })
+	return q
+}
+
+func enqueueTexts(t *testing.T, q *TaskQueue, texts ...string) {
+	t.Helper()
+	for i, text := range texts {
+		id := time.Now().Add(time.Duration(i)).Format("150405.000000000")
+		if err := q.Enqueue(Task{ID: id + text, Text: text, CreatedAt: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// failingStore wraps a store and fails the next fails calls to Save.
+type failingStore struct {
+	Store
+	fails int
+	calls int
+}
+
+var errDiskGone = errors.New("disk gone")
+
+func (s *failingStore) Save(tasks []Task) error {
+	s.calls++
+	if s.fails > 0 {
+		s.fails--
+		return errDiskGone
+	}
+	return s.Store.Save(tasks)
+}
+
+func noRetryDelay(t *testing.T) {
+	t.Helper()
+	old := saveRetryDelays
+	saveRetryDelays = []time.Duration{0, 0, 0}
+	t.Cleanup(func() { saveRetryDelays = old })
+}
+
+func TestRetrySaveSucceedsOnThirdAttempt(t *testing.T) {
+	noRetryDelay(t)
+	calls := 0
+	err := retrySave(func() error {
+		calls++
+		if calls < 3 {
+			return errDiskGone
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("retrySave = %v after %d calls, want nil after 3", err, calls)
+	}
+}
+
+func TestRetrySaveGivesUp(t *testing.T) {
+	noRetryDelay(t)
+	calls := 0
+	err := retrySave(func() error {
+		calls++
+		return errDiskGone
+	})
+	if !errors.Is(err, errDiskGone) || calls != len(saveRetryDelays)+1 {
+		t.Fatalf("retrySave = %v after %d calls, want errDiskGone after %d", err, calls, len(saveRetryDelays)+1)
+	}
+}
+
+func TestRetryDelaysStayShort(t *testing.T) {
+	var total time.Duration
+	for _, d := range saveRetryDelays {
+		total += d
+	}
+	if total > 500*time.Millisecond {
+		t.Fatalf("retries hold the queue lock for %v", total)
+	}
+}
+
+func TestQueueSavesOnThirdAttempt(t *testing.T) {
+	noRetryDelay(t)
+	q := newTestQueue(t)
+	fs := &failingStore{Store: q.store, fails: 2}
+	q.store = fs
+	enqueueTexts(t, q, "a")
+	if fs.calls != 3 || q.Count() != 1 {
+		t.Fatalf("calls = %d, count = %d; want 3 and 1", fs.calls, q.Count())
+	}
+}
+
+func TestFailedSaveRollsBack(t *testing.T) {
+	noRetryDelay(t)
+	tests := []struct {
+		name   string
+		change func(q *TaskQueue) error
+	}{
+		{"Complete", func(q *TaskQueue) error { _, err := q.Complete(); return err }},
+		{"CompleteByID", func(q *TaskQueue) error { _, err := q.CompleteByID(q.GetAll()[1].ID); return err }},
+		{"CompleteMany", func(q *TaskQueue) error {
+			_, err := q.CompleteMany([]string{q.GetAll()[0].ID, q.GetAll()[1].ID})
+			return err
+		}},
+		{"DeleteByID", func(q *TaskQueue) error { _, err := q.DeleteByID(q.GetAll()[0].ID); return err }},
+		{"Enqueue", func(q *TaskQueue) error { return q.Enqueue(Task{ID: "new", Text: "c"}) }},
+		{"Skip", func(q *TaskQueue) error { return q.Skip() }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newTestQueue(t)
+			enqueueTexts(t, q, "a", "b")
+			before := q.GetAll()
+			q.store = &failingStore{Store: q.store, fails: len(saveRetryDelays) + 1}
+
+			if err := tt.change(q); !errors.Is(err, errDiskGone) {
+				t.Fatalf("error = %v, want errDiskGone", err)
+			}
+			after := q.GetAll()
+			if len(after) != len(before) {
+				t.Fatalf("queue has %d tasks after a failed save, want %d", len(after), len(before))
+			}
+			for i := range before {
+				if after[i].ID != before[i].ID {
+					t.Fatalf("task %d is %s after a failed save, want %s", i, after[i].ID, before[i].ID)
+				}
+			}
+			if n := len(q.History().GetAll()); n != 0 {
+				t.Fatalf("history has %d tasks after a failed save", n)
+			}
+			if len(q.pending) != 0 {
+				t.Fatalf("%d events left for the next save", len(q.pending))
+			}
+
+			// The next successful save must not drop anything either.
+			if _, err := q.Complete(); err != nil {
+				t.Fatal(err)
+			}
+			if q.Count() != len(before)-1 || len(q.History().GetAll()) != 1 {
+				t.Fatalf("after a good Complete: count %d, history %d", q.Count(), len(q.History().GetAll()))
+			}
+		})
+	}
+}
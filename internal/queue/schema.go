@@ -0,0 +1,61 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// SchemaVersion is the queue.json format written by this build. Bump it and
+// append a step to migrations whenever stored data needs converting.
+const SchemaVersion = 1
+
+// ErrNewerSchema is returned (wrapped) when queue.json was written by a newer
+// build. The file is left alone rather than rewritten without fields this
+// build does not know about.
+var ErrNewerSchema = errors.New("queue file was written by a newer version of the app")
+
+// queueFile is the persisted form of the queue. Files written before
+// versioning have no version field and decode as version 0.
+type queueFile struct {
+	Version int    `json:"version"`
+	Tasks   []Task `json:"tasks"`
+}
+
+// migrations[v] upgrades a file from version v to v+1.
+var migrations = []func(*queueFile){
+	migrateV0,
+}
+
+// migrate upgrades f to SchemaVersion in place and reports whether anything
+// had to be upgraded.
+func migrate(f *queueFile) (bool, error) {
+	if f.Version > SchemaVersion || f.Version < 0 {
+		return false, fmt.Errorf("%w (version %d, supported %d)", ErrNewerSchema, f.Version, SchemaVersion)
+	}
+	from := f.Version
+	for f.Version < SchemaVersion {
+		migrations[f.Version](f)
+		f.Version++
+	}
+	return f.Version != from, nil
+}
+
+// migrateV0 upgrades files written before versioning. Fields added since then
+// (priority, tags, due date, notes, recurrence) decode to their zero values,
+// which are their defaults, and the single attachment_path is converted by
+// Task.UnmarshalJSON. What is left is giving every task an ID and dropping
+// recurrence values this build does not understand.
+func migrateV0(f *queueFile) {
+	now := time.Now().UnixNano()
+	for i := range f.Tasks {
+		t := &f.Tasks[i]
+		if t.ID == "" {
+			t.ID = strconv.FormatInt(now+int64(i), 10)
+		}
+		if _, ok := recurInterval(t.Recurrence); !ok {
+			t.Recurrence = RecurNone
+		}
+	}
+}
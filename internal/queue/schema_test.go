@@ -0,0 +1,183 @@
+package queue
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReadTasksFileMigrates(t *testing.T) {
+	tests := []struct {
+		fixture  string
+		migrated bool
+		check    func(t *testing.T, tasks []Task)
+	}{
+		{"queue_v0_first_release.json", true, func(t *testing.T, tasks []Task) {
+			if len(tasks) != 2 {
+				t.Fatalf("got %d tasks, want 2", len(tasks))
+			}
+			if tasks[0].ID != "1712345678901234567" || tasks[0].Text != "Call the bank" {
+				t.Errorf("task 0 = %+v", tasks[0])
+			}
+			if tasks[0].StartedAt.IsZero() {
+				t.Error("started_at was lost")
+			}
+			want := []Attachment{{Path: "/data/attachments/1712345678901234568.png", Type: AttachmentImage}}
+			if !reflect.DeepEqual(tasks[1].Attachments, want) {
+				t.Errorf("attachment_path became %+v, want %+v", tasks[1].Attachments, want)
+			}
+			if tasks[1].Priority != 0 || tasks[1].Tags != nil || tasks[1].DueDate != nil || tasks[1].Recurrence != RecurNone {
+				t.Errorf("fields added since have no defaults: %+v", tasks[1])
+			}
+		}},
+		{"queue_v0_unversioned.json", true, func(t *testing.T, tasks []Task) {
+			if len(tasks) != 2 {
+				t.Fatalf("got %d tasks, want 2", len(tasks))
+			}
+			if tasks[0].ID == "" || tasks[1].ID == "" || tasks[0].ID == tasks[1].ID {
+				t.Errorf("IDs %q and %q, want two distinct ones", tasks[0].ID, tasks[1].ID)
+			}
+			a := tasks[0]
+			due := time.Date(2025, 1, 12, 9, 0, 0, 0, time.UTC)
+			if a.Priority != 2 || !reflect.DeepEqual(a.Tags, []string{"home"}) || a.Recurrence != RecurWeekly ||
+				a.DueDate == nil || !a.DueDate.Equal(due) || a.Notes != "The ficus too" {
+				t.Errorf("task 0 lost data: %+v", a)
+			}
+			if tasks[1].Recurrence != RecurNone {
+				t.Errorf("unknown recurrence kept as %q", tasks[1].Recurrence)
+			}
+			if tasks[1].Text != "Pay rent" {
+				t.Errorf("task 1 text %q", tasks[1].Text)
+			}
+		}},
+		{"queue_v1.json", false, func(t *testing.T, tasks []Task) {
+			if len(tasks) != 2 {
+				t.Fatalf("got %d tasks, want 2", len(tasks))
+			}
+			a := tasks[0]
+			if !a.Pinned || a.EstimateMinutes != 45 || a.Color != "blue" || len(a.Checklist) != 2 ||
+				!a.Checklist[0].Done || len(a.BlockedBy) != 1 || len(a.Attachments) != 1 || a.SkippedAt.IsZero() {
+				t.Errorf("current version lost data: %+v", a)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			tasks, migrated, err := readTasksFile(filepath.Join("testdata", tt.fixture), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if migrated != tt.migrated {
+				t.Errorf("migrated = %v, want %v", migrated, tt.migrated)
+			}
+			tt.check(t, tasks)
+
+			// Saved again, the upgraded queue reads back unchanged and
+			// needs no further upgrade.
+			s := newTestStore(t)
+			if err := s.Save(tasks); err != nil {
+				t.Fatal(err)
+			}
+			again, migrated, err := s.Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if migrated {
+				t.Error("a saved queue needs upgrading again")
+			}
+			if !reflect.DeepEqual(normalizeTimes(again), normalizeTimes(tasks)) {
+				t.Errorf("round trip changed the tasks:\n got %+v\nwant %+v", again, tasks)
+			}
+		})
+	}
+}
+
+// normalizeTimes drops monotonic clock readings and locations, which a JSON
+// round trip does not keep.
+func normalizeTimes(tasks []Task) []Task {
+	out := make([]Task, len(tasks))
+	for i, t := range tasks {
+		for _, p := range []*time.Time{&t.CreatedAt, &t.StartedAt, &t.CompletedAt, &t.ArchivedAt, &t.SkippedAt} {
+			*p = p.UTC()
+		}
+		if t.DueDate != nil {
+			due := t.DueDate.UTC()
+			t.DueDate = &due
+		}
+		out[i] = t
+	}
+	return out
+}
+
+func TestReadTasksFileNewerSchema(t *testing.T) {
+	_, _, err := readTasksFile(filepath.Join("testdata", "queue_future.json"), nil)
+	if !errors.Is(err, ErrNewerSchema) {
+		t.Fatalf("error = %v, want ErrNewerSchema", err)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       queueFile
+		migrated bool
+		wantErr  error
+	}{
+		{"v0", queueFile{Version: 0, Tasks: []Task{{Text: "a"}}}, true, nil},
+		{"empty v0", queueFile{}, true, nil},
+		{"current", queueFile{Version: SchemaVersion, Tasks: []Task{{ID: "1", Text: "a"}}}, false, nil},
+		{"newer", queueFile{Version: SchemaVersion + 1}, false, ErrNewerSchema},
+		{"negative", queueFile{Version: -1}, false, ErrNewerSchema},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := tt.in
+			migrated, err := migrate(&f)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if migrated != tt.migrated || f.Version != SchemaVersion {
+				t.Fatalf("migrated = %v, version %d; want %v, %d", migrated, f.Version, tt.migrated, SchemaVersion)
+			}
+			for _, task := range f.Tasks {
+				if task.ID == "" {
+					t.Fatal("a task has no ID after migrating")
+				}
+			}
+		})
+	}
+}
+
+func TestLoadUpgradesFileOnDisk(t *testing.T) {
+	t.Setenv(EnvPassphrase, "")
+	t.Setenv(EnvBackend, "")
+	dir := t.TempDir()
+	old, err := os.ReadFile(filepath.Join("testdata", "queue_v0_unversioned.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "queue.json"), old, 0644); err != nil {
+		t.Fatal(err)
+	}
+	q, err := NewTaskQueue(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+	if q.Count() != 2 {
+		t.Fatalf("Count = %d, want 2", q.Count())
+	}
+	tasks, migrated, err := readTasksFile(filepath.Join(dir, "queue.json"), nil)
+	if err != nil || migrated || len(tasks) != 2 {
+		t.Fatalf("queue.json after start: %d tasks, migrated %v, %v; want 2 upgraded tasks", len(tasks), migrated, err)
+	}
+	if bak, _ := os.ReadFile(filepath.Join(dir, "queue.json.bak")); string(bak) != string(old) {
+		t.Fatal("the old file was not kept as queue.json.bak")
+	}
+}
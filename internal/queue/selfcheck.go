@@ -0,0 +1,63 @@
+package queue
+
+import (
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// AttachmentReport summarizes the attachment check done when the queue is
+// opened.
+type AttachmentReport struct {
+	Checked   int  // attachments referenced by queued tasks
+	Missing   int  // of those, files that do not exist
+	Recreated bool // attachmentsDir was gone and had to be created again
+}
+
+// AttachmentExists reports whether the attachment's file is still on disk.
+// Anything but a clear "not found" counts as present, so a file that is
+// merely unreadable is not reported as gone.
+func AttachmentExists(a Attachment) bool {
+	if a.Path == "" {
+		return false
+	}
+	_, err := os.Stat(a.Path)
+	return !errors.Is(err, os.ErrNotExist)
+}
+
+// checkAttachmentsLocked logs every queued attachment whose file is missing,
+// e.g. after a sync client removed attachmentsDir while the app was not
+// running. The tasks keep their references: the file may come back with the
+// next sync, and the views mark it as missing meanwhile. Attachments of a
+// type this build does not know are logged too. Caller holds q.mu.
+func (q *TaskQueue) checkAttachmentsLocked(recreated bool) AttachmentReport {
+	var r AttachmentReport
+	for _, t := range q.Tasks {
+		for _, a := range t.Attachments {
+			r.Checked++
+			if !AttachmentExists(a) {
+				r.Missing++
+				log.Printf("[queue] task %s: attachment %s is missing", t.ID, filepath.Base(a.Path))
+			}
+			if !a.Type.Known() {
+				log.Printf("[queue] task %s: attachment %s has unknown type %q", t.ID, filepath.Base(a.Path), a.Type)
+			}
+		}
+	}
+	// A fresh data directory has no attachments yet; only report the
+	// directory when tasks expected files in it.
+	if recreated && r.Checked > 0 {
+		r.Recreated = true
+		log.Printf("[queue] %s was missing and has been recreated", q.attachmentsDir)
+	}
+	return r
+}
+
+// AttachmentReport returns the result of the attachment check done when the
+// queue was opened.
+func (q *TaskQueue) AttachmentReport() AttachmentReport {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.attachReport
+}
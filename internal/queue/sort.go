@@ -0,0 +1,106 @@
+package queue
+
+import (
+	"slices"
+	"strings"
+)
+
+// SortOrder is a display order for a list of tasks. It never changes the
+// order of the queue itself.
+type SortOrder string
+
+const (
+	SortFIFO     SortOrder = "fifo"     // queue order
+	SortNewest   SortOrder = "newest"   // most recently created first
+	SortPriority SortOrder = "priority" // most urgent first, then queue order
+	SortAlpha    SortOrder = "alpha"    // by text, ignoring case
+	SortDue      SortOrder = "due"      // earliest due date first, undated last
+)
+
+// SortOrders lists the orders with their labels, in menu order.
+var SortOrders = []struct {
+	Order SortOrder
+	Label string
+}{
+	{SortFIFO, "Queue order"},
+	{SortNewest, "Newest first"},
+	{SortPriority, "Priority"},
+	{SortAlpha, "Alphabetical"},
+	{SortDue, "Due date"},
+}
+
+// ParseSortOrder returns the order named s, or SortFIFO for anything else.
+func ParseSortOrder(s string) SortOrder {
+	for _, o := range SortOrders {
+		if string(o.Order) == s {
+			return o.Order
+		}
+	}
+	return SortFIFO
+}
+
+// Sorted returns a copy of tasks in the given order; tasks itself is left
+// untouched. The sort is stable, so ties keep their queue order.
+func Sorted(tasks []Task, order SortOrder) []Task {
+	out := slices.Clone(tasks)
+	switch order {
+	case SortNewest:
+		slices.SortStableFunc(out, func(a, b Task) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	case SortPriority:
+		slices.SortStableFunc(out, func(a, b Task) int { return b.Priority - a.Priority })
+	case SortAlpha:
+		slices.SortStableFunc(out, func(a, b Task) int {
+			return strings.Compare(strings.ToLower(strings.TrimSpace(a.Text)), strings.ToLower(strings.TrimSpace(b.Text)))
+		})
+	case SortDue:
+		slices.SortStableFunc(out, compareDue)
+	}
+	return out
+}
+
+// compareDue orders tasks by due date, earliest (including overdue) first.
+// Tasks without a due date come after all dated ones and compare equal to
+// each other, so a stable sort keeps them in queue order.
+func compareDue(a, b Task) int {
+	switch {
+	case a.DueDate == nil && b.DueDate == nil:
+		return 0
+	case a.DueDate == nil:
+		return 1
+	case b.DueDate == nil:
+		return -1
+	}
+	return a.DueDate.Compare(*b.DueDate)
+}
+
+// CompareDueFirst orders the candidates for the current task under
+// SetDueFirst: tasks never skipped by due date as compareDue does, then the
+// skipped ones, the one skipped longest ago first, so skipping goes round
+// all of them; skipped tasks skipped at the same time go by due date.
+func CompareDueFirst(a, b Task) int {
+	switch {
+	case a.SkippedAt.IsZero() != b.SkippedAt.IsZero():
+		if a.SkippedAt.IsZero() {
+			return -1
+		}
+		return 1
+	case !a.SkippedAt.Equal(b.SkippedAt):
+		return a.SkippedAt.Compare(b.SkippedAt)
+	}
+	return compareDue(a, b)
+}
+
+// Paginate returns the tasks on page (1-based) when tasks is split into
+// pages of perPage, together with that page number and the page count. A
+// page out of range is clamped to the first or last one; an empty list is a
+// single empty page.
+func Paginate(tasks []Task, page, perPage int) (items []Task, cur, pages int) {
+	if perPage <= 0 {
+		return tasks, 1, 1
+	}
+	pages = max((len(tasks)+perPage-1)/perPage, 1)
+	cur = min(max(page, 1), pages)
+	start := (cur - 1) * perPage
+	end := min(start+perPage, len(tasks))
+	return tasks[start:end], cur, pages
+}
@@ -0,0 +1,129 @@
+package queue
+
+import (
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+
+func at(d time.Duration) *time.Time {
+	t := testNow.Add(d)
+	return &t
+}
+
+func TestCompareDue(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b *time.Time
+		want int
+	}{
+		{"both undated", nil, nil, 0},
+		{"undated after dated", nil, at(time.Hour), 1},
+		{"dated before undated", at(time.Hour), nil, -1},
+		{"overdue before undated", at(-48 * time.Hour), nil, -1},
+		{"earlier first", at(time.Hour), at(2 * time.Hour), -1},
+		{"later second", at(2 * time.Hour), at(time.Hour), 1},
+		{"overdue before upcoming", at(-time.Hour), at(time.Hour), -1},
+		{"same due", at(time.Hour), at(time.Hour), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := compareDue(Task{DueDate: tt.a}, Task{DueDate: tt.b})
+			if got != tt.want {
+				t.Fatalf("compareDue = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareDueFirst(t *testing.T) {
+	skipped := func(d time.Duration) time.Time { return testNow.Add(d) }
+	tests := []struct {
+		name string
+		a, b Task
+		want int
+	}{
+		{"unskipped by due", Task{DueDate: at(time.Hour)}, Task{DueDate: at(2 * time.Hour)}, -1},
+		{"skipped after unskipped", Task{DueDate: at(-time.Hour), SkippedAt: skipped(0)}, Task{DueDate: at(time.Hour)}, 1},
+		{"unskipped undated before skipped", Task{}, Task{DueDate: at(time.Hour), SkippedAt: skipped(0)}, -1},
+		{"longest skipped first", Task{DueDate: at(2 * time.Hour), SkippedAt: skipped(-time.Minute)}, Task{DueDate: at(time.Hour), SkippedAt: skipped(0)}, -1},
+		{"skipped together by due", Task{DueDate: at(2 * time.Hour), SkippedAt: skipped(0)}, Task{DueDate: at(time.Hour), SkippedAt: skipped(0)}, 1},
+		{"both undated", Task{}, Task{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CompareDueFirst(tt.a, tt.b); got != tt.want {
+				t.Fatalf("CompareDueFirst = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortedDueKeepsUndatedInQueueOrder(t *testing.T) {
+	tasks := []Task{
+		{ID: "undated-1"},
+		{ID: "late", DueDate: at(3 * time.Hour)},
+		{ID: "undated-2"},
+		{ID: "overdue", DueDate: at(-time.Hour)},
+		{ID: "undated-3"},
+		{ID: "soon", DueDate: at(time.Hour)},
+	}
+	want := []string{"overdue", "soon", "late", "undated-1", "undated-2", "undated-3"}
+	got := Sorted(tasks, SortDue)
+	for i, id := range want {
+		if got[i].ID != id {
+			t.Fatalf("position %d = %s, want %s (got %v)", i, got[i].ID, id, ids(got))
+		}
+	}
+	if tasks[0].ID != "undated-1" {
+		t.Fatal("Sorted changed its argument")
+	}
+}
+
+func ids(tasks []Task) []string {
+	out := make([]string, len(tasks))
+	for i, t := range tasks {
+		out[i] = t.ID
+	}
+	return out
+}
+
+func TestPaginate(t *testing.T) {
+	tasks := make([]Task, 7)
+	for i := range tasks {
+		tasks[i].ID = string(rune('a' + i))
+	}
+	tests := []struct {
+		name      string
+		tasks     []Task
+		page, per int
+		want      string
+		cur, n    int
+	}{
+		{"first page", tasks, 1, 3, "abc", 1, 3},
+		{"middle page", tasks, 2, 3, "def", 2, 3},
+		{"partial last page", tasks, 3, 3, "g", 3, 3},
+		{"exact fit", tasks[:6], 2, 3, "def", 2, 2},
+		{"page past the end", tasks, 9, 3, "g", 3, 3},
+		{"page zero", tasks, 0, 3, "abc", 1, 3},
+		{"negative page", tasks, -2, 3, "abc", 1, 3},
+		{"one page", tasks, 1, 10, "abcdefg", 1, 1},
+		{"empty list", nil, 1, 3, "", 1, 1},
+		{"empty list past the end", nil, 4, 3, "", 1, 1},
+		{"no page size", tasks, 2, 0, "abcdefg", 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, cur, pages := Paginate(tt.tasks, tt.page, tt.per)
+			got := ""
+			for _, id := range ids(items) {
+				got += id
+			}
+			if got != tt.want || cur != tt.cur || pages != tt.n {
+				t.Fatalf("Paginate(page %d, %d per page) = %q, page %d of %d; want %q, page %d of %d",
+					tt.page, tt.per, got, cur, pages, tt.want, tt.cur, tt.n)
+			}
+		})
+	}
+}
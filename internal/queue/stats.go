@@ -0,0 +1,64 @@
+package queue
+
+import "time"
+
+// DayCount is the number of tasks completed on one local calendar day.
+type DayCount struct {
+	Day   time.Time // local midnight
+	Count int
+}
+
+// CompletionStats aggregates history by completion day.
+type CompletionStats struct {
+	Today int
+	Week  int // since Monday
+	Month int // since the 1st
+	Total int
+	// Days covers the last N days ending today, oldest first, including
+	// days without completions.
+	Days []DayCount
+}
+
+// Stats counts completed tasks per day for the last days days relative to
+// now. Entries without CompletedAt (written by older versions) are counted
+// on their CreatedAt day, like the history page does.
+func (h *TaskHistory) Stats(now time.Time, days int) CompletionStats {
+	now = now.Local()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	week := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	first := today.AddDate(0, 0, -(days - 1))
+
+	var s CompletionStats
+	s.Days = make([]DayCount, days)
+	index := make(map[string]int, days)
+	for i := range s.Days {
+		s.Days[i].Day = first.AddDate(0, 0, i)
+		index[s.Days[i].Day.Format("2006-01-02")] = i
+	}
+
+	for _, e := range h.GetAll() {
+		ref := e.CompletedAt
+		if ref.IsZero() {
+			ref = e.CreatedAt
+		}
+		ref = ref.Local()
+		day := time.Date(ref.Year(), ref.Month(), ref.Day(), 0, 0, 0, 0, time.Local)
+		s.Total++
+		if day.Equal(today) {
+			s.Today++
+		}
+		if !day.Before(week) && !day.After(today) {
+			s.Week++
+		}
+		if !day.Before(month) && !day.After(today) {
+			s.Month++
+		}
+		// Keyed by calendar date rather than hours since first, so DST
+		// transitions do not shift entries into the wrong bucket.
+		if i, ok := index[day.Format("2006-01-02")]; ok {
+			s.Days[i].Count++
+		}
+	}
+	return s
+}
@@ -0,0 +1,42 @@
+package queue
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnvBackend selects where the queue is stored: "json" (the default) keeps it
+// in queue.json, "sqlite" in queue.db.
+const EnvBackend = "QUEUE_BACKEND"
+
+// Store persists the queue. TaskQueue keeps the tasks in memory, guards them
+// with its mutex and the inter-process lock, and passes the whole list to
+// Save after every change; a store may write only what differs. The methods
+// take snapshots rather than mirroring each queue operation: a single
+// operation can touch many tasks (reorders, respawns, imports), and diffing
+// the snapshot keeps one code path for all of them.
+type Store interface {
+	// Load returns the stored tasks in queue order. upgraded reports data in
+	// an older format, which the caller saves again.
+	Load() (tasks []Task, upgraded bool, err error)
+	// Save replaces the stored queue with tasks.
+	Save(tasks []Task) error
+	// Version identifies the stored data. It changes whenever another process
+	// writes and must be cheap, as it is polled for external changes.
+	Version() string
+	Close() error
+}
+
+// openStore returns the store selected by EnvBackend.
+func openStore(baseDir string, box *cipherBox) (Store, error) {
+	switch backend := strings.ToLower(strings.TrimSpace(os.Getenv(EnvBackend))); backend {
+	case "", "json":
+		return &jsonStore{path: filepath.Join(baseDir, "queue.json"), box: box}, nil
+	case "sqlite":
+		return openSQLiteStore(baseDir, box)
+	default:
+		return nil, fmt.Errorf("%s: unknown backend %q (want json or sqlite)", EnvBackend, backend)
+	}
+}
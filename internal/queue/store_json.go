@@ -0,0 +1,142 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// jsonStore keeps the queue in a single JSON file, rewritten on every save.
+// The previous version is kept next to it as a backup and used when the file
+// no longer parses, and the last keep versions as rotated copies (see
+// rotate). A file that does not parse is never written over: Save moves it
+// aside first (see corruptPath).
+type jsonStore struct {
+	path string
+	box  *cipherBox
+	keep int
+}
+
+func (s *jsonStore) backupPath() string {
+	return s.path + ".bak"
+}
+
+// corruptPath is where Save keeps a queue file that no longer parses, so a
+// damaged or badly hand-edited file can still be repaired.
+func (s *jsonStore) corruptPath(now time.Time) string {
+	return s.path + ".corrupt-" + now.Format("20060102-150405.000")
+}
+
+// Load reads the queue. When the file does not parse, the backup is used if
+// it exists and parses; otherwise the ErrCorrupt error is returned, so the
+// caller does not go on with an empty queue.
+func (s *jsonStore) Load() ([]Task, bool, error) {
+	tasks, migrated, err := readTasksFile(s.path, s.box)
+	if errors.Is(err, ErrCorrupt) {
+		if _, statErr := os.Stat(s.backupPath()); statErr != nil {
+			return nil, false, err
+		}
+		bak, bakMigrated, bakErr := readTasksFile(s.backupPath(), s.box)
+		if bakErr != nil {
+			return nil, false, err
+		}
+		log.Printf("[queue] %v; restored %d tasks from %s", err, len(bak), s.backupPath())
+		return bak, bakMigrated, nil
+	}
+	if migrated {
+		// The old file is kept as the backup by the next Save.
+		log.Printf("[queue] upgrading %s to schema version %d", filepath.Base(s.path), SchemaVersion)
+	}
+	return tasks, migrated, err
+}
+
+func (s *jsonStore) Save(tasks []Task) error {
+	data, err := json.MarshalIndent(queueFile{Version: SchemaVersion, Tasks: tasks}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if data, err = s.box.seal(data); err != nil {
+		return err
+	}
+	// Keep the previous version as a backup, but never overwrite a good
+	// backup with a file that no longer parses; keep such a file aside.
+	var prev []byte
+	var savedAt time.Time
+	if fi, err := os.Stat(s.path); err == nil {
+		savedAt = fi.ModTime()
+		b, err := os.ReadFile(s.path)
+		switch {
+		case err != nil:
+			return err
+		case validTasks(b, s.box):
+			prev = b
+			_ = atomicWriteFile(s.backupPath(), prev, 0644)
+		default:
+			aside := s.corruptPath(time.Now())
+			if err := atomicWriteFile(aside, b, 0644); err != nil {
+				return fmt.Errorf("keep unreadable %s: %w", filepath.Base(s.path), err)
+			}
+			log.Printf("[queue] %s did not parse; kept it as %s", filepath.Base(s.path), aside)
+		}
+	}
+	if err := atomicWriteFile(s.path, data, 0644); err != nil {
+		return err
+	}
+	if prev != nil {
+		s.rotate(prev, savedAt)
+	}
+	return nil
+}
+
+// Version is the file's modification time and size.
+func (s *jsonStore) Version() string {
+	fi, err := os.Stat(s.path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", fi.ModTime().UnixNano(), fi.Size())
+}
+
+func (s *jsonStore) Close() error {
+	return nil
+}
+
+// readTasksFile reads a queue file. A missing file yields an empty queue;
+// unparseable content yields an error wrapping ErrCorrupt.
+func readTasksFile(path string, box *cipherBox) ([]Task, bool, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return parseTasks(filepath.Base(path), b, box)
+}
+
+// parseTasks decodes a queue file and upgrades it to SchemaVersion, reporting
+// whether it was written in an older format.
+func parseTasks(name string, b []byte, box *cipherBox) ([]Task, bool, error) {
+	b, err := box.open(b)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", name, err)
+	}
+	var f queueFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, false, fmt.Errorf("%w: %s: %v", ErrCorrupt, name, err)
+	}
+	migrated, err := migrate(&f)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", name, err)
+	}
+	return f.Tasks, migrated, nil
+}
+
+func validTasks(b []byte, box *cipherBox) bool {
+	_, _, err := parseTasks("", b, box)
+	return err == nil
+}
@@ -0,0 +1,96 @@
+package queue
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestStore(t *testing.T) *jsonStore {
+	t.Helper()
+	return &jsonStore{path: filepath.Join(t.TempDir(), "queue.json")}
+}
+
+// truncate cuts the queue file in half, as a crash mid-write on a disk
+// without atomic renames would.
+func truncate(t *testing.T, path string) {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, b[:len(b)/2], 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadTruncatedUsesBackup(t *testing.T) {
+	s := newTestStore(t)
+	if err := s.Save([]Task{{ID: "1", Text: "first"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save([]Task{{ID: "1", Text: "first"}, {ID: "2", Text: "second"}}); err != nil {
+		t.Fatal(err)
+	}
+	truncate(t, s.path)
+
+	tasks, _, err := s.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != "1" {
+		t.Fatalf("Load = %+v, want the backup with task 1", tasks)
+	}
+}
+
+func TestLoadTruncatedWithoutBackup(t *testing.T) {
+	s := newTestStore(t)
+	if err := s.Save([]Task{{ID: "1", Text: "only"}}); err != nil {
+		t.Fatal(err)
+	}
+	truncate(t, s.path)
+
+	tasks, _, err := s.Load()
+	if !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("Load = %v, %v; want ErrCorrupt", tasks, err)
+	}
+}
+
+func TestLoadTruncatedWithBadBackup(t *testing.T) {
+	s := newTestStore(t)
+	if err := os.WriteFile(s.path, []byte(`{"version":`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(s.backupPath(), []byte(`[{"id":`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Load(); !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("Load error = %v, want ErrCorrupt", err)
+	}
+}
+
+func TestSaveKeepsUnparseableFile(t *testing.T) {
+	s := newTestStore(t)
+	damaged := []byte(`{"version": 2, "tasks": [{"id": "1", "text": "half an edit`)
+	if err := os.WriteFile(s.path, damaged, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save([]Task{{ID: "2", Text: "new"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	aside, _ := filepath.Glob(s.path + ".corrupt-*")
+	if len(aside) != 1 {
+		t.Fatalf("corrupt copies = %v, want one", aside)
+	}
+	if b, _ := os.ReadFile(aside[0]); string(b) != string(damaged) {
+		t.Fatalf("kept %q, want the damaged file", b)
+	}
+	if _, err := os.Stat(s.backupPath()); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("backup written from a damaged file: %v", err)
+	}
+	if tasks, _, err := s.Load(); err != nil || len(tasks) != 1 || tasks[0].ID != "2" {
+		t.Fatalf("Load = %+v, %v; want task 2", tasks, err)
+	}
+}
@@ -0,0 +1,235 @@
+package queue
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteSchema stores one row per task. data holds the task as JSON (sealed
+// when a passphrase is set); position is its place in the queue, and
+// priority/created_at mirror the task so the database can be queried directly.
+// The app itself only reads whole queues and writes rows by id, so there is no
+// index besides the primary key; older databases drop the ones they had.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS tasks (
+	id         TEXT PRIMARY KEY,
+	position   INTEGER NOT NULL,
+	priority   INTEGER NOT NULL DEFAULT 0,
+	created_at INTEGER NOT NULL,
+	data       BLOB NOT NULL
+);
+DROP INDEX IF EXISTS tasks_position;
+DROP INDEX IF EXISTS tasks_priority_created;
+`
+
+// sqliteStore keeps the queue in queue.db. Each Save runs in one transaction
+// and only writes the rows that changed since the last Load or Save.
+type sqliteStore struct {
+	db  *sql.DB
+	box *cipherBox
+	// schema is the user_version of the database, i.e. its SchemaVersion.
+	schema int
+	// saved mirrors the stored rows as of the last Load or Save. It is only
+	// trusted while dataVersion is unchanged, i.e. no other process wrote.
+	saved       map[string]sqliteRow
+	dataVersion string
+}
+
+type sqliteRow struct {
+	position int
+	data     string // task JSON before sealing
+}
+
+// openSQLiteStore opens baseDir/queue.db, creating it if needed. A new
+// database starts with the tasks of an existing queue.json, which is left in
+// place.
+func openSQLiteStore(baseDir string, box *cipherBox) (*sqliteStore, error) {
+	path := filepath.Join(baseDir, "queue.db")
+	_, statErr := os.Stat(path)
+	fresh := errors.Is(statErr, os.ErrNotExist)
+
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+	// One connection: PRAGMA data_version only reports writes by other
+	// connections, so it must always be asked on the one we write with.
+	db.SetMaxOpenConns(1)
+	s := &sqliteStore{db: db, box: box}
+	if err := s.init(fresh); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("queue.db: %w", err)
+	}
+	if fresh {
+		if err := s.importJSON(filepath.Join(baseDir, "queue.json")); err != nil {
+			_ = db.Close()
+			_ = os.Remove(path)
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *sqliteStore) init(fresh bool) error {
+	if _, err := s.db.Exec(sqliteSchema); err != nil {
+		return err
+	}
+	if err := s.db.QueryRow(`PRAGMA user_version`).Scan(&s.schema); err != nil {
+		return err
+	}
+	if fresh || s.schema == 0 {
+		if _, err := s.db.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, SchemaVersion)); err != nil {
+			return err
+		}
+		s.schema = SchemaVersion
+	}
+	if s.schema > SchemaVersion {
+		return fmt.Errorf("%w (version %d, supported %d)", ErrNewerSchema, s.schema, SchemaVersion)
+	}
+	return nil
+}
+
+func (s *sqliteStore) importJSON(path string) error {
+	tasks, _, err := readTasksFile(path, s.box)
+	if err != nil || len(tasks) == 0 {
+		return err
+	}
+	if err := s.Save(tasks); err != nil {
+		return err
+	}
+	log.Printf("[queue] imported %d tasks from %s into queue.db", len(tasks), filepath.Base(path))
+	return nil
+}
+
+func (s *sqliteStore) Load() ([]Task, bool, error) {
+	s.saved = nil
+	version := s.Version()
+	rows, err := s.db.Query(`SELECT id, position, data FROM tasks ORDER BY position`)
+	if err != nil {
+		return nil, false, fmt.Errorf("queue.db: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []Task
+	saved := map[string]sqliteRow{}
+	for rows.Next() {
+		var (
+			id   string
+			row  sqliteRow
+			data []byte
+		)
+		if err := rows.Scan(&id, &row.position, &data); err != nil {
+			return nil, false, fmt.Errorf("queue.db: %w", err)
+		}
+		plain, err := s.box.open(data)
+		if err != nil {
+			return nil, false, fmt.Errorf("queue.db: %w", err)
+		}
+		var t Task
+		if err := json.Unmarshal(plain, &t); err != nil {
+			return nil, false, fmt.Errorf("%w: queue.db: task %s: %v", ErrCorrupt, id, err)
+		}
+		row.data = string(plain)
+		saved[id] = row
+		tasks = append(tasks, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("queue.db: %w", err)
+	}
+
+	f := queueFile{Version: s.schema, Tasks: tasks}
+	migrated, err := migrate(&f)
+	if err != nil {
+		return nil, false, fmt.Errorf("queue.db: %w", err)
+	}
+	s.saved, s.dataVersion = saved, version
+	return f.Tasks, migrated, nil
+}
+
+func (s *sqliteStore) Save(tasks []Task) error {
+	prev := s.saved
+	// Another process wrote since we last looked: what we remember about
+	// the rows is stale, so rewrite all of them.
+	if prev == nil || s.Version() != s.dataVersion {
+		prev = nil
+	}
+	s.saved = nil
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("queue.db: %w", err)
+	}
+	defer tx.Rollback()
+
+	if prev == nil {
+		if _, err := tx.Exec(`DELETE FROM tasks`); err != nil {
+			return fmt.Errorf("queue.db: %w", err)
+		}
+	}
+	next := make(map[string]sqliteRow, len(tasks))
+	for i, t := range tasks {
+		if _, dup := next[t.ID]; dup {
+			return fmt.Errorf("queue.db: duplicate task id %s", t.ID)
+		}
+		b, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+		row := sqliteRow{position: i, data: string(b)}
+		next[t.ID] = row
+		if old, ok := prev[t.ID]; ok && old == row {
+			continue
+		}
+		sealed, err := s.box.seal(b)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`INSERT INTO tasks (id, position, priority, created_at, data) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(id) DO UPDATE SET position = excluded.position, priority = excluded.priority,
+				created_at = excluded.created_at, data = excluded.data`,
+			t.ID, i, t.Priority, t.CreatedAt.UnixNano(), sealed); err != nil {
+			return fmt.Errorf("queue.db: %w", err)
+		}
+	}
+	for id := range prev {
+		if _, ok := next[id]; ok {
+			continue
+		}
+		if _, err := tx.Exec(`DELETE FROM tasks WHERE id = ?`, id); err != nil {
+			return fmt.Errorf("queue.db: %w", err)
+		}
+	}
+	if s.schema != SchemaVersion {
+		if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, SchemaVersion)); err != nil {
+			return fmt.Errorf("queue.db: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("queue.db: %w", err)
+	}
+	s.schema = SchemaVersion
+	s.saved, s.dataVersion = next, s.Version()
+	return nil
+}
+
+// Version is SQLite's data_version, which changes when another connection
+// commits. Our own writes leave it alone.
+func (s *sqliteStore) Version() string {
+	var v int64
+	if err := s.db.QueryRow(`PRAGMA data_version`).Scan(&v); err != nil {
+		return ""
+	}
+	return strconv.FormatInt(v, 10)
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,161 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Template is a task to enqueue again and again, such as a weekly chore:
+// the text, tags, priority and recurrence a fresh task starts with.
+// Templates carry no attachments.
+type Template struct {
+	Name       string   `json:"name"`
+	Text       string   `json:"text"`
+	Tags       []string `json:"tags,omitempty"`
+	Priority   int      `json:"priority,omitempty"`
+	Recurrence string   `json:"recurrence,omitempty"`
+}
+
+// TemplateFromTask makes a template named name from what t would start
+// over with. An empty name is taken from the first line of the text.
+func TemplateFromTask(name string, t Task) Template {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name, _, _ = strings.Cut(strings.TrimSpace(t.Text), "\n")
+	}
+	return Template{
+		Name:       name,
+		Text:       t.Text,
+		Tags:       append([]string(nil), t.Tags...),
+		Priority:   t.Priority,
+		Recurrence: t.Recurrence,
+	}
+}
+
+// NewTask returns a fresh task made from the template, created at now.
+func (tp Template) NewTask(now time.Time) Task {
+	return Task{
+		ID:         strconv.FormatInt(now.UnixNano(), 10),
+		Text:       tp.Text,
+		CreatedAt:  now,
+		Priority:   tp.Priority,
+		Tags:       append([]string(nil), tp.Tags...),
+		Recurrence: tp.Recurrence,
+	}
+}
+
+// Templates is the list of task templates kept in templates.json next to
+// queue.json, in the order they were saved.
+type Templates struct {
+	mu       sync.Mutex
+	Entries  []Template `json:"templates"`
+	filePath string
+	box      *cipherBox
+}
+
+func newTemplates(baseDir string, box *cipherBox) (*Templates, error) {
+	s := &Templates{filePath: filepath.Join(baseDir, "templates.json"), box: box}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Templates) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := os.ReadFile(s.filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			s.Entries = nil
+			return nil
+		}
+		return err
+	}
+	if b, err = s.box.open(b); err != nil {
+		return fmt.Errorf("templates.json: %w", err)
+	}
+	var tmp struct {
+		Entries []Template `json:"templates"`
+	}
+	if err := json.Unmarshal(b, &tmp); err != nil {
+		return fmt.Errorf("templates.json: %w", err)
+	}
+	s.Entries = tmp.Entries
+	return nil
+}
+
+func (s *Templates) saveLocked() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if data, err = s.box.seal(data); err != nil {
+		return err
+	}
+	return atomicWriteFile(s.filePath, data, 0644)
+}
+
+func (s *Templates) GetAll() []Template {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := make([]Template, len(s.Entries))
+	copy(res, s.Entries)
+	return res
+}
+
+// Save adds tp, or replaces the template with the same name (compared
+// case-insensitively). replaced reports which one happened.
+func (s *Templates) Save(tp Template) (replaced bool, err error) {
+	tp.Name = strings.TrimSpace(tp.Name)
+	if tp.Name == "" || strings.TrimSpace(tp.Text) == "" {
+		return false, errors.New("a template needs a name and text")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old := s.Entries
+	s.Entries = append([]Template(nil), old...)
+	if i := s.indexLocked(tp.Name); i >= 0 {
+		s.Entries[i], replaced = tp, true
+	} else {
+		s.Entries = append(s.Entries, tp)
+	}
+	if err := s.saveLocked(); err != nil {
+		s.Entries = old
+		return false, err
+	}
+	return replaced, nil
+}
+
+// Delete removes the template with name.
+func (s *Templates) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.indexLocked(name)
+	if i < 0 {
+		return fmt.Errorf("template not found: %s", name)
+	}
+	old := s.Entries
+	s.Entries = append(append([]Template(nil), old[:i]...), old[i+1:]...)
+	if err := s.saveLocked(); err != nil {
+		s.Entries = old
+		return err
+	}
+	return nil
+}
+
+func (s *Templates) indexLocked(name string) int {
+	for i, tp := range s.Entries {
+		if strings.EqualFold(tp.Name, strings.TrimSpace(name)) {
+			return i
+		}
+	}
+	return -1
+}
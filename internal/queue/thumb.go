@@ -0,0 +1,96 @@
+package queue
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ThumbSize is the longest side, in pixels, of an image attachment thumbnail.
+const ThumbSize = 200
+
+// maxThumbPixels guards against decoding huge images just to shrink them.
+const maxThumbPixels = 50_000_000
+
+// ThumbPath returns where the thumbnail of the attachment at path is kept:
+// next to it, with a _thumb suffix, always as JPEG.
+func ThumbPath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + "_thumb.jpg"
+}
+
+// writeThumbLocked stores a downscaled JPEG copy of an image attachment at
+// ThumbPath, encrypted like the original. Caller holds q.mu.
+func (q *TaskQueue) writeThumbLocked(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if data, err = q.box.open(data); err != nil {
+		return err
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if cfg.Width*cfg.Height > maxThumbPixels {
+		return fmt.Errorf("image too large (%dx%d)", cfg.Width, cfg.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, downscale(img, ThumbSize), &jpeg.Options{Quality: 80}); err != nil {
+		return err
+	}
+	out, err := q.box.seal(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	return atomicWriteFile(ThumbPath(path), out, 0644)
+}
+
+// downscale fits img into a size×size box by averaging the source pixels
+// that fall on each target pixel, over a white background since JPEG has no
+// alpha. Smaller images keep their size.
+func downscale(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > size || h > size {
+		if w >= h {
+			w, h = size, max(h*size/b.Dx(), 1)
+		} else {
+			w, h = max(w*size/b.Dy(), 1), size
+		}
+	}
+	src := image.NewRGBA(b)
+	draw.Draw(src, b, image.White, image.Point{}, draw.Src)
+	draw.Draw(src, b, img, b.Min, draw.Over)
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0 := b.Min.Y + y*b.Dy()/h
+		y1 := max(b.Min.Y+(y+1)*b.Dy()/h, y0+1)
+		for x := 0; x < w; x++ {
+			x0 := b.Min.X + x*b.Dx()/w
+			x1 := max(b.Min.X+(x+1)*b.Dx()/w, x0+1)
+			var r, g, bl, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := src.RGBAAt(sx, sy)
+					r, g, bl, n = r+uint32(c.R), g+uint32(c.G), bl+uint32(c.B), n+1
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{uint8(r / n), uint8(g / n), uint8(bl / n), 0xff})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,99 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Problem is something wrong with one task of a queue file, as found by
+// ValidateFile.
+type Problem struct {
+	Index  int // position in the file, from 0
+	TaskID string
+	Msg    string
+}
+
+func (p Problem) String() string {
+	if p.TaskID == "" {
+		return fmt.Sprintf("task %d: %s", p.Index+1, p.Msg)
+	}
+	return fmt.Sprintf("task %d (id %s): %s", p.Index+1, p.TaskID, p.Msg)
+}
+
+// ValidateFile reads a queue file the way the app loads queue.json,
+// including decryption and upgrading older formats, without changing it,
+// and checks every task: a non-empty and unique ID, non-empty text, and
+// attachments whose file exists and whose extension fits their type. It
+// returns the number of tasks read and the problems found; err is set only
+// when the file cannot be read at all. The salt for an encrypted file is
+// looked up next to it.
+func ValidateFile(path string) (n int, problems []Problem, err error) {
+	if _, err := os.Stat(path); err != nil {
+		return 0, nil, err
+	}
+	box, err := cipherFromEnv(filepath.Dir(path))
+	if err != nil {
+		return 0, nil, err
+	}
+	tasks, _, err := readTasksFile(path, box)
+	if err != nil {
+		return 0, nil, err
+	}
+	return len(tasks), validateTasks(tasks), nil
+}
+
+func validateTasks(tasks []Task) []Problem {
+	var problems []Problem
+	seen := make(map[string]int, len(tasks))
+	for i, t := range tasks {
+		add := func(format string, args ...any) {
+			problems = append(problems, Problem{Index: i, TaskID: t.ID, Msg: fmt.Sprintf(format, args...)})
+		}
+		switch first, dup := seen[t.ID]; {
+		case strings.TrimSpace(t.ID) == "":
+			add("empty id")
+		case dup:
+			add("same id as task %d", first+1)
+		default:
+			seen[t.ID] = i
+		}
+		if strings.TrimSpace(t.Text) == "" {
+			add("empty text")
+		}
+		for _, a := range t.Attachments {
+			name := filepath.Base(a.Path)
+			if a.Path == "" {
+				add("attachment with no path")
+				continue
+			}
+			if _, err := os.Stat(a.Path); errors.Is(err, os.ErrNotExist) {
+				add("attachment %s is missing", name)
+			} else if err != nil {
+				add("attachment %s: %v", name, err)
+			}
+			if msg := attachmentTypeProblem(a); msg != "" {
+				add("attachment %s: %s", name, msg)
+			}
+		}
+	}
+	return problems
+}
+
+// attachmentTypeProblem describes how a's declared type disagrees with its
+// file extension, or returns "".
+func attachmentTypeProblem(a Attachment) string {
+	ext := strings.ToLower(filepath.Ext(a.Path))
+	byExt, builtin := builtinTypeForExt(ext)
+	switch {
+	case !a.Type.Known():
+		return fmt.Sprintf("unknown type %q", a.Type)
+	case a.Type == AttachmentFile && builtin:
+		return fmt.Sprintf("type %s, but %s is a %s extension", a.Type, ext, byExt)
+	case a.Type != AttachmentFile && byExt != a.Type:
+		return fmt.Sprintf("type %s, but %q is not one of %s", a.Type, ext, strings.Join(builtinExts[a.Type], " "))
+	}
+	return ""
+}
@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/ncruces/zenity"
+)
+
+// DefaultDialogTimeout is used until SetDialogTimeout is called.
+const DefaultDialogTimeout = 5 * time.Minute
+
+var dialogTimeout atomic.Int64
+
+// SetDialogTimeout sets how long a native dialog may stay open before it is
+// closed as if cancelled. Without a limit, a dialog that never appears (e.g.
+// no display server) would block the tray's event loop for good.
+func SetDialogTimeout(d time.Duration) {
+	dialogTimeout.Store(int64(d))
+}
+
+// dialogOptions appends a context that closes the dialog after the timeout.
+// Call done once the dialog has returned.
+func dialogOptions(opts ...zenity.Option) (_ []zenity.Option, done func()) {
+	d := time.Duration(dialogTimeout.Load())
+	if d <= 0 {
+		d = DefaultDialogTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	return append(opts, zenity.Context(ctx)), cancel
+}
+
+// canceled reports whether a dialog was dismissed or closed by the timeout;
+// both are handled like Cancel. Timeouts are logged.
+func canceled(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		log.Printf("[ui] dialog closed after %v without an answer", time.Duration(dialogTimeout.Load()))
+		return true
+	}
+	return errors.Is(err, zenity.ErrCanceled)
+}
@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"bytes"
+	"embed"
+	"encoding/binary"
+	"image/png"
+	"runtime"
+)
+
+//go:embed icons/*.png
+var iconFS embed.FS
+
+// TrayIcon is the state shown by the tray icon.
+type TrayIcon int
+
+const (
+	IconIdle    TrayIcon = iota // queue is empty
+	IconPending                 // tasks are waiting
+	IconOverdue                 // at least one task is past its due date
+	IconDone                    // the last task was just completed or deleted
+)
+
+// TrayIconData returns the icon for a state, as ICO on Windows and PNG
+// elsewhere. template is a monochrome variant for the macOS menu bar, which
+// adapts it to light and dark mode; it is nil for the overdue state, whose
+// red dot must keep its color.
+func TrayIconData(state TrayIcon) (icon, template []byte) {
+	name := "idle"
+	switch state {
+	case IconPending:
+		name = "pending"
+	case IconOverdue:
+		name = "overdue"
+	case IconDone:
+		name = "done"
+	}
+	icon, _ = iconFS.ReadFile("icons/" + name + ".png")
+	if state != IconOverdue {
+		template, _ = iconFS.ReadFile("icons/" + name + "_template.png")
+	}
+	if runtime.GOOS == "windows" {
+		icon = pngToICO(icon)
+	}
+	return icon, template
+}
+
+// pngToICO wraps a PNG in a single-image ICO container, which Windows
+// accepts for tray icons.
+func pngToICO(data []byte) []byte {
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return data
+	}
+	size := func(n int) byte {
+		if n >= 256 {
+			return 0 // 0 means 256
+		}
+		return byte(n)
+	}
+	var buf bytes.Buffer
+	// ICONDIR: reserved, type 1 (icon), one image.
+	_ = binary.Write(&buf, binary.LittleEndian, [3]uint16{0, 1, 1})
+	// ICONDIRENTRY: width, height, colors, reserved, planes, bpp, size, offset.
+	buf.Write([]byte{size(cfg.Width), size(cfg.Height), 0, 0})
+	_ = binary.Write(&buf, binary.LittleEndian, [2]uint16{1, 32})
+	_ = binary.Write(&buf, binary.LittleEndian, [2]uint32{uint32(len(data)), 6 + 16})
+	buf.Write(data)
+	return buf.Bytes()
+}
@@ -3,14 +3,13 @@ package ui
 import (
 	"bytes"
 	"errors"
-	"image"
-	"image/color"
-	"image/draw"
-	"image/png"
+	"fmt"
+	"log"
 	"net/url"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/microcosm-cc/bluemonday"
 	"github.com/ncruces/zenity"
@@ -18,43 +17,32 @@ import (
 	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/renderer/html"
 
+	"github.com/Ameight/systray-queue-app/internal/i18n"
 	"github.com/Ameight/systray-queue-app/internal/queue"
 )
 
-// MakeTemplateIcon returns a minimal monochrome 16×16 PNG icon (suitable as macOS template icon).
-func MakeTemplateIcon() []byte {
-	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
-	draw.Draw(img, img.Bounds(), &image.Uniform{C: color.RGBA{0, 0, 0, 0}}, image.Point{}, draw.Src)
-	fg := color.RGBA{0, 0, 0, 255}
-	cx, cy, r := 8, 8, 4
-	for y := 0; y < 16; y++ {
-		for x := 0; x < 16; x++ {
-			dx, dy := x-cx, y-cy
-			if dx*dx+dy*dy <= r*r {
-				img.Set(x, y, fg)
-			}
-		}
-	}
-	var buf bytes.Buffer
-	_ = png.Encode(&buf, img)
-	return buf.Bytes()
-}
-
-// Error shows a native error dialog.
+// Error shows an error dialog. The error is logged as well, so it is on
+// record even when the dialog cannot be shown.
 func Error(title, msg string) {
-	_ = zenity.Error(msg, zenity.Title(title))
+	log.Printf("[error] %s: %s", title, msg)
+	opts, done := dialogOptions(zenity.Title(title))
+	defer done()
+	if err := zenity.Error(msg, opts...); err != nil && !canceled(err) {
+		log.Printf("[ui] error dialog: %v", err)
+	}
 }
 
 // QuickAddText shows a simple text-entry dialog for adding a task.
 // Returns (text, true, nil) on OK, ("", false, nil) on cancel, ("", false, err) on error.
 func QuickAddText() (string, bool, error) {
-	text, err := zenity.Entry(
-		"Task text:",
-		zenity.Title("Add task"),
-		zenity.OKLabel("Add"),
-		zenity.CancelLabel("Cancel"),
+	opts, done := dialogOptions(
+		zenity.Title(i18n.T("Add task")),
+		zenity.OKLabel(i18n.T("Add")),
+		zenity.CancelLabel(i18n.T("Cancel")),
 	)
-	if errors.Is(err, zenity.ErrCanceled) {
+	defer done()
+	text, err := zenity.Entry(i18n.T("Task text:"), opts...)
+	if canceled(err) {
 		return "", false, nil
 	}
 	if err != nil {
@@ -67,14 +55,1014 @@ func QuickAddText() (string, bool, error) {
 	return text, true, nil
 }
 
-// RenderPage wraps body HTML in a full page with shared styles.
+// EditText shows a text-entry dialog pre-filled with the current task text.
+// Returns (text, true, nil) on OK, ("", false, nil) on cancel or empty input.
+func EditText(current string) (string, bool, error) {
+	opts, done := dialogOptions(
+		zenity.Title(i18n.T("Edit task")),
+		zenity.EntryText(current),
+		zenity.OKLabel(i18n.T("Save")),
+		zenity.CancelLabel(i18n.T("Cancel")),
+	)
+	defer done()
+	text, err := zenity.Entry(i18n.T("Task text:"), opts...)
+	if canceled(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", false, nil
+	}
+	return text, true, nil
+}
+
+// Confirm shows a yes/no question. Returns true only when the user picks OK.
+func Confirm(title, msg, okLabel string) bool {
+	opts, done := dialogOptions(
+		zenity.Title(title),
+		zenity.OKLabel(okLabel),
+		zenity.CancelLabel(i18n.T("Cancel")),
+	)
+	defer done()
+	err := zenity.Question(msg, opts...)
+	return err == nil
+}
+
+// ConfirmMerge asks whether to delete the copies in a group of duplicate
+// tasks, described by msg. Returns true only when the user picks Delete
+// copies; Keep all and closing the dialog leave the group alone.
+func ConfirmMerge(msg string) bool {
+	opts, done := dialogOptions(
+		zenity.Title(i18n.T("Find duplicates")),
+		zenity.OKLabel(i18n.T("Delete copies")),
+		zenity.CancelLabel(i18n.T("Keep all")),
+	)
+	defer done()
+	return zenity.Question(msg, opts...) == nil
+}
+
+// QuickAddNotes asks for optional longer notes shown below the task text.
+// Cancel or empty input yields "".
+func QuickAddNotes() (string, error) {
+	opts, done := dialogOptions(
+		zenity.Title(i18n.T("Add task")),
+		zenity.OKLabel(i18n.T("Next")),
+		zenity.CancelLabel(i18n.T("No notes")),
+	)
+	defer done()
+	notes, err := zenity.Entry(i18n.T("Notes (optional):"), opts...)
+	if canceled(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(notes), nil
+}
+
+// QuickAddTags asks for optional comma-separated tags.
+// Returns the raw input; cancel or empty input yields "".
+func QuickAddTags() (string, error) {
+	opts, done := dialogOptions(
+		zenity.Title(i18n.T("Add task")),
+		zenity.OKLabel(i18n.T("Next")),
+		zenity.CancelLabel(i18n.T("No tags")),
+	)
+	defer done()
+	raw, err := zenity.Entry(i18n.T("Tags (comma-separated), leave empty for none:"), opts...)
+	if canceled(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(raw), nil
+}
+
+// SearchQuery asks for a search string. Returns ("", false, nil) on cancel
+// or empty input.
+func SearchQuery() (string, bool, error) {
+	opts, done := dialogOptions(
+		zenity.Title(i18n.T("Search")),
+		zenity.OKLabel(i18n.T("Search")),
+		zenity.CancelLabel(i18n.T("Cancel")),
+	)
+	defer done()
+	query, err := zenity.Entry(i18n.T("Find tasks containing:"), opts...)
+	if canceled(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	query = strings.TrimSpace(query)
+	return query, query != "", nil
+}
+
+// PickTag shows a list of tags and returns the selected one.
+// Returns ("", false, nil) on cancel.
+func PickTag(tags []string) (string, bool, error) {
+	opts, done := dialogOptions(zenity.Title(i18n.T("Filter by tag")))
+	defer done()
+	choice, err := zenity.List(i18n.T("Show tasks tagged:"), tags, opts...)
+	if canceled(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return choice, choice != "", nil
+}
+
+// PickBackup lets the user choose one of the backups described by labels
+// and returns its index. ok is false when the dialog is cancelled.
+func PickBackup(labels []string) (int, bool, error) {
+	opts, done := dialogOptions(
+		zenity.Title(i18n.T("Restore from backup")),
+		zenity.OKLabel(i18n.T("Restore")),
+	)
+	defer done()
+	items := make([]string, len(labels))
+	for i, l := range labels {
+		items[i] = fmt.Sprintf("%d. %s", i+1, l)
+	}
+	choice, err := zenity.List(i18n.T("Replace the queue with the version saved at:"), items, opts...)
+	if canceled(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	for i, item := range items {
+		if item == choice {
+			return i, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// PickTemplate lets the user choose one of the template labels and returns
+// its index. ok is false when the dialog is cancelled.
+func PickTemplate(title, prompt, okLabel string, labels []string) (int, bool, error) {
+	opts, done := dialogOptions(
+		zenity.Title(title),
+		zenity.OKLabel(okLabel),
+	)
+	defer done()
+	items := make([]string, len(labels))
+	for i, l := range labels {
+		items[i] = fmt.Sprintf("%d. %s", i+1, l)
+	}
+	choice, err := zenity.List(prompt, items, opts...)
+	if canceled(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	for i, item := range items {
+		if item == choice {
+			return i, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// TemplateName asks for the name to save a template under, pre-filled with
+// def. Returns ("", false, nil) on cancel or empty input.
+func TemplateName(def string) (string, bool, error) {
+	opts, done := dialogOptions(
+		zenity.Title(i18n.T("Save as template")),
+		zenity.EntryText(def),
+		zenity.OKLabel(i18n.T("Save")),
+		zenity.CancelLabel(i18n.T("Cancel")),
+	)
+	defer done()
+	name, err := zenity.Entry(i18n.T("Template name:"), opts...)
+	if canceled(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", false, nil
+	}
+	return name, true, nil
+}
+
+// PickAttachment lets the user choose one of names and returns its index.
+// ok is false when the dialog is cancelled.
+func PickAttachment(names []string) (int, bool, error) {
+	opts, done := dialogOptions(zenity.Title(i18n.T("Open attachment")))
+	defer done()
+	items := make([]string, len(names))
+	for i, n := range names {
+		// Numbered so that equal names still map back to one index.
+		items[i] = fmt.Sprintf("%d. %s", i+1, n)
+	}
+	choice, err := zenity.List(i18n.T("Open with the default app:"), items, opts...)
+	if canceled(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	for i, n := range items {
+		if n == choice {
+			return i, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// Info shows a native information dialog.
+func Info(title, msg string) {
+	opts, done := dialogOptions(zenity.Title(title))
+	defer done()
+	if err := zenity.Info(msg, opts...); err != nil && !canceled(err) {
+		log.Printf("[ui] info dialog: %v", err)
+	}
+}
+
+// ShowTask shows t in a native dialog, for when the manage page cannot be
+// opened in a browser: the text and notes as written, the due date, tags and
+// the names of the attachments, which are not displayed.
+func ShowTask(t queue.Task) {
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(t.Text))
+	if notes := strings.TrimSpace(t.Notes); notes != "" {
+		b.WriteString("\n\n" + notes)
+	}
+	var meta []string
+	if t.DueDate != nil {
+		meta = append(meta, i18n.Tf("Due: %s", t.DueDate.Local().Format("02.01.2006 15:04")))
+	}
+	if len(t.Tags) > 0 {
+		meta = append(meta, i18n.Tf("Tags: %s", strings.Join(t.Tags, ", ")))
+	}
+	for _, a := range t.Attachments {
+		meta = append(meta, i18n.Tf("Attachment: %s", filepath.Base(a.Path)))
+	}
+	if len(meta) > 0 {
+		b.WriteString("\n\n" + strings.Join(meta, "\n"))
+	}
+	Info(i18n.T("Current task"), b.String())
+}
+
+// Notify shows a desktop notification: libnotify on Linux, a toast on
+// Windows, Notification Center on macOS.
+func Notify(title, body string) error {
+	return zenity.Notify(body, zenity.Title(title))
+}
+
+// AttachChoice is the answer to the quick-add "attach files?" prompt.
+type AttachChoice int
+
+const (
+	AttachNone AttachChoice = iota
+	AttachFiles
+	AttachClipboard
+	AttachRecording
+	AttachURL
+	AttachScreenshot
+)
+
+// QuickAddAttachChoice asks where to take an attachment from: files, a URL
+// or, with canPaste, the clipboard image, with canCapture, a new screenshot
+// and, with canRecord, a new voice memo.
+func QuickAddAttachChoice(canPaste, canRecord, canCapture bool) AttachChoice {
+	type option struct {
+		label  string
+		choice AttachChoice
+	}
+	choices := []option{
+		{"Attach files", AttachFiles},
+	}
+	if canPaste {
+		choices = append(choices, option{"Paste image from clipboard", AttachClipboard})
+	}
+	choices = append(choices, option{"Attach from URL", AttachURL})
+	if canCapture {
+		choices = append(choices, option{"Take a screenshot", AttachScreenshot})
+	}
+	if canRecord {
+		choices = append(choices, option{"Record audio (up to 60 s)", AttachRecording})
+	}
+	items := make([]string, len(choices))
+	for i, c := range choices {
+		items[i] = i18n.T(c.label)
+	}
+	opts, done := dialogOptions(
+		zenity.Title(i18n.T("Add task")),
+		zenity.OKLabel(i18n.T("Next")),
+		zenity.CancelLabel(i18n.T("No attachment")),
+	)
+	defer done()
+	choice, err := zenity.List(i18n.T("Attach something to this task?"), items, opts...)
+	if err != nil {
+		return AttachNone
+	}
+	for _, c := range choices {
+		if i18n.T(c.label) == choice {
+			return c.choice
+		}
+	}
+	return AttachNone
+}
+
+// QuickAddURL asks for the address of an attachment to download. Returns
+// ("", false, nil) on cancel or empty input.
+func QuickAddURL() (string, bool, error) {
+	opts, done := dialogOptions(
+		zenity.Title(i18n.T("Attach from URL")),
+		zenity.OKLabel(i18n.T("Download")),
+		zenity.CancelLabel(i18n.T("Cancel")),
+	)
+	defer done()
+	raw, err := zenity.Entry(i18n.T("Image, audio or video URL (http or https):"), opts...)
+	if canceled(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	raw = strings.TrimSpace(raw)
+	return raw, raw != "", nil
+}
+
+// RecordingDialog shows a progress dialog with a Stop button while a
+// recording runs, counting down to limit. It returns when the user presses
+// Stop or closes the dialog (stopped is true) or when finished is closed
+// because the recorder exited on its own. It has no dialog timeout: the
+// recording limit bounds it.
+func RecordingDialog(limit time.Duration, finished <-chan struct{}) (stopped bool, err error) {
+	dlg, err := zenity.Progress(
+		zenity.Title(i18n.T("Recording")),
+		zenity.Pulsate(),
+		zenity.CancelLabel(i18n.T("Stop")),
+	)
+	if err != nil {
+		return false, err
+	}
+	defer dlg.Close()
+	start := time.Now()
+	tick := time.NewTicker(time.Second)
+	defer tick.Stop()
+	for {
+		left := (limit - time.Since(start)).Round(time.Second)
+		_ = dlg.Text(i18n.Tf("Recording… %v left. Press Stop to finish.", max(left, 0)))
+		select {
+		case <-dlg.Done():
+			return true, nil
+		case <-finished:
+			return false, nil
+		case <-tick.C:
+		}
+	}
+}
+
+// attachmentFilters lists the accepted attachment types for the file picker,
+// including the ones allowed in the settings.
+func attachmentFilters() zenity.FileFilters {
+	var filters zenity.FileFilters
+	for _, f := range []struct {
+		name string
+		t    queue.AttachmentType
+	}{
+		{"Images", queue.AttachmentImage},
+		{"Audio", queue.AttachmentAudio},
+		{"Video", queue.AttachmentVideo},
+		{"Other files", queue.AttachmentFile},
+	} {
+		var patterns []string
+		for _, ext := range queue.AttachmentExts(f.t) {
+			patterns = append(patterns, "*"+ext)
+		}
+		if len(patterns) > 0 {
+			filters = append(filters, zenity.FileFilter{Name: i18n.T(f.name), Patterns: patterns})
+		}
+	}
+	return filters
+}
+
+// QuickAddAttachments lets the user pick attachment files one at a time until
+// the picker is cancelled. Returns the selected source paths.
+func QuickAddAttachments() ([]string, error) {
+	var paths []string
+	for {
+		opts, done := dialogOptions(
+			zenity.Title(i18n.Tf("Attachment %d (Cancel to finish)", len(paths)+1)),
+			attachmentFilters(),
+		)
+		fp, err := zenity.SelectFile(opts...)
+		done()
+		if canceled(err) {
+			return paths, nil
+		}
+		if err != nil {
+			return paths, err
+		}
+		paths = append(paths, fp)
+	}
+}
+
+// PickImportFile asks for a .txt/.csv task list or a .zip export bundle.
+// Returns ("", false, nil) on cancel.
+func PickImportFile() (string, bool, error) {
+	opts, done := dialogOptions(
+		zenity.Title(i18n.T("Import tasks")),
+		zenity.FileFilters{
+			{Name: i18n.T("Task lists and bundles"), Patterns: []string{"*.txt", "*.csv", "*.zip"}},
+		},
+	)
+	defer done()
+	fp, err := zenity.SelectFile(opts...)
+	if canceled(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return fp, true, nil
+}
+
+// PickExportPath asks where to save the export bundle.
+// Returns ("", false, nil) on cancel.
+func PickExportPath(defaultName string) (string, bool, error) {
+	opts, done := dialogOptions(
+		zenity.Title(i18n.T("Export queue")),
+		zenity.Filename(defaultName),
+		zenity.ConfirmOverwrite(),
+		zenity.FileFilters{
+			{Name: i18n.T("Zip archive"), Patterns: []string{"*.zip"}},
+		},
+	)
+	defer done()
+	fp, err := zenity.SelectFileSave(opts...)
+	if canceled(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return fp, true, nil
+}
+
+// PickTaskExportPath asks where to save a task exported as an HTML page.
+// Returns ("", false, nil) on cancel.
+func PickTaskExportPath(defaultName string) (string, bool, error) {
+	opts, done := dialogOptions(
+		zenity.Title(i18n.T("Export task")),
+		zenity.Filename(defaultName),
+		zenity.ConfirmOverwrite(),
+		zenity.FileFilters{
+			{Name: i18n.T("HTML page"), Patterns: []string{"*.html"}},
+		},
+	)
+	defer done()
+	fp, err := zenity.SelectFileSave(opts...)
+	if canceled(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return fp, true, nil
+}
+
+// AskMarkdownTarget asks whether the queue of n tasks as Markdown goes to
+// the clipboard or to a file. ok is false when the dialog is cancelled.
+func AskMarkdownTarget(n int) (toFile, ok bool) {
+	opts, done := dialogOptions(
+		zenity.Title(i18n.T("Copy as Markdown")),
+		zenity.OKLabel(i18n.T("Copy")),
+		zenity.ExtraButton(i18n.T("Save as file…")),
+		zenity.CancelLabel(i18n.T("Cancel")),
+	)
+	defer done()
+	err := zenity.Question(i18n.Tf("Copy the %d queued tasks to the clipboard as a Markdown checklist, or save them to a .md file?", n), opts...)
+	switch {
+	case err == nil:
+		return false, true
+	case errors.Is(err, zenity.ErrExtraButton):
+		return true, true
+	}
+	return false, false
+}
+
+// PickMarkdownPath asks where to save the queue as Markdown. Returns
+// ("", false, nil) on cancel.
+func PickMarkdownPath(defaultName string) (string, bool, error) {
+	opts, done := dialogOptions(
+		zenity.Title(i18n.T("Copy as Markdown")),
+		zenity.Filename(defaultName),
+		zenity.ConfirmOverwrite(),
+		zenity.FileFilters{
+			{Name: i18n.T("Markdown"), Patterns: []string{"*.md"}},
+		},
+	)
+	defer done()
+	fp, err := zenity.SelectFileSave(opts...)
+	if canceled(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return fp, true, nil
+}
+
+// timeOfDayLayout is the format of the time entry that follows the calendar.
+const timeOfDayLayout = "15:04"
+
+// pickDateTime asks for a day in a calendar and then for the time of day,
+// starting from def. With future set, a moment that is not after now is
+// reported and the calendar is shown again. Cancelling either dialog returns
+// (zero, false, nil).
+func pickDateTime(title, prompt, cancelLabel string, def time.Time, future bool) (time.Time, bool, error) {
+	for {
+		opts, done := dialogOptions(
+			zenity.Title(title),
+			zenity.OKLabel(i18n.T("Next")),
+			zenity.CancelLabel(cancelLabel),
+			zenity.DefaultDate(def.Year(), def.Month(), def.Day()),
+		)
+		day, err := zenity.Calendar(prompt, opts...)
+		done()
+		if canceled(err) {
+			return time.Time{}, false, nil
+		}
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		clock, ok, err := pickTimeOfDay(title, cancelLabel, def)
+		if err != nil || !ok {
+			return time.Time{}, false, err
+		}
+		at := time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, time.Local)
+		if future && !at.After(time.Now()) {
+			Error(title, i18n.Tf("%s has already passed. Pick a later time.", at.Format("02 Jan 2006, 15:04")))
+			def = at
+			continue
+		}
+		return at, true, nil
+	}
+}
+
+// pickTimeOfDay asks for a time of day in timeOfDayLayout, prefilled from
+// def. Invalid input is reported and the prompt is shown again.
+func pickTimeOfDay(title, cancelLabel string, def time.Time) (time.Time, bool, error) {
+	for {
+		opts, done := dialogOptions(
+			zenity.Title(title),
+			zenity.OKLabel(i18n.T("OK")),
+			zenity.CancelLabel(cancelLabel),
+			zenity.EntryText(def.Format(timeOfDayLayout)),
+		)
+		raw, err := zenity.Entry(i18n.T("Time (HH:MM):"), opts...)
+		done()
+		if canceled(err) {
+			return time.Time{}, false, nil
+		}
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		raw = strings.TrimSpace(raw)
+		clock, err := time.Parse(timeOfDayLayout, raw)
+		if err != nil {
+			Error(title, i18n.Tf("Invalid time, expected HH:MM: %s", raw))
+			continue
+		}
+		return clock, true, nil
+	}
+}
+
+// QuickAddDueDate asks for an optional due date, the day in a calendar and
+// then the time of day. Returns (nil, nil) when either dialog is cancelled.
+// A date in the past is allowed: the task simply shows up as overdue.
+func QuickAddDueDate() (*time.Time, error) {
+	y, m, d := time.Now().Date()
+	def := time.Date(y, m, d+1, 9, 0, 0, 0, time.Local)
+	due, ok, err := pickDateTime(i18n.T("Add task"), i18n.T("Due date:"), i18n.T("No due date"), def, false)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &due, nil
+}
+
+// QuickAddReminders offers the common reminders from queue.ReminderOptions,
+// those before the due date only when due is set, and returns the chosen
+// ones resolved against now. Cancelling or selecting nothing means none.
+func QuickAddReminders(now time.Time, due *time.Time) ([]queue.Reminder, error) {
+	var options []queue.ReminderOption
+	for _, o := range queue.ReminderOptions {
+		if _, ok := o.At(now, due); ok {
+			options = append(options, o)
+		}
+	}
+	items := make([]string, len(options))
+	for i, o := range options {
+		items[i] = i18n.T(o.Label)
+	}
+	opts, done := dialogOptions(
+		zenity.Title(i18n.T("Add task")),
+		zenity.OKLabel(i18n.T("Next")),
+		zenity.CancelLabel(i18n.T("No reminder")),
+	)
+	defer done()
+	choices, err := zenity.ListMultiple(i18n.T("Remind me:"), items, opts...)
+	if canceled(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var rs []queue.Reminder
+	for _, c := range choices {
+		for i, item := range items {
+			if item == c {
+				at, _ := options[i].At(now, due)
+				rs = append(rs, queue.Reminder{At: at})
+				break
+			}
+		}
+	}
+	return rs, nil
+}
+
+// QuickAddEstimate asks for an optional time estimate, in minutes or as a
+// duration like 1h30m. Returns 0 when the field is left empty or the dialog
+// is cancelled. Invalid input is reported and the prompt is shown again.
+func QuickAddEstimate() (int, error) {
+	for {
+		opts, done := dialogOptions(
+			zenity.Title(i18n.T("Add task")),
+			zenity.OKLabel(i18n.T("Next")),
+			zenity.CancelLabel(i18n.T("No estimate")),
+		)
+		raw, err := zenity.Entry(i18n.T("Estimated time (minutes, or e.g. 1h30m), leave empty for none:"), opts...)
+		done()
+		if canceled(err) {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		m, err := queue.ParseEstimate(raw)
+		if err != nil {
+			Error(i18n.T("Add task"), err.Error())
+			continue
+		}
+		return m, nil
+	}
+}
+
+// CompletionNote asks for an optional note on how a task went before it is
+// completed. ok is false when the dialog is cancelled, which should leave the
+// task alone; an empty note is fine.
+func CompletionNote(taskText string) (note string, ok bool, err error) {
+	opts, done := dialogOptions(
+		zenity.Title(i18n.T("Complete task")),
+		zenity.OKLabel(i18n.T("Complete")),
+		zenity.CancelLabel(i18n.T("Cancel")),
+	)
+	defer done()
+	note, err = zenity.Entry(taskText+"\n\n"+i18n.T("Note (optional):"), opts...)
+	if canceled(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return strings.TrimSpace(note), true, nil
+}
+
+// PriorityLabels maps priority levels to display names, in ascending order.
+var PriorityLabels = []struct {
+	Priority int
+	Label    string
+}{
+	{queue.PriorityNormal, "Normal"},
+	{queue.PriorityHigh, "High"},
+	{queue.PriorityUrgent, "Urgent"},
+}
+
+// QuickAddPriority shows a list dialog for picking the task priority.
+// Cancelling the dialog keeps the default (normal) priority.
+func QuickAddPriority() (int, error) {
+	items := make([]string, len(PriorityLabels))
+	for i, p := range PriorityLabels {
+		items[i] = i18n.T(p.Label)
+	}
+	opts, done := dialogOptions(
+		zenity.Title(i18n.T("Add task")),
+		zenity.DefaultItems(items[0]),
+		zenity.OKLabel(i18n.T("Add")),
+		zenity.CancelLabel(i18n.T("Skip")),
+	)
+	defer done()
+	choice, err := zenity.List(i18n.T("Priority:"), items, opts...)
+	if canceled(err) {
+		return queue.PriorityNormal, nil
+	}
+	if err != nil {
+		return queue.PriorityNormal, err
+	}
+	for _, p := range PriorityLabels {
+		if i18n.T(p.Label) == choice {
+			return p.Priority, nil
+		}
+	}
+	return queue.PriorityNormal, nil
+}
+
+// RecurrenceLabels maps recurrence values to display names.
+var RecurrenceLabels = []struct {
+	Recurrence string
+	Label      string
+}{
+	{queue.RecurNone, "Once"},
+	{queue.RecurDaily, "Daily"},
+	{queue.RecurWeekly, "Weekly"},
+}
+
+// QuickAddRecurrence asks whether the task repeats after completion.
+// Cancelling the dialog keeps the task one-off.
+func QuickAddRecurrence() (string, error) {
+	items := make([]string, len(RecurrenceLabels))
+	for i, r := range RecurrenceLabels {
+		items[i] = i18n.T(r.Label)
+	}
+	opts, done := dialogOptions(
+		zenity.Title(i18n.T("Add task")),
+		zenity.DefaultItems(items[0]),
+		zenity.OKLabel(i18n.T("Next")),
+		zenity.CancelLabel(i18n.T("Skip")),
+	)
+	defer done()
+	choice, err := zenity.List(i18n.T("Repeat:"), items, opts...)
+	if canceled(err) {
+		return queue.RecurNone, nil
+	}
+	if err != nil {
+		return queue.RecurNone, err
+	}
+	for _, r := range RecurrenceLabels {
+		if i18n.T(r.Label) == choice {
+			return r.Recurrence, nil
+		}
+	}
+	return queue.RecurNone, nil
+}
+
+// PickSnooze asks how long to snooze a task for and returns when it should
+// come back. Returns (zero, false, nil) on cancel.
+func PickSnooze(now time.Time) (time.Time, bool, error) {
+	y, m, d := now.Date()
+	tomorrow := time.Date(y, m, d+1, 9, 0, 0, 0, now.Location())
+	options := []struct {
+		label string
+		until time.Time
+	}{
+		{"1 hour", now.Add(time.Hour)},
+		{"3 hours", now.Add(3 * time.Hour)},
+		{"Tomorrow, 09:00", tomorrow},
+		{"Pick a date…", time.Time{}},
+	}
+	items := make([]string, len(options))
+	for i, o := range options {
+		items[i] = i18n.T(o.label)
+	}
+	opts, done := dialogOptions(
+		zenity.Title(i18n.T("Snooze")),
+		zenity.DefaultItems(items[0]),
+		zenity.OKLabel(i18n.T("Snooze")),
+	)
+	defer done()
+	choice, err := zenity.List(i18n.T("Snooze the current task for:"), items, opts...)
+	if canceled(err) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	for _, o := range options {
+		if i18n.T(o.label) != choice {
+			continue
+		}
+		if o.until.IsZero() {
+			return pickDateTime(i18n.T("Snooze"), i18n.T("Snooze the current task until:"), i18n.T("Cancel"), tomorrow, true)
+		}
+		return o.until, true, nil
+	}
+	return time.Time{}, false, nil
+}
+
+// QuickAddColor asks for a color label from queue.Palette. Cancelling means
+// no color.
+func QuickAddColor() (string, error) {
+	items := make([]string, len(queue.Palette))
+	for i, c := range queue.Palette {
+		items[i] = i18n.T(strings.ToUpper(c.Name[:1]) + c.Name[1:])
+	}
+	opts, done := dialogOptions(
+		zenity.Title(i18n.T("Add task")),
+		zenity.OKLabel(i18n.T("Next")),
+		zenity.CancelLabel(i18n.T("No color")),
+	)
+	defer done()
+	choice, err := zenity.List(i18n.T("Color label:"), items, opts...)
+	if canceled(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	for i, item := range items {
+		if item == choice {
+			return queue.Palette[i].Name, nil
+		}
+	}
+	return "", nil
+}
+
+// QuickAddBlockers lets the user pick queued tasks the new task has to wait
+// for. Cancelling or selecting nothing means no dependencies.
+func QuickAddBlockers(tasks []queue.Task) ([]string, error) {
+	if len(tasks) == 0 {
+		return nil, nil
+	}
+	items := make([]string, len(tasks))
+	for i, t := range tasks {
+		items[i] = fmt.Sprintf("%d. %s", i+1, firstLine(t.Text))
+	}
+	opts, done := dialogOptions(
+		zenity.Title(i18n.T("Add task")),
+		zenity.OKLabel(i18n.T("Next")),
+		zenity.CancelLabel(i18n.T("Not blocked")),
+	)
+	defer done()
+	choices, err := zenity.ListMultiple(i18n.T("Blocked by (the task waits until these are done):"), items, opts...)
+	if canceled(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, c := range choices {
+		for i, item := range items {
+			if item == c {
+				ids = append(ids, tasks[i].ID)
+				break
+			}
+		}
+	}
+	return ids, nil
+}
+
+// PickTask shows a list of tasks and returns the ID of the selected one.
+// Returns ("", false, nil) on cancel.
+func PickTask(title, prompt string, tasks []queue.Task) (string, bool, error) {
+	items := make([]string, len(tasks))
+	for i, t := range tasks {
+		items[i] = fmt.Sprintf("%d. %s", i+1, firstLine(t.Text))
+	}
+	opts, done := dialogOptions(zenity.Title(title))
+	defer done()
+	choice, err := zenity.List(prompt, items, opts...)
+	if canceled(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	for i, item := range items {
+		if item == choice {
+			return tasks[i].ID, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// PickTasks shows a list of tasks and returns the IDs of the selected ones,
+// in list order. okLabel names the button that accepts the selection.
+// Returns (nil, nil) on cancel.
+func PickTasks(title, prompt, okLabel string, tasks []queue.Task) ([]string, error) {
+	items := make([]string, len(tasks))
+	for i, t := range tasks {
+		items[i] = fmt.Sprintf("%d. %s", i+1, firstLine(t.Text))
+	}
+	opts, done := dialogOptions(zenity.Title(title), zenity.OKLabel(okLabel))
+	defer done()
+	choices, err := zenity.ListMultiple(prompt, items, opts...)
+	if canceled(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	picked := make(map[string]bool, len(choices))
+	for _, c := range choices {
+		picked[c] = true
+	}
+	var ids []string
+	for i, item := range items {
+		if picked[item] {
+			ids = append(ids, tasks[i].ID)
+		}
+	}
+	return ids, nil
+}
+
+// Bulk actions offered by PickBulkAction.
+const (
+	BulkComplete = "complete"
+	BulkSkip     = "skip"
+	BulkDelete   = "delete"
+)
+
+// BulkActionLabels maps bulk actions to display names.
+var BulkActionLabels = []struct {
+	Action string
+	Label  string
+}{
+	{BulkComplete, "Complete"},
+	{BulkSkip, "Skip"},
+	{BulkDelete, "Delete"},
+}
+
+// PickBulkAction asks what to do with n selected tasks. Returns ("", false,
+// nil) on cancel.
+func PickBulkAction(n int) (string, bool, error) {
+	items := make([]string, len(BulkActionLabels))
+	for i, a := range BulkActionLabels {
+		items[i] = i18n.T(a.Label)
+	}
+	opts, done := dialogOptions(
+		zenity.Title(i18n.T("Bulk actions")),
+		zenity.DefaultItems(items[0]),
+	)
+	defer done()
+	choice, err := zenity.List(i18n.Tf("Do what with the %d selected tasks?", n), items, opts...)
+	if canceled(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	for _, a := range BulkActionLabels {
+		if i18n.T(a.Label) == choice {
+			return a.Action, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+func firstLine(text string) string {
+	if idx := strings.IndexByte(text, '\n'); idx >= 0 {
+		text = text[:idx]
+	}
+	text = strings.TrimSpace(text)
+	if runes := []rune(text); len(runes) > 80 {
+		return string(runes[:80]) + "…"
+	}
+	return text
+}
+
+// RenderPage wraps body HTML in a full page with shared styles. title is
+// plain text, translated and escaped here; body must already be safe HTML.
 func RenderPage(title, body string) string {
+	title = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(i18n.T(title))
 	return `<!doctype html><html><head>
 <meta charset="utf-8">
 <meta name="viewport" content="width=device-width,initial-scale=1">
 <title>` + title + `</title>
 <link rel="icon" type="image/png" href="/favicon.png">
-<style>
+` + pageStyle + `
+` + I18nScript() + `
+</head><body>` + body + `</body></html>`
+}
+
+// I18nScript defines T and Tf for page scripts. They look strings up in the
+// active i18n catalog like their Go namesakes; Tf fills %s and %d in order.
+// RenderPage includes it; pages with their own <head> add it there.
+func I18nScript() string {
+	// json.Marshal escapes <, > and &, so the catalog cannot end the script.
+	return `<script>
+const i18nCatalog = ` + i18n.JSON() + `;
+function T(key){ return i18nCatalog[key] ?? key; }
+function Tf(key, ...args){ let i = 0; return T(key).replace(/%[sd]/g, () => args[i++]); }
+</script>`
+}
+
+// pageStyle is the <style> block shared by RenderPage and RenderSharedTask.
+const pageStyle = `<style>
   body{font-family:-apple-system,Segoe UI,Roboto,Arial,sans-serif;line-height:1.6;padding:20px;max-width:860px;margin:0 auto;color:#1a1a1a}
   h1{font-size:20px;margin:0 0 16px}
   button{padding:8px 14px;border-radius:8px;border:1px solid #ccc;background:#fff;cursor:pointer;font-size:14px}
@@ -88,17 +1076,25 @@ func RenderPage(title, body string) string {
   pre code{padding:0}
   pre{padding:12px}
   audio{width:100%;margin:8px 0}
-</style>
-</head><body>` + body + `</body></html>`
-}
+  .notes{margin-top:12px;padding:10px 14px;border-left:3px solid #ddd;background:#fafafa;border-radius:0 8px 8px 0;color:#444;font-size:14px}
+  video{max-width:100%;max-height:480px;margin:8px 0;border-radius:8px;background:#000}
+</style>`
 
 // RenderTaskHTML renders a task's markdown content to an HTML fragment.
+// Notes, if any, follow in their own block.
 func RenderTaskHTML(t queue.Task) (string, error) {
 	md := t.Text
 
-	if t.AttachmentType == queue.AttachmentAudio && t.AttachmentPath != "" {
-		audioURL := fileURLFromPath(t.AttachmentPath)
-		md += "\n\n<audio controls src=\"" + audioURL + "\"></audio>\n"
+	for _, a := range t.Attachments {
+		if a.Path == "" {
+			continue
+		}
+		switch a.Type {
+		case queue.AttachmentAudio:
+			md += "\n\n<audio controls src=\"" + fileURLFromPath(a.Path) + "\"></audio>\n"
+		case queue.AttachmentVideo:
+			md += "\n\n<video controls src=\"" + fileURLFromPath(a.Path) + "\"></video>\n"
+		}
 	}
 
 	gm := goldmark.New(
@@ -110,8 +1106,16 @@ func RenderTaskHTML(t queue.Task) (string, error) {
 	if err := gm.Convert([]byte(md), &out); err != nil {
 		return "", err
 	}
+	frag := sanitizeHTML(out.String())
 
-	return sanitizeHTML(out.String()), nil
+	if strings.TrimSpace(t.Notes) != "" {
+		var notes bytes.Buffer
+		if err := gm.Convert([]byte(t.Notes), &notes); err != nil {
+			return "", err
+		}
+		frag += `<div class="notes">` + sanitizeHTML(notes.String()) + `</div>`
+	}
+	return frag, nil
 }
 
 func fileURLFromPath(p string) string {
@@ -129,9 +1133,9 @@ func fileURLFromPath(p string) string {
 func sanitizeHTML(htmlStr string) string {
 	p := bluemonday.UGCPolicy()
 
-	p.AllowElements("audio", "source")
-	p.AllowAttrs("controls").OnElements("audio")
-	p.AllowAttrs("src").OnElements("audio", "source")
+	p.AllowElements("audio", "video", "source")
+	p.AllowAttrs("controls").OnElements("audio", "video")
+	p.AllowAttrs("src").OnElements("audio", "video", "source")
 	p.AllowAttrs("type").OnElements("source")
 
 	p.AllowElements("img")
@@ -139,5 +1143,10 @@ func sanitizeHTML(htmlStr string) string {
 
 	p.AllowURLSchemes("http", "https", "mailto", "file")
 
+	// External links from task text open in a new browser tab and must not
+	// get a handle on the manage UI page.
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	p.RequireNoReferrerOnFullyQualifiedLinks(true)
+
 	return p.Sanitize(htmlStr)
 }
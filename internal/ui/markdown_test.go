@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/Ameight/systray-queue-app/internal/queue"
+)
+
+func TestRenderTaskHTMLStripsScripts(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		bad  []string // must not appear in the output, compared in lower case
+	}{
+		{"script tag", "Hi <script>alert(1)</script> there", []string{"<script", "alert(1)"}},
+		{"script block", "<script>\nfetch('/history/clear')\n</script>", []string{"<script", "fetch("}},
+		{"markdown javascript link", "[click](javascript:alert(1))", []string{"javascript:"}},
+		{"html javascript link", `<a href="javascript:alert(1)">click</a>`, []string{"javascript:"}},
+		{"mixed-case scheme", `<a href="JaVaScRiPt:alert(1)">click</a>`, []string{"javascript:"}},
+		{"entity-encoded scheme", `<a href="&#106;avascript:alert(1)">click</a>`, []string{"javascript:", "avascript:"}},
+		{"data link", `<a href="data:text/html,<script>alert(1)</script>">x</a>`, []string{"data:", "<script"}},
+		{"img onerror", `<img src="x.png" onerror="alert(1)">`, []string{"onerror"}},
+		{"markdown image javascript", "![x](javascript:alert(1))", []string{"javascript:"}},
+		{"div onclick", `<div onclick="alert(1)">x</div>`, []string{"onclick"}},
+		{"onmouseover on link", `<a href="https://example.com" onmouseover="alert(1)">x</a>`, []string{"onmouseover"}},
+		{"audio onplay", `<audio controls src="a.mp3" onplay="alert(1)"></audio>`, []string{"onplay"}},
+		{"iframe", `<iframe src="https://example.com"></iframe>`, []string{"<iframe"}},
+		{"object", `<object data="x.swf"></object>`, []string{"<object"}},
+		{"style tag", `<style>body{display:none}</style>`, []string{"<style", "display:none"}},
+		{"svg onload", `<svg onload="alert(1)"></svg>`, []string{"onload", "<svg"}},
+		{"form", `<form action="/history/clear" method="post"><button>x</button></form>`, []string{"<form", "action="}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, task := range []queue.Task{{Text: tt.text}, {Text: "task", Notes: tt.text}} {
+				out, err := RenderTaskHTML(task)
+				if err != nil {
+					t.Fatal(err)
+				}
+				low := strings.ToLower(out)
+				for _, bad := range tt.bad {
+					if strings.Contains(low, bad) {
+						t.Errorf("output contains %q:\n%s", bad, out)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRenderTaskHTMLKeepsSafeMarkup(t *testing.T) {
+	out, err := RenderTaskHTML(queue.Task{
+		Text:        "**bold** [site](https://example.com)",
+		Attachments: []queue.Attachment{{Path: "/data/attachments/note.m4a", Type: queue.AttachmentAudio}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<strong>bold</strong>",
+		`href="https://example.com"`,
+		`target="_blank"`,
+		`rel="nofollow noreferrer noopener"`,
+		`<audio controls`,
+		`src="file:///data/attachments/note.m4a"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderTaskHTMLEscapesAttachmentNames(t *testing.T) {
+	tagRe := regexp.MustCompile(`<(\w+)`)
+	attrRe := regexp.MustCompile(`(?i)\son\w+\s*=`)
+	for _, name := range []string{`x"><script>alert(1)</script>.m4a`, `x' onmouseover='alert(1)'.m4a`, `x" onerror="alert(1).mp4`} {
+		for _, typ := range []queue.AttachmentType{queue.AttachmentAudio, queue.AttachmentVideo} {
+			out, err := RenderTaskHTML(queue.Task{Text: "task", Attachments: []queue.Attachment{{Path: "/data/attachments/" + name, Type: typ}}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The name stays inside src, percent-encoded: no new tag or attribute.
+			if tags := tagRe.FindAllStringSubmatch(out, -1); len(tags) != 3 || tags[2][1] != string(typ) {
+				t.Errorf("%s %q: unexpected tags in\n%s", typ, name, out)
+			}
+			if attrRe.MatchString(out) {
+				t.Errorf("%s %q: output has an event handler:\n%s", typ, name, out)
+			}
+		}
+	}
+}
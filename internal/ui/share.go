@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"mime"
+	"path/filepath"
+	"strings"
+
+	"github.com/Ameight/systray-queue-app/internal/queue"
+)
+
+// RenderSharedTask renders t as a standalone HTML page for sending to
+// someone else. Attachments are inlined as data: URLs, so the page refers to
+// no local files and opens in any browser. read returns the contents of an
+// attachment file, decrypted (see TaskQueue.ReadAttachment).
+func RenderSharedTask(t queue.Task, read func(path string) ([]byte, error)) (string, error) {
+	frag, err := RenderTaskHTML(queue.Task{ID: t.ID, Text: t.Text, Notes: t.Notes, CreatedAt: t.CreatedAt})
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString(`<p class="muted">Created ` + t.CreatedAt.Local().Format("02 Jan 2006, 15:04"))
+	if t.DueDate != nil {
+		b.WriteString(` · due ` + t.DueDate.Local().Format("02 Jan 2006, 15:04"))
+	}
+	b.WriteString(`</p><div class="card">` + frag)
+	if len(t.Checklist) > 0 {
+		b.WriteString(`<ul style="list-style:none;padding-left:0">`)
+		for _, it := range t.Checklist {
+			box := "☐"
+			if it.Done {
+				box = "☑"
+			}
+			b.WriteString(`<li>` + box + ` ` + html.EscapeString(it.Text) + `</li>`)
+		}
+		b.WriteString(`</ul>`)
+	}
+	for _, a := range t.Attachments {
+		if a.Path == "" {
+			continue
+		}
+		data, err := read(a.Path)
+		if err != nil {
+			return "", fmt.Errorf("attachment %s: %w", filepath.Base(a.Path), err)
+		}
+		src := dataURL(a, data)
+		name := html.EscapeString(filepath.Base(a.Path))
+		switch a.Type {
+		case queue.AttachmentImage:
+			b.WriteString(`<p><img src="` + src + `" alt="` + name + `"></p>`)
+		case queue.AttachmentAudio:
+			b.WriteString(`<audio controls src="` + src + `"></audio>`)
+		case queue.AttachmentVideo:
+			b.WriteString(`<video controls src="` + src + `"></video>`)
+		default:
+			b.WriteString(`<p>📄 <a href="` + src + `" download="` + name + `">` + name + `</a></p>`)
+		}
+		if label := a.MetaLabel(); label != "" {
+			b.WriteString(`<p class="muted">` + label + `</p>`)
+		}
+	}
+	b.WriteString(`</div>`)
+
+	title := html.EscapeString(firstLine(t.Text))
+	return `<!doctype html><html><head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width,initial-scale=1">
+<title>` + title + `</title>
+` + pageStyle + `
+</head><body>` + b.String() + `</body></html>`, nil
+}
+
+// dataURL encodes an attachment as a data: URL, typed by its extension.
+func dataURL(a queue.Attachment, data []byte) string {
+	typ := mime.TypeByExtension(strings.ToLower(filepath.Ext(a.Path)))
+	if typ == "" {
+		typ = "application/octet-stream"
+	}
+	return "data:" + typ + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"os"
 	"os/exec"
@@ -127,10 +128,12 @@ func Install(info *UpdateInfo) error {
 	}
 
 	// Relaunch: prefer 'open Bundle.app', fall back to direct exec.
+	relaunch := exec.Command(exePath)
 	if bundle := findBundle(exePath); bundle != "" {
-		_ = exec.Command("open", bundle).Start()
-	} else {
-		_ = exec.Command(exePath).Start()
+		relaunch = exec.Command("open", bundle)
+	}
+	if err := relaunch.Start(); err != nil {
+		log.Printf("[updater] relaunch: %v", err)
 	}
 	return nil
 }
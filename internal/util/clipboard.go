@@ -0,0 +1,64 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/atotto/clipboard"
+)
+
+// CopyText puts text on the system clipboard as UTF-8 (UTF-16 on Windows),
+// so multi-line and non-Latin text survive. On Linux it needs xclip, xsel or
+// wl-copy.
+func CopyText(text string) error {
+	return clipboard.WriteAll(text)
+}
+
+// ErrNoClipboardImage is returned when the clipboard holds no image data.
+var ErrNoClipboardImage = errors.New("clipboard has no image")
+
+// CanPasteImage reports whether SaveClipboardImage can work here: always on
+// macOS, on Linux when xclip is installed, and never elsewhere.
+func CanPasteImage() bool {
+	switch runtime.GOOS {
+	case "darwin":
+		return true
+	case "linux":
+		_, err := exec.LookPath("xclip")
+		return err == nil
+	default:
+		return false
+	}
+}
+
+// SaveClipboardImage writes the clipboard image to dst as PNG.
+// Supported on macOS (osascript) and Linux (xclip); other platforms
+// always report ErrNoClipboardImage.
+func SaveClipboardImage(dst string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		script := []string{
+			"-e", "set png to (the clipboard as «class PNGf»)",
+			"-e", fmt.Sprintf("set f to open for access (POSIX file %q) with write permission", dst),
+			"-e", "set eof f to 0",
+			"-e", "write png to f",
+			"-e", "close access f",
+		}
+		if err := exec.Command("osascript", script...).Run(); err != nil {
+			_ = os.Remove(dst)
+			return ErrNoClipboardImage
+		}
+		return nil
+	case "linux":
+		out, err := exec.Command("xclip", "-selection", "clipboard", "-t", "image/png", "-o").Output()
+		if err != nil || len(out) == 0 {
+			return ErrNoClipboardImage
+		}
+		return AtomicWriteFile(dst, out, 0o644)
+	default:
+		return ErrNoClipboardImage
+	}
+}
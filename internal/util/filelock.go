@@ -0,0 +1,45 @@
+package util
+
+import (
+	"errors"
+	"os"
+)
+
+// ErrLocked is returned by TryLockFile while another process holds the lock.
+var ErrLocked = errors.New("file is locked by another process")
+
+// FileLock is an exclusive advisory lock on a file. The OS drops it when the
+// holding process exits, even after a crash.
+type FileLock struct {
+	f *os.File
+}
+
+// LockFile takes the lock on path, creating the file if needed, and waits
+// while another process holds it.
+func LockFile(path string) (*FileLock, error) {
+	return openLock(path, true)
+}
+
+// TryLockFile is LockFile without waiting: it fails with ErrLocked while
+// another process holds the lock.
+func TryLockFile(path string) (*FileLock, error) {
+	return openLock(path, false)
+}
+
+func openLock(path string, wait bool) (*FileLock, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := flock(f, wait); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &FileLock{f: f}, nil
+}
+
+// Unlock drops the lock.
+func (l *FileLock) Unlock() error {
+	_ = funlock(l.f)
+	return l.f.Close()
+}
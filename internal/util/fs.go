@@ -10,16 +10,44 @@ import (
 	"strings"
 )
 
+// EnvDataDir overrides the data directory, e.g. to keep the queue in a synced
+// folder. Every data file is placed relative to it. It takes precedence over
+// the data folder chosen in Settings.
+const EnvDataDir = "QUEUE_DATA_DIR"
+
 func AppDataDir() (string, error) {
-	cfgBase, err := os.UserConfigDir()
+	if dir := os.Getenv(EnvDataDir); dir != "" {
+		dir, err := filepath.Abs(dir)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", EnvDataDir, err)
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return "", fmt.Errorf("%s: %w", EnvDataDir, err)
+		}
+		return dir, nil
+	}
+	s, err := LoadSettings()
 	if err != nil {
 		return "", err
 	}
-	dir := filepath.Join(cfgBase, "systray-queue-app")
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return "", err
+	if s.DataDir != "" {
+		if err := os.MkdirAll(s.DataDir, 0o755); err != nil {
+			return "", fmt.Errorf("data folder from settings: %w", err)
+		}
+		return s.DataDir, nil
+	}
+	return DefaultDataDir()
+}
+
+// CheckWritable returns an error if files cannot be created in dir.
+func CheckWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return err
 	}
-	return dir, nil
+	name := f.Name()
+	_ = f.Close()
+	return os.Remove(name)
 }
 
 func OpenWithSystem(path string) error {
@@ -33,6 +61,15 @@ func OpenWithSystem(path string) error {
 	}
 }
 
+// OpenFolder shows dir in the system file manager: Finder, Explorer or
+// whatever xdg-open picks.
+func OpenFolder(dir string) error {
+	if runtime.GOOS == "windows" {
+		return exec.Command("explorer", dir).Start()
+	}
+	return OpenWithSystem(dir)
+}
+
 func OpenBrowser(url string) error {
 	return OpenWithSystem(url)
 }
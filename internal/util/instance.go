@@ -0,0 +1,32 @@
+package util
+
+import "errors"
+
+// ErrAlreadyRunning is returned by LockInstance when another process holds
+// the lock.
+var ErrAlreadyRunning = errors.New("another instance is already running")
+
+// InstanceLock is held by the running tray app for its whole lifetime, see
+// LockInstance.
+type InstanceLock struct {
+	l *FileLock
+}
+
+// LockInstance takes an exclusive advisory lock on path without waiting. It
+// fails with ErrAlreadyRunning while another process holds it; the OS drops
+// the lock when that process exits, even after a crash.
+func LockInstance(path string) (*InstanceLock, error) {
+	l, err := TryLockFile(path)
+	if errors.Is(err, ErrLocked) {
+		return nil, ErrAlreadyRunning
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &InstanceLock{l: l}, nil
+}
+
+// Release drops the lock.
+func (l *InstanceLock) Release() error {
+	return l.l.Unlock()
+}
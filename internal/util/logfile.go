@@ -0,0 +1,104 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// RotatingLog is an io.Writer that appends to a file and rotates it once it
+// would grow past maxSize: path becomes path.1, path.1 becomes path.2 and so
+// on, keeping at most keep files including the current one.
+type RotatingLog struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	keep    int
+	f       *os.File
+	size    int64
+}
+
+// OpenRotatingLog opens path for appending, creating it if needed.
+func OpenRotatingLog(path string, maxSize int64, keep int) (*RotatingLog, error) {
+	l := &RotatingLog{path: path, maxSize: maxSize, keep: max(keep, 1)}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *RotatingLog) open() error {
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	l.f, l.size = f, fi.Size()
+	return nil
+}
+
+func (l *RotatingLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return 0, os.ErrClosed
+	}
+	if l.size > 0 && l.size+int64(len(p)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			// Keep logging to whatever file is open rather than losing the line.
+			fmt.Fprintf(os.Stderr, "log rotation: %v\n", err)
+		}
+	}
+	if l.f == nil {
+		return 0, os.ErrClosed
+	}
+	n, err := l.f.Write(p)
+	l.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the files by one and starts a new, empty log. The file is
+// closed first because Windows cannot rename an open file. Caller holds l.mu.
+func (l *RotatingLog) rotate() error {
+	if err := l.f.Close(); err != nil {
+		return err
+	}
+	l.f = nil
+	name := func(i int) string {
+		if i == 0 {
+			return l.path
+		}
+		return fmt.Sprintf("%s.%d", l.path, i)
+	}
+	_ = os.Remove(name(l.keep - 1))
+	for i := l.keep - 2; i >= 0; i-- {
+		if err := os.Rename(name(i), name(i+1)); err != nil && !os.IsNotExist(err) {
+			_ = l.open()
+			return err
+		}
+	}
+	return l.open()
+}
+
+// Close closes the current file. Later writes fail with os.ErrClosed.
+func (l *RotatingLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return nil
+	}
+	err := l.f.Close()
+	l.f = nil
+	return err
+}
+
+// IsTerminal reports whether f is a character device, i.e. the process was
+// started from a terminal rather than by the desktop.
+func IsTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,282 @@
+package util
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// ErrNoRecorder is returned when no command-line audio recorder is installed.
+var ErrNoRecorder = errors.New("no audio recorder found (install sox or ffmpeg; arecord also works on Linux)")
+
+// recorder describes how to record a WAV file with one external tool.
+type recorder struct {
+	name string
+	args func(dst string, limit time.Duration) []string
+	// quitByStdin means the tool finishes the file cleanly when it reads
+	// "q" on stdin (ffmpeg); the others stop on an interrupt signal.
+	quitByStdin bool
+	// rawPCM means the tool writes mono 16-bit PCM at pcmRate to stdout
+	// and the WAV file is written here. Stop closes the pipe, which ends
+	// the tool on its next write; sox on Windows needs this, as it cannot
+	// be interrupted there and a killed sox leaves the header unfinished.
+	rawPCM bool
+}
+
+// pcmRate is the sample rate asked of rawPCM recorders.
+const pcmRate = 44100
+
+func seconds(d time.Duration) string { return strconv.Itoa(int(d / time.Second)) }
+
+// recorders lists the tools tried on this platform, best first. All of them
+// read the default input device.
+func recorders() []recorder {
+	sox := recorder{name: "rec", args: func(dst string, limit time.Duration) []string {
+		return []string{"-q", "-c", "1", dst, "trim", "0", seconds(limit)}
+	}}
+	ffmpeg := func(format, input string) recorder {
+		return recorder{name: "ffmpeg", quitByStdin: true, args: func(dst string, limit time.Duration) []string {
+			return []string{"-hide_banner", "-loglevel", "error", "-y", "-f", format, "-i", input, "-t", seconds(limit), "-ac", "1", dst}
+		}}
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return []recorder{sox, ffmpeg("avfoundation", ":0")}
+	case "linux":
+		arecord := recorder{name: "arecord", args: func(dst string, limit time.Duration) []string {
+			return []string{"-q", "-f", "cd", "-c", "1", "-d", seconds(limit), dst}
+		}}
+		return []recorder{arecord, sox, ffmpeg("pulse", "default")}
+	case "windows":
+		// sox ships as sox.exe only; "rec" is a Unix symlink.
+		return []recorder{{name: "sox", rawPCM: true, args: func(_ string, limit time.Duration) []string {
+			return []string{"-q", "-t", "waveaudio", "-d",
+				"-t", "raw", "-e", "signed-integer", "-b", "16", "-c", "1", "-r", strconv.Itoa(pcmRate), "-",
+				"trim", "0", seconds(limit)}
+		}}}
+	}
+	return nil
+}
+
+func findRecorder() (recorder, string, bool) {
+	for _, r := range recorders() {
+		if path, err := exec.LookPath(r.name); err == nil {
+			return r, path, true
+		}
+	}
+	return recorder{}, "", false
+}
+
+// CanRecordAudio reports whether StartRecording has a tool to use. It does
+// not check for a microphone; without one the recording fails right away.
+func CanRecordAudio() bool {
+	_, _, ok := findRecorder()
+	return ok
+}
+
+// Recording is an audio recording in progress, see StartRecording.
+type Recording struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	pcm   *os.File // read end of a rawPCM recorder's stdout
+	rec   recorder
+	done  chan struct{} // closed when the recorder has exited
+	err   error         // exit status, valid once done is closed
+}
+
+// StartRecording records the default microphone into dst as WAV until Stop
+// is called or limit has passed, whichever comes first.
+func StartRecording(dst string, limit time.Duration) (*Recording, error) {
+	rec, path, ok := findRecorder()
+	if !ok {
+		return nil, ErrNoRecorder
+	}
+	cmd := exec.Command(path, rec.args(dst, limit)...)
+	r := &Recording{cmd: cmd, rec: rec, done: make(chan struct{})}
+	if rec.quitByStdin {
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			return nil, err
+		}
+		r.stdin = stdin
+	}
+	var out *os.File
+	if rec.rawPCM {
+		var err error
+		if out, err = os.Create(dst); err != nil {
+			return nil, err
+		}
+		pr, pw, err := os.Pipe()
+		if err != nil {
+			_ = out.Close()
+			return nil, err
+		}
+		// The recorder has its own copy of the write end once started.
+		defer pw.Close()
+		cmd.Stdout = pw
+		r.pcm = pr
+	}
+	if err := cmd.Start(); err != nil {
+		if out != nil {
+			_ = r.pcm.Close()
+			_ = out.Close()
+		}
+		return nil, fmt.Errorf("%s: %w", rec.name, err)
+	}
+	go func() {
+		var werr error
+		if out != nil {
+			werr = writeWAV(out, r.pcm)
+		}
+		if err := cmd.Wait(); err != nil {
+			r.err = fmt.Errorf("%s: %w", rec.name, err)
+		} else if werr != nil {
+			r.err = werr
+		}
+		close(r.done)
+	}()
+	return r, nil
+}
+
+// Done is closed once the recorder has exited, either on its own (time
+// limit reached, no microphone) or after Stop; Err then tells which.
+func (r *Recording) Done() <-chan struct{} { return r.done }
+
+// Err returns the recorder's exit status after Done is closed.
+func (r *Recording) Err() error {
+	<-r.done
+	return r.err
+}
+
+// Stop asks the recorder to finish the file and waits for it to exit. A
+// recorder that already exited is left alone and its status returned.
+func (r *Recording) Stop() error {
+	select {
+	case <-r.done:
+		return r.err
+	default:
+	}
+	var err error
+	switch {
+	case r.stdin != nil:
+		_, err = io.WriteString(r.stdin, "q")
+		_ = r.stdin.Close()
+	case r.pcm != nil:
+		// writeWAV treats the closed pipe as the end of the recording.
+		err = r.pcm.Close()
+	default:
+		err = r.cmd.Process.Signal(os.Interrupt)
+	}
+	if err != nil {
+		return fmt.Errorf("stopping %s: %w", r.rec.name, err)
+	}
+	select {
+	case <-r.done:
+		// Stopped recorders exit non-zero although the file is fine.
+		var exit *exec.ExitError
+		if errors.As(r.err, &exit) {
+			return nil
+		}
+		return r.err
+	case <-time.After(5 * time.Second):
+		_ = r.cmd.Process.Kill()
+		return fmt.Errorf("%s did not stop", r.rec.name)
+	}
+}
+
+// wavHeaderSize is the size of the canonical PCM WAV header.
+const wavHeaderSize = 44
+
+// writeWAV copies mono 16-bit PCM from src into f until src ends or is
+// closed, then fills in the WAV header in front of it and closes f.
+func writeWAV(f *os.File, src io.Reader) error {
+	_, err := f.Write(make([]byte, wavHeaderSize))
+	var n int64
+	if err == nil {
+		n, err = io.Copy(f, src)
+		if errors.Is(err, os.ErrClosed) {
+			err = nil
+		}
+	}
+	if err == nil {
+		n -= n % 2 // drop half a sample cut off by the stop
+		err = f.Truncate(wavHeaderSize + n)
+	}
+	if err == nil {
+		_, err = f.WriteAt(wavHeader(uint32(n)), 0)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func wavHeader(dataSize uint32) []byte {
+	const (
+		channels = 1
+		bits     = 16
+	)
+	h := make([]byte, 0, wavHeaderSize)
+	h = append(h, "RIFF"...)
+	h = binary.LittleEndian.AppendUint32(h, 36+dataSize)
+	h = append(h, "WAVEfmt "...)
+	h = binary.LittleEndian.AppendUint32(h, 16)
+	h = binary.LittleEndian.AppendUint16(h, 1) // PCM
+	h = binary.LittleEndian.AppendUint16(h, channels)
+	h = binary.LittleEndian.AppendUint32(h, pcmRate)
+	h = binary.LittleEndian.AppendUint32(h, pcmRate*channels*bits/8)
+	h = binary.LittleEndian.AppendUint16(h, channels*bits/8)
+	h = binary.LittleEndian.AppendUint16(h, bits)
+	h = append(h, "data"...)
+	return binary.LittleEndian.AppendUint32(h, dataSize)
+}
+
+// ValidateWAV checks that path is a finished WAV file with some audio: a
+// RIFF/WAVE header, a fmt chunk, and a non-empty data chunk that fits in
+// the file. A recorder that was cut off leaves a zero or oversized length.
+func ValidateWAV(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	var riff [12]byte
+	if _, err := io.ReadFull(f, riff[:]); err != nil || string(riff[:4]) != "RIFF" || string(riff[8:]) != "WAVE" {
+		return errors.New("not a WAV file")
+	}
+	pos, haveFmt := int64(len(riff)), false
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(f, chunk[:]); err != nil {
+			return errors.New("the WAV file has no audio data")
+		}
+		pos += int64(len(chunk))
+		size := int64(binary.LittleEndian.Uint32(chunk[4:]))
+		switch string(chunk[:4]) {
+		case "fmt ":
+			haveFmt = true
+		case "data":
+			if !haveFmt {
+				return errors.New("the WAV file has no format chunk")
+			}
+			if size == 0 || pos+size > fi.Size() {
+				return errors.New("the WAV file is unfinished")
+			}
+			return nil
+		}
+		pos += size + size%2 // chunks are padded to an even size
+		if _, err := f.Seek(pos, io.SeekStart); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package util
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteWAVFillsInTheHeader(t *testing.T) {
+	dst := filepath.Join(t.TempDir(), "a.wav")
+	out, err := os.Create(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pw.Close()
+	done := make(chan error, 1)
+	go func() { done <- writeWAV(out, pr) }()
+	// An odd length: the stop cuts a sample in half.
+	if _, err := pw.Write(make([]byte, 1001)); err != nil {
+		t.Fatal(err)
+	}
+	pw.Close()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateWAV(dst); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != wavHeaderSize+1000 {
+		t.Fatalf("file is %d bytes, want %d", len(b), wavHeaderSize+1000)
+	}
+	if got := binary.LittleEndian.Uint32(b[40:]); got != 1000 {
+		t.Fatalf("data size %d, want 1000", got)
+	}
+}
+
+func TestValidateWAVRejects(t *testing.T) {
+	unfinished := append(wavHeader(0x7ffff000), make([]byte, 100)...)
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"not wav", []byte("ID3 this is an mp3 file, not a wav one at all")},
+		{"no audio", wavHeader(0)},
+		{"unfinished header", unfinished},
+		{"data before fmt", append([]byte("RIFF\x10\x00\x00\x00WAVEdata\x04\x00\x00\x00"), 1, 2, 3, 4)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := filepath.Join(t.TempDir(), "a.wav")
+			if err := os.WriteFile(p, tt.data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := ValidateWAV(p); err == nil {
+				t.Fatal("ValidateWAV accepted it")
+			}
+		})
+	}
+}
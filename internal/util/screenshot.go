@@ -0,0 +1,102 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// ErrNoScreenshotTool is returned when no screen capture tool is installed.
+var ErrNoScreenshotTool = errors.New("no screenshot tool found (install gnome-screenshot, spectacle, maim or ImageMagick)")
+
+// ErrScreenshotCanceled is returned when the user dismissed the capture, e.g.
+// with Esc while selecting an area, so no picture was taken.
+var ErrScreenshotCanceled = errors.New("screenshot canceled")
+
+// capturer describes how to take a PNG screenshot with one external tool.
+type capturer struct {
+	name string
+	args func(dst string) []string
+}
+
+// windowsCapture saves the whole virtual screen, as Windows has no snipping
+// tool that writes to a file; the dialog that asked is closed by then.
+const windowsCapture = `Add-Type -AssemblyName System.Windows.Forms,System.Drawing
+$b = [System.Windows.Forms.SystemInformation]::VirtualScreen
+$bmp = New-Object System.Drawing.Bitmap $b.Width, $b.Height
+[System.Drawing.Graphics]::FromImage($bmp).CopyFromScreen($b.Location, [System.Drawing.Point]::Empty, $b.Size)
+$bmp.Save(%s, [System.Drawing.Imaging.ImageFormat]::Png)`
+
+// capturers lists the tools tried on this platform, best first. Except on
+// Windows they let the user pick an area or a window.
+func capturers() []capturer {
+	switch runtime.GOOS {
+	case "darwin":
+		return []capturer{{name: "screencapture", args: func(dst string) []string {
+			return []string{"-i", "-x", dst}
+		}}}
+	case "linux":
+		return []capturer{
+			{name: "gnome-screenshot", args: func(dst string) []string { return []string{"-a", "-f", dst} }},
+			{name: "spectacle", args: func(dst string) []string { return []string{"-b", "-n", "-r", "-o", dst} }},
+			{name: "maim", args: func(dst string) []string { return []string{"-s", dst} }},
+			{name: "import", args: func(dst string) []string { return []string{dst} }},
+		}
+	case "windows":
+		return []capturer{{name: "powershell", args: func(dst string) []string {
+			quoted := "'" + strings.ReplaceAll(dst, "'", "''") + "'"
+			return []string{"-NoProfile", "-NonInteractive", "-Command", fmt.Sprintf(windowsCapture, quoted)}
+		}}}
+	}
+	return nil
+}
+
+func findCapturer() (capturer, string, bool) {
+	for _, c := range capturers() {
+		if path, err := exec.LookPath(c.name); err == nil {
+			return c, path, true
+		}
+	}
+	return capturer{}, "", false
+}
+
+// CanCaptureScreen reports whether CaptureScreen has a tool to use.
+func CanCaptureScreen() bool {
+	_, _, ok := findCapturer()
+	return ok
+}
+
+// CaptureScreen takes a screenshot into dst as PNG with the platform's tool:
+// screencapture on macOS, on Linux the first of gnome-screenshot, spectacle,
+// maim and ImageMagick's import, and the whole screen through PowerShell on
+// Windows. It returns when the user has made the selection, or
+// ErrScreenshotCanceled when they dismissed it.
+func CaptureScreen(dst string) error {
+	c, path, ok := findCapturer()
+	if !ok {
+		return ErrNoScreenshotTool
+	}
+	if runtime.GOOS == "windows" {
+		// Let the dialog that asked close before the screen is grabbed.
+		time.Sleep(500 * time.Millisecond)
+	}
+	_ = os.Remove(dst)
+	runErr := exec.Command(path, c.args(dst)...).Run()
+	if fi, err := os.Stat(dst); err != nil || fi.Size() == 0 {
+		_ = os.Remove(dst)
+		if runErr != nil && runtime.GOOS == "windows" {
+			return fmt.Errorf("%s: %w", c.name, runErr) // nothing to cancel there
+		}
+		// The tools exit with an error, or with 0 and no file, on Esc.
+		return ErrScreenshotCanceled
+	}
+	if runErr != nil {
+		_ = os.Remove(dst)
+		return fmt.Errorf("%s: %w", c.name, runErr)
+	}
+	return nil
+}
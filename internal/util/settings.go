@@ -0,0 +1,90 @@
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Settings are the preferences that decide where the data directory is and
+// so cannot be stored inside it, plus a little state the tray keeps between
+// runs. They live in settings.json in the default data directory; everything
+// else is in key-config.yaml.
+type Settings struct {
+	DataDir string `json:"data_dir,omitempty"` // "" means DefaultDataDir
+	// LastTaskID is the task last opened from the tray, reopened on start
+	// when KeyConfig.RestoreLastTask is set.
+	LastTaskID string `json:"last_task_id,omitempty"`
+}
+
+// DefaultDataDir returns the data directory used when neither EnvDataDir nor
+// Settings.DataDir is set, creating it if needed.
+func DefaultDataDir() (string, error) {
+	cfgBase, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(cfgBase, "systray-queue-app")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+func settingsPath() (string, error) {
+	dir, err := DefaultDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "settings.json"), nil
+}
+
+// LoadSettings reads settings.json. A missing file gives the zero Settings,
+// which keeps every default.
+func LoadSettings() (Settings, error) {
+	var s Settings
+	path, err := settingsPath()
+	if err != nil {
+		return s, err
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(b, &s); err != nil {
+		return Settings{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// SaveSettings validates s and writes it to settings.json. A data directory
+// must be an absolute path; it is created if needed and must be writable.
+// The change takes effect on the next start, and nothing is moved there.
+func SaveSettings(s Settings) error {
+	if s.DataDir != "" {
+		if !filepath.IsAbs(s.DataDir) {
+			return fmt.Errorf("data folder must be an absolute path: %s", s.DataDir)
+		}
+		s.DataDir = filepath.Clean(s.DataDir)
+		if err := os.MkdirAll(s.DataDir, 0o755); err != nil {
+			return fmt.Errorf("data folder: %w", err)
+		}
+		if err := CheckWritable(s.DataDir); err != nil {
+			return fmt.Errorf("data folder is not writable: %w", err)
+		}
+	}
+	path, err := settingsPath()
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return AtomicWriteFile(path, append(b, '\n'), 0o644)
+}
@@ -0,0 +1,75 @@
+package util
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// completeWAV is the built-in completion sound, a short two-note chime.
+//
+//go:embed sounds/complete.wav
+var completeWAV []byte
+
+// ErrNoPlayer is returned when no command-line audio player is installed.
+var ErrNoPlayer = errors.New("no audio player found (install pulseaudio-utils, alsa-utils or ffmpeg)")
+
+// soundTimeout stops a player that hangs, e.g. on a busy audio device.
+const soundTimeout = 10 * time.Second
+
+var builtinSound = sync.OnceValues(func() (string, error) {
+	p := filepath.Join(os.TempDir(), "systray-queue-complete.wav")
+	return p, AtomicWriteFile(p, completeWAV, 0o644)
+})
+
+// PlaySound plays the audio file at path, or the built-in completion sound
+// when path is empty, and returns when it has finished. It shells out to the
+// platform's player: afplay on macOS, System.Media.SoundPlayer through
+// PowerShell on Windows (WAV only), and the first of paplay, pw-play, aplay
+// and ffplay found on Linux.
+func PlaySound(path string) error {
+	if path == "" {
+		p, err := builtinSound()
+		if err != nil {
+			return err
+		}
+		path = p
+	}
+	var name string
+	var args []string
+	switch runtime.GOOS {
+	case "darwin":
+		name, args = "afplay", []string{path}
+	case "windows":
+		quoted := "'" + strings.ReplaceAll(path, "'", "''") + "'"
+		name, args = "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", "(New-Object Media.SoundPlayer " + quoted + ").PlaySync()"}
+	default:
+		for _, p := range []struct {
+			name string
+			args []string
+		}{
+			{"paplay", []string{path}},
+			{"pw-play", []string{path}},
+			{"aplay", []string{"-q", path}},
+			{"ffplay", []string{"-nodisp", "-autoexit", "-loglevel", "quiet", path}},
+		} {
+			if _, err := exec.LookPath(p.name); err == nil {
+				name, args = p.name, p.args
+				break
+			}
+		}
+		if name == "" {
+			return ErrNoPlayer
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), soundTimeout)
+	defer cancel()
+	return exec.CommandContext(ctx, name, args...).Run()
+}
@@ -0,0 +1,126 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Ameight/systray-queue-app/internal/queue"
+)
+
+// Delivery settings: each attempt gets attemptTimeout, and failed attempts
+// are retried after 1s, 2s, 4s…
+const (
+	maxAttempts    = 4
+	attemptTimeout = 10 * time.Second
+	firstBackoff   = time.Second
+)
+
+// SignatureHeader carries "sha256=<hex HMAC-SHA256 of the body>" keyed with
+// the shared secret, when one is set.
+const SignatureHeader = "X-Queue-Signature"
+
+// Payload is the JSON body posted for a completed task.
+type Payload struct {
+	Event string     `json:"event"` // always "task.completed"
+	Task  queue.Task `json:"task"`
+}
+
+// Sender posts completed tasks to a configured URL.
+type Sender struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// New returns a Sender for rawURL, or nil when rawURL is empty, which turns
+// the webhook off.
+func New(rawURL, secret string) *Sender {
+	if rawURL == "" {
+		return nil
+	}
+	return &Sender{url: rawURL, secret: secret, client: &http.Client{Timeout: attemptTimeout}}
+}
+
+// ValidURL checks a webhook URL from the settings; empty is allowed.
+func ValidURL(rawURL string) error {
+	if rawURL == "" {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("webhook URL must be an http:// or https:// address: %s", rawURL)
+	}
+	return nil
+}
+
+// Sign returns the SignatureHeader value for body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send posts t until the receiver answers 2xx, a 4xx other than 429 says a
+// retry will not help, the attempts run out or ctx is done. It blocks, so
+// callers run it in the background; a nil Sender does nothing.
+func (s *Sender) Send(ctx context.Context, t queue.Task) error {
+	if s == nil {
+		return nil
+	}
+	body, err := json.Marshal(Payload{Event: "task.completed", Task: t})
+	if err != nil {
+		return err
+	}
+	backoff := firstBackoff
+	for attempt := 1; ; attempt++ {
+		retry, err := s.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt == maxAttempts {
+			return err
+		}
+		log.Printf("[webhook] attempt %d for task %s failed, retrying: %v", attempt, t.ID, err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+// post makes one delivery attempt and reports whether a failure is worth
+// retrying.
+func (s *Sender) post(ctx context.Context, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "systray-queue-app")
+	if s.secret != "" {
+		req.Header.Set(SignatureHeader, Sign(s.secret, body))
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+	err = fmt.Errorf("webhook answered %s", resp.Status)
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests, err
+}
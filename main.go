@@ -1,7 +1,20 @@
 package main
 
-import "github.com/Ameight/systray-queue-app/internal/app"
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/Ameight/systray-queue-app/internal/app"
+	"github.com/Ameight/systray-queue-app/internal/cli"
+)
 
 func main() {
-	app.Run(faviconPNG)
+	if len(os.Args) > 1 && cli.IsCommand(os.Args[1]) {
+		os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	app.Run(ctx, faviconPNG)
 }